@@ -0,0 +1,128 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidAlternation = errors.New("messages do not alternate between user and assistant")
+
+// AlternationError reports the first message that breaks the alternation
+// ValidateAlternation checks. It matches ErrInvalidAlternation with
+// errors.Is.
+type AlternationError struct {
+	// Index is the index of the offending message.
+	Index   int
+	Role    string
+	Message string
+}
+
+func (e *AlternationError) Error() string {
+	return fmt.Sprintf("%s: message %d with role %s %s", ErrInvalidAlternation, e.Index, e.Role, e.Message)
+}
+
+func (e *AlternationError) Is(target error) bool {
+	return target == ErrInvalidAlternation
+}
+
+// ValidateAlternation checks that messages follow the pattern strict
+// providers require: any number of leading system messages, then turns that
+// alternate between the user and the assistant, starting with the user. Tool
+// and function results count as user turns, and consecutive tool results
+// form a single turn. The first offending message is returned as an
+// *AlternationError. See FixAlternation to repair a conversation.
+func ValidateAlternation(messages []ChatCompletionMessage) error {
+	start := leadingSystemMessages(messages)
+	previous := ChatMessageRoleAssistant
+	for i := start; i < len(messages); i++ {
+		msg := messages[i]
+		side := alternationSide(msg.Role)
+		switch {
+		case msg.Role == ChatMessageRoleSystem:
+			return &AlternationError{Index: i, Role: msg.Role, Message: "follows a non-system message"}
+		case side == "":
+			return &AlternationError{Index: i, Role: msg.Role, Message: "has an unknown role"}
+		case i == start && side != ChatMessageRoleUser:
+			return &AlternationError{Index: i, Role: msg.Role, Message: "must be a user message"}
+		case side == previous && !(msg.Role == ChatMessageRoleTool && messages[i-1].Role == ChatMessageRoleTool):
+			return &AlternationError{Index: i, Role: msg.Role, Message: "follows a message of the same side"}
+		}
+		previous = side
+	}
+	return nil
+}
+
+// FixAlternation returns a copy of messages that passes ValidateAlternation.
+// Consecutive plain text messages of the same role are merged, their contents
+// separated by a blank line; other messages of the same side, e.g. a user
+// message following tool results, are separated by an empty bridging message
+// of the other side. A conversation starting with the assistant gets an empty
+// user message first. System messages after the first non-system message and
+// messages with unknown roles are turned into user messages.
+func FixAlternation(messages []ChatCompletionMessage) []ChatCompletionMessage {
+	start := leadingSystemMessages(messages)
+	fixed := make([]ChatCompletionMessage, start, len(messages))
+	copy(fixed, messages[:start])
+
+	previous := ChatMessageRoleAssistant
+	for _, msg := range messages[start:] {
+		side := alternationSide(msg.Role)
+		if side == "" {
+			msg.Role, side = ChatMessageRoleUser, ChatMessageRoleUser
+		}
+
+		if side == previous {
+			var last *ChatCompletionMessage
+			if len(fixed) > start {
+				last = &fixed[len(fixed)-1]
+			}
+			switch {
+			case last != nil && mergeableMessages(*last, msg):
+				last.Content += "\n\n" + msg.Content
+				continue
+			case last != nil && last.Role == ChatMessageRoleTool && msg.Role == ChatMessageRoleTool:
+			default:
+				fixed = append(fixed, ChatCompletionMessage{Role: oppositeSide(side)})
+			}
+		}
+		fixed = append(fixed, msg)
+		previous = side
+	}
+	return fixed
+}
+
+func leadingSystemMessages(messages []ChatCompletionMessage) int {
+	n := 0
+	for n < len(messages) && messages[n].Role == ChatMessageRoleSystem {
+		n++
+	}
+	return n
+}
+
+// alternationSide returns the side of the conversation a role belongs to,
+// or "" for unknown roles.
+func alternationSide(role string) string {
+	switch role {
+	case ChatMessageRoleUser, ChatMessageRoleTool, ChatMessageRoleFunction:
+		return ChatMessageRoleUser
+	case ChatMessageRoleAssistant:
+		return ChatMessageRoleAssistant
+	default:
+		return ""
+	}
+}
+
+func oppositeSide(side string) string {
+	if side == ChatMessageRoleUser {
+		return ChatMessageRoleAssistant
+	}
+	return ChatMessageRoleUser
+}
+
+// mergeableMessages reports whether b can be appended to the content of a.
+func mergeableMessages(a, b ChatCompletionMessage) bool {
+	return a.Role == b.Role && (a.Role == ChatMessageRoleUser || a.Role == ChatMessageRoleAssistant) &&
+		a.Name == b.Name && len(a.MultiContent) == 0 && len(b.MultiContent) == 0 &&
+		len(a.ToolCalls) == 0 && len(b.ToolCalls) == 0 &&
+		a.FunctionCall == zeroFunctionCall && b.FunctionCall == zeroFunctionCall
+}
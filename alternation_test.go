@@ -0,0 +1,92 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"errors"
+	"testing"
+)
+
+func TestValidateAlternation(t *testing.T) {
+	system := ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: "Be brief."}
+	user := ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Hi"}
+	assistant := ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "Hello"}
+	call := ChatCompletionMessage{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{{ID: "a"}, {ID: "b"}}}
+	resultA := ChatCompletionMessage{Role: ChatMessageRoleTool, Content: "1", ToolCallID: "a"}
+	resultB := ChatCompletionMessage{Role: ChatMessageRoleTool, Content: "2", ToolCallID: "b"}
+
+	tests := []struct {
+		name     string
+		messages []ChatCompletionMessage
+		index    int
+	}{
+		{"valid", []ChatCompletionMessage{system, system, user, assistant, user}, -1},
+		{"valid with tool results", []ChatCompletionMessage{user, call, resultA, resultB, assistant}, -1},
+		{"empty", nil, -1},
+		{"starts with assistant", []ChatCompletionMessage{system, assistant, user}, 1},
+		{"repeated user", []ChatCompletionMessage{user, assistant, user, user}, 3},
+		{"repeated assistant", []ChatCompletionMessage{user, assistant, assistant}, 2},
+		{"user after tool result", []ChatCompletionMessage{user, call, resultA, user}, 3},
+		{"late system", []ChatCompletionMessage{user, assistant, system, user}, 2},
+		{"unknown role", []ChatCompletionMessage{user, {Role: "narrator"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAlternation(tt.messages)
+			if tt.index < 0 {
+				checks.NoError(t, err, "unexpected ValidateAlternation error")
+			} else {
+				checks.ErrorIs(t, err, ErrInvalidAlternation, "expected ErrInvalidAlternation")
+				var altErr *AlternationError
+				if !errors.As(err, &altErr) || altErr.Index != tt.index {
+					t.Errorf("expected offending index %d, got %v", tt.index, err)
+				}
+			}
+
+			fixed := FixAlternation(tt.messages)
+			checks.NoError(t, ValidateAlternation(fixed), "fixed messages should alternate")
+			if tt.index < 0 && len(fixed) != len(tt.messages) {
+				t.Errorf("valid messages should be kept, got %+v", fixed)
+			}
+		})
+	}
+}
+
+func TestFixAlternation(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "Be brief."},
+		{Role: ChatMessageRoleAssistant, Content: "Welcome!"},
+		{Role: ChatMessageRoleUser, Content: "Hi"},
+		{Role: ChatMessageRoleUser, Content: "Are you there?"},
+		{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{{ID: "a"}}},
+		{Role: ChatMessageRoleTool, Content: "1", ToolCallID: "a"},
+		{Role: ChatMessageRoleUser, Content: "Thanks"},
+	}
+	fixed := FixAlternation(messages)
+
+	expected := []struct{ role, content string }{
+		{ChatMessageRoleSystem, "Be brief."},
+		{ChatMessageRoleUser, ""},
+		{ChatMessageRoleAssistant, "Welcome!"},
+		{ChatMessageRoleUser, "Hi\n\nAre you there?"},
+		{ChatMessageRoleAssistant, ""},
+		{ChatMessageRoleTool, "1"},
+		{ChatMessageRoleAssistant, ""},
+		{ChatMessageRoleUser, "Thanks"},
+	}
+	if len(fixed) != len(expected) {
+		t.Fatalf("expected %d messages, got %+v", len(expected), fixed)
+	}
+	for i, e := range expected {
+		if fixed[i].Role != e.role || fixed[i].Content != e.content {
+			t.Errorf("message %d: expected %s %q, got %s %q", i, e.role, e.content, fixed[i].Role, fixed[i].Content)
+		}
+	}
+	if len(fixed[4].ToolCalls) != 1 {
+		t.Errorf("expected the tool call to be kept, got %+v", fixed[4])
+	}
+	if messages[3].Content != "Are you there?" {
+		t.Error("FixAlternation must not modify its argument")
+	}
+}
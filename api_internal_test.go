@@ -2,6 +2,8 @@ package openai
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -148,3 +150,38 @@ func TestAzureFullURL(t *testing.T) {
 		})
 	}
 }
+
+func TestRequestAuthHeaderTokenProvider(t *testing.T) {
+	calls := 0
+	config := DefaultConfig("static-token")
+	config.TokenProvider = func(ctx context.Context) (string, error) {
+		calls++
+		return fmt.Sprintf("dynamic-token-%d", calls), nil
+	}
+	cli := NewClientWithConfig(config)
+
+	for i := 1; i <= 2; i++ {
+		req, err := cli.newStreamRequest(context.Background(), "POST", "/chat/completions", nil, "")
+		if err != nil {
+			t.Fatalf("Failed to create request: %v", err)
+		}
+		expected := fmt.Sprintf("Bearer dynamic-token-%d", i)
+		if actual := req.Header.Get("Authorization"); actual != expected {
+			t.Errorf("Expected %s, got %s", expected, actual)
+		}
+	}
+
+	errProvider := errors.New("token expired")
+	config.TokenProvider = func(ctx context.Context) (string, error) {
+		return "", errProvider
+	}
+	cli = NewClientWithConfig(config)
+	_, err := cli.newStreamRequest(context.Background(), "POST", "/chat/completions", nil, "")
+	if !errors.Is(err, errProvider) {
+		t.Errorf("Expected token provider error, got %v", err)
+	}
+	_, err = cli.ListModels(context.Background())
+	if !errors.Is(err, errProvider) {
+		t.Errorf("Expected token provider error from sendRequest, got %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package openai
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrUnauthorized is matched by errors returned when the API rejects the
+	// credentials of a request with 401 Unauthorized, e.g. because the API
+	// key is invalid or expired and should be refreshed.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden is matched by errors returned when the API refuses a
+	// request with 403 Forbidden, e.g. because the key lacks a permission or
+	// the region is not supported.
+	ErrForbidden = errors.New("forbidden")
+)
+
+// AuthError is returned when the API responds with 401 Unauthorized or 403
+// Forbidden. It matches ErrUnauthorized or ErrForbidden with errors.Is,
+// respectively, and unwraps to the underlying *APIError, whose message is
+// included in the error string. If the body is not an API error, e.g. an
+// HTML page of a proxy, the message is the body, truncated, or the status
+// text if the body is empty.
+type AuthError struct {
+	Err *APIError
+}
+
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *AuthError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AuthError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.Err.HTTPStatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.Err.HTTPStatusCode == http.StatusForbidden
+	default:
+		return false
+	}
+}
+
+// newAuthError returns an *AuthError if apiErr was returned with 401 or 403,
+// or nil otherwise.
+func newAuthError(apiErr *APIError) *AuthError {
+	if apiErr.HTTPStatusCode != http.StatusUnauthorized && apiErr.HTTPStatusCode != http.StatusForbidden {
+		return nil
+	}
+	return &AuthError{Err: apiErr}
+}
+
+const (
+	// maxErrorBodySize is the number of bytes read from error responses.
+	maxErrorBodySize = 1 << 20
+	// maxErrorBodyMessage is the number of bytes of a body that is not an
+	// API error kept as the message of the error.
+	maxErrorBodyMessage = 512
+)
+
+// errorBodyMessage returns the trimmed body of an error response that is not
+// an API error, truncated to maxErrorBodyMessage bytes, or the status text if
+// the body is empty.
+func errorBodyMessage(body []byte, statusCode int) string {
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		return http.StatusText(statusCode)
+	}
+	if len(message) > maxErrorBodyMessage {
+		message = strings.ToValidUTF8(message[:maxErrorBodyMessage], "") + "..."
+	}
+	return message
+}
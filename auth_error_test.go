@@ -0,0 +1,121 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestAuthError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	status := http.StatusUnauthorized
+	message := "Incorrect API key provided."
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"error":{"message":"` + message + `","type":"invalid_request_error"}}`))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrUnauthorized, "expected ErrUnauthorized")
+	if errors.Is(err, ErrForbidden) {
+		t.Error("a 401 must not match ErrForbidden")
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusUnauthorized {
+		t.Errorf("expected the error to wrap the APIError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), message) {
+		t.Errorf("expected the error to contain the message, got %q", err.Error())
+	}
+
+	status = http.StatusForbidden
+	message = "Country, region, or territory not supported"
+	_, err = client.CreateChatCompletionStream(context.Background(), req)
+	checks.ErrorIs(t, err, ErrForbidden, "expected ErrForbidden from the stream")
+	if errors.Is(err, ErrUnauthorized) {
+		t.Error("a 403 must not match ErrUnauthorized")
+	}
+	if !strings.Contains(err.Error(), message) {
+		t.Errorf("expected the error to contain the message, got %q", err.Error())
+	}
+
+	status = http.StatusBadRequest
+	_, err = client.CreateChatCompletion(context.Background(), req)
+	if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) {
+		t.Errorf("a 400 must not match the auth errors, got %v", err)
+	}
+}
+
+func TestAuthErrorWithoutAPIErrorBody(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte("<html><body><h1>401 Authorization Required</h1></body></html>"))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrUnauthorized, "expected ErrUnauthorized for an HTML body")
+	var authErr *AuthError
+	if !errors.As(err, &authErr) || authErr.Err.HTTPStatusCode != http.StatusUnauthorized {
+		t.Errorf("expected an AuthError, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "401 Authorization Required") {
+		t.Errorf("expected the error to contain the body, got %q", err.Error())
+	}
+
+	_, err = client.CreateChatCompletionStream(context.Background(), req)
+	checks.ErrorIs(t, err, ErrUnauthorized, "expected ErrUnauthorized from the stream for an HTML body")
+}
+
+func TestAuthErrorWithoutErrorField(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	body := `{"message":"key revoked"}`
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(body))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrUnauthorized, "expected ErrUnauthorized for a body without an error field")
+	if !strings.Contains(err.Error(), "key revoked") {
+		t.Errorf("expected the error to contain the gateway's reason, got %q", err.Error())
+	}
+
+	body = ""
+	_, err = client.CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrUnauthorized, "expected ErrUnauthorized for an empty body")
+	if !strings.Contains(err.Error(), http.StatusText(http.StatusUnauthorized)) {
+		t.Errorf("expected the error to contain the status text, got %q", err.Error())
+	}
+
+	body = strings.Repeat("x", 10000)
+	_, err = client.CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrUnauthorized, "expected ErrUnauthorized for a long body")
+	if len(err.Error()) > 1000 {
+		t.Errorf("expected a long body to be truncated, got %d bytes", len(err.Error()))
+	}
+}
@@ -0,0 +1,76 @@
+package openai
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CacheKeyOption configures ChatCompletionRequest.CacheKey.
+type CacheKeyOption func(*cacheKeyOptions)
+
+type cacheKeyOptions struct {
+	excluded []string
+}
+
+// defaultCacheKeyExcluded holds the JSON names of the request fields that do
+// not affect the response.
+var defaultCacheKeyExcluded = []string{"user"}
+
+// WithCacheKeyExcludedFields sets the JSON names of the top-level request
+// fields, e.g. "user" or "logit_bias", that are left out of the cache key.
+// It replaces the default, which excludes "user" only.
+func WithCacheKeyExcludedFields(fields ...string) CacheKeyOption {
+	return func(o *cacheKeyOptions) {
+		o.excluded = fields
+	}
+}
+
+// CacheKey returns a deterministic key for the request, the hex-encoded
+// SHA-256 digest of its canonical JSON encoding, e.g. to memoize responses to
+// identical prompts. Object keys are sorted at every level, so requests that
+// differ only in the order of map entries or of raw JSON schema properties
+// have the same key. Volatile fields are excluded, see
+// WithCacheKeyExcludedFields.
+func (r ChatCompletionRequest) CacheKey(opts ...CacheKeyOption) string {
+	options := &cacheKeyOptions{excluded: defaultCacheKeyExcluded}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	canonical, err := canonicalJSON(r, options.excluded)
+	if err != nil {
+		// Encoding fails only for unencodable values a caller put into the
+		// request, which the API would reject anyway; fall back to a key that
+		// still distinguishes such requests by their error.
+		canonical = []byte(err.Error())
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalJSON encodes v with sorted object keys and without the top-level
+// fields in excluded.
+func canonicalJSON(v any, excluded []string) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Numbers are kept as written so that large integers and float
+	// precision survive the round trip.
+	decoder.UseNumber()
+	var value any
+	if err = decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if object, ok := value.(map[string]any); ok {
+		for _, field := range excluded {
+			delete(object, field)
+		}
+	}
+	// encoding/json writes map keys in sorted order.
+	return json.Marshal(value)
+}
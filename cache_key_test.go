@@ -0,0 +1,50 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"testing"
+)
+
+func TestChatCompletionRequestCacheKey(t *testing.T) {
+	newRequest := func(keys ...string) ChatCompletionRequest {
+		bias := make(map[string]int)
+		properties := make(map[string]JSONSchema)
+		for _, key := range keys {
+			bias[key] = len(key)
+			properties[key] = JSONSchema{Type: JSONSchemaTypeString}
+		}
+		return ChatCompletionRequest{
+			Model:     GPT4o,
+			Messages:  []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+			LogitBias: bias,
+			Tools: []Tool{{Type: ToolTypeFunction, Function: &Functions{
+				Name:       "get_weather",
+				Parameters: FuncParameters{Type: JSONSchemaTypeObject, Properties: properties},
+			}}},
+		}
+	}
+	a := newRequest("1", "22", "333", "4444")
+	b := newRequest("4444", "333", "1", "22")
+
+	key := a.CacheKey()
+	if len(key) != 64 {
+		t.Fatalf("expected a hex SHA-256 digest, got %q", key)
+	}
+	if b.CacheKey() != key {
+		t.Error("requests differing in key order have different cache keys")
+	}
+
+	b.User = "user-1234"
+	if b.CacheKey() != key {
+		t.Error("user should not affect the cache key by default")
+	}
+	if b.CacheKey(WithCacheKeyExcludedFields()) == key {
+		t.Error("user should affect the cache key when it is not excluded")
+	}
+
+	b.Messages[0].Content = "Hi!"
+	if b.CacheKey() == key {
+		t.Error("requests with different messages have the same cache key")
+	}
+}
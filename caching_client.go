@@ -0,0 +1,180 @@
+package openai
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// Cache stores chat completion responses by the CacheKey of their request.
+type Cache interface {
+	Get(ctx context.Context, key string) (ChatCompletionResponse, bool)
+	Set(ctx context.Context, key string, response ChatCompletionResponse)
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// response once it holds the maximum number of responses, and responses
+// older than its TTL.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[string]*list.Element
+	// lru holds the entries from the most to the least recently used.
+	lru *list.List
+	now func() time.Time
+}
+
+type memoryCacheEntry struct {
+	key       string
+	response  ChatCompletionResponse
+	expiresAt time.Time
+}
+
+// NewMemoryCache creates a MemoryCache holding at most maxEntries responses
+// for ttl each. A zero maxEntries or ttl disables the corresponding limit.
+func NewMemoryCache(maxEntries int, ttl time.Duration) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get returns the response stored for key, unless it expired.
+func (c *MemoryCache) Get(_ context.Context, key string) (ChatCompletionResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return ChatCompletionResponse{}, false
+	}
+	entry, _ := element.Value.(*memoryCacheEntry)
+	if c.ttl > 0 && !c.now().Before(entry.expiresAt) {
+		c.remove(element)
+		return ChatCompletionResponse{}, false
+	}
+	c.lru.MoveToFront(element)
+	return withCopiedChoices(entry.response), true
+}
+
+// Set stores response for key, evicting the least recently used response if
+// the cache is full.
+func (c *MemoryCache) Set(_ context.Context, key string, response ChatCompletionResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &memoryCacheEntry{key: key, response: withCopiedChoices(response), expiresAt: c.now().Add(c.ttl)}
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(entry)
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.remove(c.lru.Back())
+	}
+}
+
+// Len returns the number of stored responses, including expired ones that
+// were not evicted yet.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+func (c *MemoryCache) remove(element *list.Element) {
+	entry, _ := c.lru.Remove(element).(*memoryCacheEntry)
+	delete(c.entries, entry.key)
+}
+
+// withCopiedChoices returns response with a copy of its choices, so that
+// callers modifying the choices of their response don't modify shared ones.
+func withCopiedChoices(response ChatCompletionResponse) ChatCompletionResponse {
+	if response.Choices != nil {
+		response.Choices = append([]ChatCompletionChoice(nil), response.Choices...)
+	}
+	return response
+}
+
+// CachingClient is a Client whose CreateChatCompletion serves responses to
+// repeated requests from a Cache. All other methods, including streaming,
+// are those of the wrapped Client.
+type CachingClient struct {
+	*Client
+	cache   Cache
+	options cachingOptions
+}
+
+// CachingOption configures a CachingClient.
+type CachingOption func(*cachingOptions)
+
+type cachingOptions struct {
+	always     bool
+	keyOptions []CacheKeyOption
+}
+
+// WithCacheNondeterministic caches responses regardless of the request's
+// Temperature.
+func WithCacheNondeterministic() CachingOption {
+	return func(o *cachingOptions) {
+		o.always = true
+	}
+}
+
+// WithCachingKeyOptions passes opts to ChatCompletionRequest.CacheKey when
+// computing the keys of requests.
+func WithCachingKeyOptions(opts ...CacheKeyOption) CachingOption {
+	return func(o *cachingOptions) {
+		o.keyOptions = append(o.keyOptions, opts...)
+	}
+}
+
+// NewCachingClient wraps client to cache chat completion responses in cache.
+func NewCachingClient(client *Client, cache Cache, opts ...CachingOption) *CachingClient {
+	c := &CachingClient{Client: client, cache: cache}
+	for _, opt := range opts {
+		opt(&c.options)
+	}
+	return c
+}
+
+// CreateChatCompletion returns the cached response to an identical request
+// if there is one, and otherwise sends the request and caches a successful
+// response. Requests count as identical when they match once the client's
+// defaults are applied and their options set the same headers and omit the
+// same fields. Only requests with a Temperature of zero are cached, since the
+// responses to others vary by design and the API samples with a temperature
+// of 1 when none is set; WithCacheNondeterministic lifts this restriction.
+func (c *CachingClient) CreateChatCompletion(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...ChatCompletionOption,
+) (ChatCompletionResponse, error) {
+	options := newChatCompletionOptions(opts)
+	prepared := c.prepareChatCompletionRequest(request, options)
+	if prepared.Stream || options.err != nil || !c.cacheable(prepared) {
+		return c.Client.CreateChatCompletion(ctx, request, opts...)
+	}
+
+	key := prepared.CacheKey(c.options.keyOptions...) + optionsKey(options)
+	if response, ok := c.cache.Get(ctx, key); ok {
+		return response, nil
+	}
+	response, err := c.Client.CreateChatCompletion(ctx, request, opts...)
+	if err != nil {
+		return response, err
+	}
+	c.cache.Set(ctx, key, response)
+	return response, nil
+}
+
+func (c *CachingClient) cacheable(request ChatCompletionRequest) bool {
+	return c.options.always || (request.Temperature != nil && *request.Temperature == 0)
+}
@@ -0,0 +1,134 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestCachingClient(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var requests int
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		resBytes, _ := json.Marshal(ChatCompletionResponse{
+			ID:      fmt.Sprintf("chatcmpl-%d", requests),
+			Choices: []ChatCompletionChoice{{Message: ChatCompletionMessage{Content: "Hi!"}}},
+		})
+		_, _ = w.Write(resBytes)
+	})
+
+	cache := NewMemoryCache(10, time.Hour)
+	caching := NewCachingClient(client, cache)
+	request := ChatCompletionRequest{
+		Model:       GPT4o,
+		Messages:    []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		Temperature: Float32(0),
+	}
+
+	first, err := caching.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	second, err := caching.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if requests != 1 || second.ID != first.ID {
+		t.Errorf("expected the second response from the cache, got %d requests", requests)
+	}
+
+	request.Temperature = Float32(0.7)
+	for i := 0; i < 2; i++ {
+		_, err = caching.CreateChatCompletion(context.Background(), request)
+		checks.NoError(t, err, "CreateChatCompletion error")
+	}
+	if requests != 3 {
+		t.Errorf("requests with a temperature should not be cached, got %d requests", requests)
+	}
+
+	caching = NewCachingClient(client, cache, WithCacheNondeterministic())
+	for i := 0; i < 2; i++ {
+		_, err = caching.CreateChatCompletion(context.Background(), request)
+		checks.NoError(t, err, "CreateChatCompletion error")
+	}
+	if requests != 4 {
+		t.Errorf("forced caching should cache requests with a temperature, got %d requests", requests)
+	}
+}
+
+func TestCachingClientKeyOptions(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var requests int
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		resBytes, _ := json.Marshal(ChatCompletionResponse{
+			Choices: []ChatCompletionChoice{{Message: ChatCompletionMessage{Content: "Hi!"}}},
+		})
+		_, _ = w.Write(resBytes)
+	})
+
+	caching := NewCachingClient(client, NewMemoryCache(10, time.Hour))
+	request := ChatCompletionRequest{
+		Model:       GPT4o,
+		Messages:    []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		Temperature: Float32(0),
+	}
+	for i, opts := range [][]ChatCompletionOption{
+		nil,
+		{WithApproxWords(50)},
+		{WithApproxChars(50)},
+		{OmitFields("temperature")},
+		{WithOrganization("org-1")},
+		{WithProject("proj-1")},
+		{WithRequestID("req-1")},
+	} {
+		_, err := caching.CreateChatCompletion(context.Background(), request, opts...)
+		checks.NoError(t, err, "CreateChatCompletion error")
+		if requests != i+1 {
+			t.Fatalf("options %d were served from the cache of another call", i)
+		}
+	}
+
+	response, err := caching.CreateChatCompletion(context.Background(), request, WithOrganization("org-1"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if requests != 7 {
+		t.Errorf("expected identical options to be served from the cache, got %d requests", requests)
+	}
+	response.Choices[0].Message.Content = "modified"
+	response, err = caching.CreateChatCompletion(context.Background(), request, WithOrganization("org-1"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.Choices[0].Message.Content != "Hi!" {
+		t.Errorf("modifying a response changed the cached one: %q", response.Choices[0].Message.Content)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewMemoryCache(2, 0)
+	cache.Set(ctx, "a", ChatCompletionResponse{ID: "a"})
+	cache.Set(ctx, "b", ChatCompletionResponse{ID: "b"})
+	if _, ok := cache.Get(ctx, "a"); !ok {
+		t.Fatal("a should be cached")
+	}
+	cache.Set(ctx, "c", ChatCompletionResponse{ID: "c"})
+	if _, ok := cache.Get(ctx, "b"); ok {
+		t.Error("b should have been evicted as the least recently used")
+	}
+	if cache.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", cache.Len())
+	}
+
+	cache = NewMemoryCache(0, 10*time.Millisecond)
+	cache.Set(ctx, "a", ChatCompletionResponse{ID: "a"})
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := cache.Get(ctx, "a"); ok {
+		t.Error("a should have expired")
+	}
+}
@@ -1,10 +1,13 @@
 package openai
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 )
 
 // Chat message role defined by the OpenAI API.
@@ -13,12 +16,16 @@ const (
 	ChatMessageRoleUser      = "user"
 	ChatMessageRoleAssistant = "assistant"
 	ChatMessageRoleFunction  = "function"
+	ChatMessageRoleTool      = "tool"
 )
 
 var (
 	ErrChatCompletionInvalidModel       = errors.New("this model is not supported with this method, please use CreateCompletion client method instead") //nolint:lll
 	ErrChatCompletionStreamNotSupported = errors.New("streaming is not supported with this method, please use CreateChatCompletionStream")              //nolint:lll
 	ErrModelNotSupportedWithPlugins     = errors.New("this model is not supported with plugins")                                                        //nolint:lll
+	ErrInvalidChatCompletionRequest     = errors.New("invalid chat completion request")                                                                 //nolint:lll
+	ErrContentFieldsMisused             = errors.New("can't use both Content and MultiContent properties simultaneously")                               //nolint:lll
+	ErrNoChoices                        = errors.New("the response has no choices")
 )
 
 type Arguments string
@@ -30,6 +37,20 @@ func (a Arguments) String() string {
 	return string(a)
 }
 
+// Valid reports whether the arguments are valid JSON.
+func (a Arguments) Valid() bool {
+	return json.Valid([]byte(a))
+}
+
+// Pretty returns the arguments re-indented for logging.
+func (a Arguments) Pretty() (string, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(a), "", "  "); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
 type FunctionCall struct {
 	Name      string    `json:"name,omitempty"`
 	Arguments Arguments `json:"arguments,omitempty"`
@@ -37,10 +58,123 @@ type FunctionCall struct {
 
 var zeroFunctionCall = FunctionCall{}
 
+type ToolType string
+
+const (
+	ToolTypeFunction ToolType = "function"
+)
+
+// ToolCall is a single tool invocation requested by the model.
+type ToolCall struct {
+	// Index is the index the tool call was streamed with. It is only set on
+	// tool calls merged from stream deltas, see ChatCompletionResponse.ApplyDelta.
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id"`
+	Type     ToolType     `json:"type"`
+	Function FunctionCall `json:"function"`
+}
+
+type ChatMessagePartType string
+
+const (
+	ChatMessagePartTypeText     ChatMessagePartType = "text"
+	ChatMessagePartTypeImageURL ChatMessagePartType = "image_url"
+)
+
+type ImageURLDetail string
+
+const (
+	ImageURLDetailHigh ImageURLDetail = "high"
+	ImageURLDetailLow  ImageURLDetail = "low"
+	ImageURLDetailAuto ImageURLDetail = "auto"
+)
+
+func (d ImageURLDetail) valid() bool {
+	switch d {
+	case "", ImageURLDetailHigh, ImageURLDetailLow, ImageURLDetailAuto:
+		return true
+	}
+	return false
+}
+
+type ChatMessageImageURL struct {
+	URL    string         `json:"url,omitempty"`
+	Detail ImageURLDetail `json:"detail,omitempty"`
+}
+
+// ChatMessagePart is a single part of a multimodal message.
+type ChatMessagePart struct {
+	Type     ChatMessagePartType  `json:"type,omitempty"`
+	Text     string               `json:"text,omitempty"`
+	ImageURL *ChatMessageImageURL `json:"image_url,omitempty"`
+
+	// CacheControl is an extension of Anthropic-compatible providers, see
+	// ChatCompletionMessage.CacheControl.
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+}
+
+type CacheControlType string
+
+const (
+	CacheControlTypeEphemeral CacheControlType = "ephemeral"
+)
+
+// CacheControl marks a message or content part as a cache breakpoint for
+// providers that support prompt caching, such as Anthropic-compatible
+// proxies. It is not part of the OpenAI API.
+type CacheControl struct {
+	Type CacheControlType `json:"type"`
+}
+
+type AnnotationType string
+
+const (
+	AnnotationTypeURLCitation AnnotationType = "url_citation"
+)
+
+// Annotation annotates a range of a message's content.
+type Annotation struct {
+	Type AnnotationType `json:"type"`
+	// URLCitation is set for annotations of type url_citation.
+	URLCitation *URLCitation `json:"url_citation,omitempty"`
+}
+
+// URLCitation cites a web page for the content between StartIndex and
+// EndIndex.
+type URLCitation struct {
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+}
+
 type ChatCompletionMessage struct {
-	Role         string       `json:"role"`
-	Content      string       `json:"content"`
+	Role    string `json:"role"`
+	Content string `json:"content"`
+	// MultiContent holds the parts of a multimodal message. It is sent as
+	// the content field and is mutually exclusive with Content.
+	MultiContent []ChatMessagePart `json:"-"`
+
+	Refusal      string       `json:"refusal,omitempty"`
 	FunctionCall FunctionCall `json:"function_call,omitempty"`
+	ToolCalls    []ToolCall   `json:"tool_calls,omitempty"`
+
+	// Annotations holds the citations of the sources that search-enabled
+	// models based the content on.
+	Annotations []Annotation `json:"annotations,omitempty"`
+
+	// ReasoningContent is the chain of thought returned separately from
+	// Content by some OpenAI-compatible reasoning models.
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+
+	// CacheControl is an extension of Anthropic-compatible providers that
+	// caches the prompt up to and including this message, e.g. a large
+	// system prompt. It is only sent when set.
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+
+	// ToolCallID is required for messages with role tool and references
+	// the ToolCall.ID the message is a result for.
+	ToolCallID string `json:"tool_call_id,omitempty"`
 
 	// This property isn't in the official documentation, but it's in
 	// the documentation for the official library for python:
@@ -50,32 +184,80 @@ type ChatCompletionMessage struct {
 }
 
 func (c ChatCompletionMessage) MarshalJSON() ([]byte, error) {
+	if c.Content != "" && c.MultiContent != nil {
+		return nil, ErrContentFieldsMisused
+	}
+
 	// We need to use a custom marshaler because the FunctionCall field
-	// is a pointer, and we want to omit it if it's nil.
+	// is a pointer, and we want to omit it if it's nil, and because Content
+	// is sent as an array of parts when MultiContent is set.
 	type Alias ChatCompletionMessage
-	if c.FunctionCall == zeroFunctionCall {
-		return json.Marshal(&struct {
-			FunctionCall *FunctionCall `json:"function_call,omitempty"`
-			Alias
-		}{
-			FunctionCall: nil,
-			Alias:        (Alias)(c),
-		})
+	var content any = c.Content
+	if c.MultiContent != nil {
+		content = c.MultiContent
+	}
+	var functionCall *FunctionCall
+	if c.FunctionCall != zeroFunctionCall {
+		functionCall = &c.FunctionCall
 	}
 	return json.Marshal(&struct {
-		*Alias
+		Alias
+		Content      any           `json:"content"`
 		FunctionCall *FunctionCall `json:"function_call,omitempty"`
 	}{
-		Alias:        (*Alias)(&c),
-		FunctionCall: &c.FunctionCall,
+		Alias:        (Alias)(c),
+		Content:      content,
+		FunctionCall: functionCall,
 	})
 }
 
+func (c *ChatCompletionMessage) UnmarshalJSON(data []byte) error {
+	type Alias ChatCompletionMessage
+	aux := struct {
+		*Alias
+		Content json.RawMessage `json:"content"`
+	}{
+		Alias: (*Alias)(c),
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	c.Content = ""
+	c.MultiContent = nil
+	content := bytes.TrimSpace(aux.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '[':
+		return json.Unmarshal(content, &c.MultiContent)
+	default:
+		return json.Unmarshal(content, &c.Content)
+	}
+}
+
+// TextContent returns the text of the message: Content if it is set, and
+// otherwise the text parts of MultiContent joined by newlines, ignoring
+// images and other parts.
+func (c ChatCompletionMessage) TextContent() string {
+	if c.Content != "" || c.MultiContent == nil {
+		return c.Content
+	}
+	texts := make([]string, 0, len(c.MultiContent))
+	for _, part := range c.MultiContent {
+		if part.Type == ChatMessagePartTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
 type JSONSchemaType string
 
 const (
 	JSONSchemaTypeObject  JSONSchemaType = "object"
 	JSONSchemaTypeNumber  JSONSchemaType = "number"
+	JSONSchemaTypeInteger JSONSchemaType = "integer"
 	JSONSchemaTypeString  JSONSchemaType = "string"
 	JSONSchemaTypeArray   JSONSchemaType = "array"
 	JSONSchemaTypeNull    JSONSchemaType = "null"
@@ -88,6 +270,7 @@ type JSONSchema struct {
 	Enum        []string               `json:"enum,omitempty"`
 	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
 	Required    []string               `json:"required,omitempty"`
+	Items       *JSONSchema            `json:"items,omitempty"`
 }
 type FuncParameters struct {
 	Type       JSONSchemaType        `json:"type"`
@@ -100,21 +283,284 @@ type Functions struct {
 	Parameters  FuncParameters `json:"parameters"`
 }
 
+// Tool is a tool the model may call. Functions are currently the only type
+// of tool.
+type Tool struct {
+	Type     ToolType   `json:"type"`
+	Function *Functions `json:"function,omitempty"`
+}
+
+// ToolChoiceMode is a tool choice that does not name a tool.
+type ToolChoiceMode string
+
+const (
+	toolChoiceModeNone     ToolChoiceMode = "none"
+	toolChoiceModeAuto     ToolChoiceMode = "auto"
+	toolChoiceModeRequired ToolChoiceMode = "required"
+)
+
+// ToolChoice is a tool choice that forces the model to call a specific tool.
+type ToolChoice struct {
+	Type     ToolType     `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction names the function of a ToolChoice.
+type ToolFunction struct {
+	Name string `json:"name"`
+}
+
+// ToolChoiceAuto lets the model decide whether to call tools, the API
+// default when tools are present.
+func ToolChoiceAuto() ToolChoiceMode {
+	return toolChoiceModeAuto
+}
+
+// ToolChoiceNone prevents the model from calling tools.
+func ToolChoiceNone() ToolChoiceMode {
+	return toolChoiceModeNone
+}
+
+// ToolChoiceRequired makes the model call at least one tool.
+func ToolChoiceRequired() ToolChoiceMode {
+	return toolChoiceModeRequired
+}
+
+// ToolChoiceFunction makes the model call the function tool name, which
+// must be one of the request's Tools.
+func ToolChoiceFunction(name string) ToolChoice {
+	return ToolChoice{Type: ToolTypeFunction, Function: ToolFunction{Name: name}}
+}
+
 // ChatCompletionRequest represents a request structure for chat completion API.
 type ChatCompletionRequest struct {
-	Model            string                  `json:"model"`
-	Messages         []ChatCompletionMessage `json:"messages"`
-	MaxTokens        int                     `json:"max_tokens,omitempty"`
-	Temperature      float32                 `json:"temperature,omitempty"`
-	TopP             float32                 `json:"top_p,omitempty"`
-	N                int                     `json:"n,omitempty"`
-	Stream           bool                    `json:"stream,omitempty"`
-	Stop             []string                `json:"stop,omitempty"`
-	PresencePenalty  float32                 `json:"presence_penalty,omitempty"`
-	FrequencyPenalty float32                 `json:"frequency_penalty,omitempty"`
-	LogitBias        map[string]int          `json:"logit_bias,omitempty"`
-	User             string                  `json:"user,omitempty"`
-	Functions        []Functions             `json:"functions,omitempty"`
+	Model     string                  `json:"model"`
+	Messages  []ChatCompletionMessage `json:"messages"`
+	MaxTokens int                     `json:"max_tokens,omitempty"`
+
+	// MaxCompletionTokens bounds the generated tokens, including reasoning
+	// tokens. Reasoning models such as o1 require it instead of MaxTokens,
+	// see ClientConfig.TranslateMaxTokens.
+	MaxCompletionTokens int `json:"max_completion_tokens,omitempty"`
+
+	// ThinkingBudget is an extension of OpenAI-compatible providers of
+	// reasoning models and is not part of the OpenAI API. It hints how many
+	// tokens the model may spend on reasoning, separately from the output
+	// tokens, and is only sent when set; set it with Int. Compare it with
+	// Usage.ReasoningTokens to tune the budget.
+	ThinkingBudget *int `json:"thinking_budget,omitempty"`
+
+	// Temperature and TopP are pointers so that an explicit zero, which
+	// requests deterministic sampling, is sent to the API; a nil value omits
+	// the field and uses the API default of 1. Use Float32 to set them, or
+	// OmitZeroFloat32 to keep the behavior of the former float32 fields.
+	Temperature *float32 `json:"temperature,omitempty"`
+	TopP        *float32 `json:"top_p,omitempty"`
+
+	N      int      `json:"n,omitempty"`
+	Stream bool     `json:"stream,omitempty"`
+	Stop   []string `json:"stop,omitempty"`
+
+	// StreamOptions is only used by CreateChatCompletionStream.
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+
+	// PresencePenalty and FrequencyPenalty are pointers so that an explicit
+	// zero is sent to the API; a nil value omits the field and uses the API
+	// default. Both must be between -2.0 and 2.0.
+	PresencePenalty  *float32 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float32 `json:"frequency_penalty,omitempty"`
+
+	LogitBias map[string]int `json:"logit_bias,omitempty"`
+	User      string         `json:"user,omitempty"`
+	Functions []Functions    `json:"functions,omitempty"`
+	Tools     []Tool         `json:"tools,omitempty"`
+
+	// ToolChoice controls which tool the model calls, if any. Set it with
+	// ToolChoiceAuto, ToolChoiceNone, ToolChoiceRequired or
+	// ToolChoiceFunction.
+	ToolChoice any `json:"tool_choice,omitempty"`
+
+	// LogProbs requests the log probabilities of the output tokens, and
+	// TopLogProbs the number of most likely tokens to return per position.
+	LogProbs    bool `json:"logprobs,omitempty"`
+	TopLogProbs int  `json:"top_logprobs,omitempty"`
+
+	// SafePrompt and RandomSeed are extensions of Mistral-compatible
+	// providers and are not part of the OpenAI API. SafePrompt prepends the
+	// provider's safety prompt, RandomSeed seeds sampling. Both are only sent
+	// when set.
+	SafePrompt *bool `json:"safe_prompt,omitempty"`
+	RandomSeed *int  `json:"random_seed,omitempty"`
+
+	// WebSearchOptions enables the built-in web search of search-enabled
+	// models.
+	WebSearchOptions *WebSearchOptions `json:"web_search_options,omitempty"`
+
+	// Store keeps the completion for later retrieval with GetChatCompletion
+	// and ListChatCompletions, which Metadata can filter by.
+	Store    bool              `json:"store,omitempty"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// ResponseFormat requests JSON output, optionally matching a JSON
+	// schema, see CreateChatCompletionTyped.
+	ResponseFormat *ChatCompletionResponseFormat `json:"response_format,omitempty"`
+}
+
+type ChatCompletionResponseFormatType string
+
+const (
+	ChatCompletionResponseFormatTypeText       ChatCompletionResponseFormatType = "text"
+	ChatCompletionResponseFormatTypeJSONObject ChatCompletionResponseFormatType = "json_object"
+	ChatCompletionResponseFormatTypeJSONSchema ChatCompletionResponseFormatType = "json_schema"
+)
+
+// ChatCompletionResponseFormat is the format of the response content.
+// JSONSchema is only used with ChatCompletionResponseFormatTypeJSONSchema.
+type ChatCompletionResponseFormat struct {
+	Type       ChatCompletionResponseFormatType        `json:"type"`
+	JSONSchema *ChatCompletionResponseFormatJSONSchema `json:"json_schema,omitempty"`
+}
+
+// ChatCompletionResponseFormatJSONSchema is the JSON schema the response
+// content should match. Name may only contain letters, digits, underscores
+// and dashes.
+type ChatCompletionResponseFormatJSONSchema struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Schema      json.RawMessage `json:"schema"`
+	Strict      bool            `json:"strict,omitempty"`
+}
+
+// StreamOptions configures a streamed chat completion.
+type StreamOptions struct {
+	// IncludeUsage requests an additional frame before [DONE] with the token
+	// usage of the whole request and no choices.
+	IncludeUsage bool `json:"include_usage,omitempty"`
+}
+
+// Validate checks the request for mistakes the API would reject. It is called
+// by CreateChatCompletion and CreateChatCompletionStream before sending.
+func (r ChatCompletionRequest) Validate() error {
+	if len(r.Stop) > maxStopSequences {
+		return fmt.Errorf("%w: at most %d stop sequences are allowed, got %d",
+			ErrInvalidChatCompletionRequest, maxStopSequences, len(r.Stop))
+	}
+	for i, stop := range r.Stop {
+		if stop == "" {
+			return fmt.Errorf("%w: stop sequence %d is empty", ErrInvalidChatCompletionRequest, i)
+		}
+	}
+	for i, msg := range r.Messages {
+		for _, part := range msg.MultiContent {
+			if part.ImageURL != nil && !part.ImageURL.Detail.valid() {
+				return fmt.Errorf("%w: message %d has unknown image detail %q",
+					ErrInvalidChatCompletionRequest, i, part.ImageURL.Detail)
+			}
+		}
+	}
+	if err := validateLogitBias(r.LogitBias); err != nil {
+		return err
+	}
+	if err := r.validateToolChoice(); err != nil {
+		return err
+	}
+	if r.WebSearchOptions != nil && !r.WebSearchOptions.SearchContextSize.valid() {
+		return fmt.Errorf("%w: unknown web search context size %q",
+			ErrInvalidChatCompletionRequest, r.WebSearchOptions.SearchContextSize)
+	}
+	if capabilities, ok := GetModelCapabilities(r.Model); ok && capabilities.MaxN > 0 && r.N > capabilities.MaxN {
+		return fmt.Errorf("%w: %s allows at most %d choices per request, got n=%d",
+			ErrInvalidChatCompletionRequest, r.Model, capabilities.MaxN, r.N)
+	}
+	if r.ThinkingBudget != nil && *r.ThinkingBudget < 0 {
+		return fmt.Errorf("%w: thinking budget must not be negative, got %d",
+			ErrInvalidChatCompletionRequest, *r.ThinkingBudget)
+	}
+	if err := validatePenalty("presence_penalty", r.PresencePenalty); err != nil {
+		return err
+	}
+	return validatePenalty("frequency_penalty", r.FrequencyPenalty)
+}
+
+// Float32 returns a pointer to v, for setting the optional float fields of
+// ChatCompletionRequest.
+func Float32(v float32) *float32 {
+	return &v
+}
+
+// Bool returns a pointer to v, for setting the optional bool fields of
+// ChatCompletionRequest.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Int returns a pointer to v, for setting the optional int fields of
+// ChatCompletionRequest.
+func Int(v int) *int {
+	return &v
+}
+
+// OmitZeroFloat32 returns a pointer to v, or nil if v is zero. It eases the
+// migration from the former float32 request fields, where a zero value was
+// omitted from the request.
+func OmitZeroFloat32(v float32) *float32 {
+	if v == 0 {
+		return nil
+	}
+	return &v
+}
+
+const (
+	minPenalty = -2.0
+	maxPenalty = 2.0
+)
+
+func validatePenalty(name string, penalty *float32) error {
+	if penalty == nil || (*penalty >= minPenalty && *penalty <= maxPenalty) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s must be between %v and %v, got %v",
+		ErrInvalidChatCompletionRequest, name, minPenalty, maxPenalty, *penalty)
+}
+
+func (r ChatCompletionRequest) validateToolChoice() error {
+	var name string
+	switch choice := r.ToolChoice.(type) {
+	case nil:
+		return nil
+	case ToolChoiceMode:
+		if choice == toolChoiceModeNone {
+			return nil
+		}
+	case ToolChoice:
+		name = choice.Function.Name
+	case *ToolChoice:
+		if choice == nil {
+			return nil
+		}
+		name = choice.Function.Name
+	default:
+		// Raw values are passed through for forward compatibility.
+		return nil
+	}
+
+	if len(r.Tools) == 0 {
+		return fmt.Errorf("%w: tool_choice requires tools", ErrInvalidChatCompletionRequest)
+	}
+	if name == "" {
+		return nil
+	}
+	for _, tool := range r.Tools {
+		if tool.Function != nil && tool.Function.Name == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: tool_choice names %q, which is not one of the tools",
+		ErrInvalidChatCompletionRequest, name)
+}
+
+func (r ChatCompletionRequest) requestsFunctions() bool {
+	return len(r.Functions) > 0 || len(r.Tools) > 0
 }
 
 type FinishReason string
@@ -123,8 +569,12 @@ const (
 	FinishReasonStop          FinishReason = "stop"
 	FinishReasonLength        FinishReason = "length"
 	FinishReasonFunctionCall  FinishReason = "function_call"
+	FinishReasonToolCalls     FinishReason = "tool_calls"
 	FinishReasonContentFilter FinishReason = "content_filter"
 	FinishReasonNull          FinishReason = "null"
+	// FinishReasonUnknown is returned by NormalizeFinishReason for reasons
+	// it doesn't know.
+	FinishReasonUnknown FinishReason = "unknown"
 )
 
 type ChatCompletionChoice struct {
@@ -135,9 +585,17 @@ type ChatCompletionChoice struct {
 	// or a message terminated by one of the stop sequences provided via the stop parameter
 	// length: Incomplete model output due to max_tokens parameter or token limit
 	// function_call: The model decided to call a function
+	// tool_calls: The model decided to call one or more tools
 	// content_filter: Omitted content due to a flag from our content filters
 	// null: API response still in progress or incomplete
 	FinishReason FinishReason `json:"finish_reason"`
+	LogProbs     *LogProbs    `json:"logprobs,omitempty"`
+}
+
+// Truncated reports whether the choice was cut off by the max_tokens
+// parameter or the model's token limit.
+func (c ChatCompletionChoice) Truncated() bool {
+	return c.FinishReason == FinishReasonLength
 }
 
 // ChatCompletionResponse represents a response structure for chat completion API.
@@ -148,34 +606,139 @@ type ChatCompletionResponse struct {
 	Model   string                 `json:"model"`
 	Choices []ChatCompletionChoice `json:"choices"`
 	Usage   Usage                  `json:"usage"`
+
+	// RequestID is the ID the request was sent with, see WithRequestID.
+	RequestID string `json:"-"`
+}
+
+// WasTruncated reports whether any of the response's choices was cut off
+// by the token limit.
+func (r ChatCompletionResponse) WasTruncated() bool {
+	for _, choice := range r.Choices {
+		if choice.Truncated() {
+			return true
+		}
+	}
+	return false
 }
 
 // CreateChatCompletion — API call to Create a completion for the chat message.
 func (c *Client) CreateChatCompletion(
 	ctx context.Context,
 	request ChatCompletionRequest,
+	opts ...ChatCompletionOption,
 ) (response ChatCompletionResponse, err error) {
 	if request.Stream {
 		err = ErrChatCompletionStreamNotSupported
 		return
 	}
 
-	if !checkModelSupportsPlugins(request.Model) {
-		err = ErrModelNotSupportedWithPlugins
-		return
-	}
-
 	urlSuffix := "/chat/completions"
 	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
 		err = ErrChatCompletionInvalidModel
 		return
 	}
 
-	req, err := c.requestBuilder.Build(ctx, http.MethodPost, c.fullURL(urlSuffix, request.Model), request)
-	if err != nil {
+	options := newChatCompletionOptions(opts)
+	if options.err != nil {
+		err = options.err
 		return
 	}
+	request = c.prepareChatCompletionRequest(request, options)
+	if request.requestsFunctions() && !checkModelSupportsPlugins(request.Model) {
+		err = ErrModelNotSupportedWithPlugins
+		return
+	}
+	if err = request.Validate(); err != nil {
+		return
+	}
+
+	if c.config.DeduplicateRequests && !options.skipDeduplication {
+		return c.inflight.do(ctx, flightKey(request, options), func() (ChatCompletionResponse, error) {
+			return c.createChatCompletion(ctx, urlSuffix, request, options)
+		})
+	}
+	return c.createChatCompletion(ctx, urlSuffix, request, options)
+}
+
+// createChatCompletion sends the prepared and validated request.
+func (c *Client) createChatCompletion(
+	ctx context.Context,
+	urlSuffix string,
+	request ChatCompletionRequest,
+	options *chatCompletionOptions,
+) (response ChatCompletionResponse, err error) {
+	if c.config.ModerationGuard {
+		if err = c.moderateMessages(ctx, request.Messages); err != nil {
+			return
+		}
+	}
 
-	err = c.sendRequest(req, &response)
+	err = c.sendChatCompletion(ctx, urlSuffix, request, options, &response)
+	if c.config.TrimOnContextLengthExceeded {
+		if trimmed, ok := retryTrimmedRequest(request, err); ok {
+			err = c.sendChatCompletion(ctx, urlSuffix, trimmed, options, &response)
+		}
+	}
 	return
 }
+
+func (c *Client) sendChatCompletion(
+	ctx context.Context,
+	urlSuffix string,
+	request ChatCompletionRequest,
+	options *chatCompletionOptions,
+	response *ChatCompletionResponse,
+) error {
+	if c.config.TokenBudget != nil {
+		if err := c.config.TokenBudget.Check(); err != nil {
+			return err
+		}
+	}
+	if err := c.waitRateLimit(ctx, request); err != nil {
+		return err
+	}
+
+	body := c.chatCompletionBody(request, options)
+	req, err := c.requestBuilder.Build(ctx, http.MethodPost, c.fullURL(urlSuffix, request.Model), body)
+	if err != nil {
+		return err
+	}
+	options.applyHeaders(req)
+
+	if err = c.sendRequest(req, response); err != nil {
+		return err
+	}
+	response.RequestID = options.header.Get(RequestIDHeader)
+	if c.config.TokenBudget != nil {
+		c.config.TokenBudget.Spend(response.Usage.TotalTokens)
+	}
+	return nil
+}
+
+// prepareChatCompletionRequest applies the client-wide defaults to request.
+func (c *Client) prepareChatCompletionRequest(
+	request ChatCompletionRequest,
+	options *chatCompletionOptions,
+) ChatCompletionRequest {
+	request = c.defaults.apply(request)
+	if c.config.DefaultSystemPrompt != "" && !options.skipDefaultSystemPrompt &&
+		(len(request.Messages) == 0 || request.Messages[0].Role != ChatMessageRoleSystem) {
+		messages := make([]ChatCompletionMessage, 0, len(request.Messages)+1)
+		messages = append(messages, ChatCompletionMessage{
+			Role:    ChatMessageRoleSystem,
+			Content: c.config.DefaultSystemPrompt,
+		})
+		request.Messages = append(messages, request.Messages...)
+	}
+	if !options.skipRegisteredTools && checkModelSupportsPlugins(request.Model) {
+		request.Tools = c.tools.addTo(request.Tools)
+		if options.registeredToolExamples {
+			c.tools.addExamplesTo(&request)
+		}
+	}
+	if c.config.EscapeStopSequences && len(request.Stop) > 0 {
+		request.Stop = escapeStopSequences(request.Stop)
+	}
+	return options.responseLength.apply(request)
+}
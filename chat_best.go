@@ -0,0 +1,97 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// CreateChatCompletionBest samples n choices for request and returns the
+// message that score rates highest, resolving ties by the lowest choice
+// index. The choices are requested with N set to n; if the provider returns
+// fewer, e.g. because it ignores N, the missing ones are requested
+// concurrently, one per request.
+func (c *Client) CreateChatCompletionBest(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	n int,
+	score func(ChatCompletionMessage) float64,
+	opts ...ChatCompletionOption,
+) (ChatCompletionMessage, error) {
+	if n < 1 {
+		return ChatCompletionMessage{}, fmt.Errorf("%w: n must be at least 1, got %d",
+			ErrInvalidChatCompletionRequest, n)
+	}
+
+	request.N = n
+	response, err := c.CreateChatCompletion(ctx, request, opts...)
+	if err != nil {
+		return ChatCompletionMessage{}, err
+	}
+	choices := sortedChoices(response.Choices)
+
+	if missing := n - len(choices); missing > 0 {
+		request.N = 0
+		extra, err := c.sampleChoices(ctx, request, missing, opts)
+		if err != nil {
+			return ChatCompletionMessage{}, err
+		}
+		choices = append(choices, extra...)
+	}
+	if len(choices) == 0 {
+		return ChatCompletionMessage{}, ErrNoChoices
+	}
+
+	best, bestScore := 0, score(choices[0].Message)
+	for i := 1; i < len(choices); i++ {
+		if s := score(choices[i].Message); s > bestScore {
+			best, bestScore = i, s
+		}
+	}
+	return choices[best].Message, nil
+}
+
+// sampleChoices sends n copies of request concurrently and returns the first
+// choice of each response, in request order.
+func (c *Client) sampleChoices(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	n int,
+	opts []ChatCompletionOption,
+) ([]ChatCompletionChoice, error) {
+	// The copies are identical on purpose and must not be deduplicated.
+	opts = append(append([]ChatCompletionOption(nil), opts...), withoutDeduplication())
+	responses := make([]ChatCompletionResponse, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			responses[i], errs[i] = c.CreateChatCompletion(ctx, request, opts...)
+		}(i)
+	}
+	wg.Wait()
+
+	choices := make([]ChatCompletionChoice, 0, n)
+	for i, response := range responses {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		if len(response.Choices) > 0 {
+			choices = append(choices, response.Choices[0])
+		}
+	}
+	return choices, nil
+}
+
+// sortedChoices returns a copy of choices ordered by index.
+func sortedChoices(choices []ChatCompletionChoice) []ChatCompletionChoice {
+	sorted := make([]ChatCompletionChoice, len(choices))
+	copy(sorted, choices)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Index < sorted[j].Index
+	})
+	return sorted
+}
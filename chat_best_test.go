@@ -0,0 +1,71 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestCreateChatCompletionBest(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		var choices []ChatCompletionChoice
+		for i, content := range []string{"ok", "great", "fine", "great"}[:req.N] {
+			choices = append(choices, ChatCompletionChoice{Index: i, Message: ChatCompletionMessage{Content: content}})
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{Choices: choices})
+		_, _ = w.Write(resBytes)
+	})
+
+	score := func(msg ChatCompletionMessage) float64 {
+		return float64(len(msg.Content))
+	}
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	best, err := client.CreateChatCompletionBest(context.Background(), request, 4, score)
+	checks.NoError(t, err, "CreateChatCompletionBest error")
+	if best.Content != "great" {
+		t.Errorf("unexpected best message: %q", best.Content)
+	}
+
+	_, err = client.CreateChatCompletionBest(context.Background(), request, 0, score)
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "n below 1 should be rejected")
+}
+
+func TestCreateChatCompletionBestWithoutN(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var mu sync.Mutex
+	var requests int
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		content := []string{"a", "abc", "ab"}[requests-1]
+		mu.Unlock()
+		// The provider ignores N and always returns a single choice.
+		resBytes, _ := json.Marshal(ChatCompletionResponse{Choices: []ChatCompletionChoice{
+			{Message: ChatCompletionMessage{Content: content}},
+		}})
+		_, _ = w.Write(resBytes)
+	})
+
+	best, err := client.CreateChatCompletionBest(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}, 3, func(msg ChatCompletionMessage) float64 { return float64(len(msg.Content)) })
+	checks.NoError(t, err, "CreateChatCompletionBest error")
+	if requests != 3 || best.Content != "abc" {
+		t.Errorf("expected 3 requests and the longest message, got %d requests and %q", requests, best.Content)
+	}
+}
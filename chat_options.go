@@ -0,0 +1,208 @@
+package openai
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var ErrInvalidChatCompletionOption = errors.New("invalid chat completion option")
+
+// ChatCompletionOption configures a single CreateChatCompletion or
+// CreateChatCompletionStream call.
+type ChatCompletionOption func(*chatCompletionOptions)
+
+type chatCompletionOptions struct {
+	skipDefaultSystemPrompt bool
+	header                  http.Header
+	firstTokenTimeout       time.Duration
+	onToolCallDelta         func(index int, nameFragment, argsFragment string)
+	clientSideStop          bool
+	skipRegisteredTools     bool
+	registeredToolExamples  bool
+	omitFields              []string
+	skipDeduplication       bool
+	stopWhen                func(accumulated string) bool
+	dropRepeatedDeltas      bool
+	truncationError         bool
+	streamTokenLimit        int
+	responseLength          responseLength
+	err                     error
+}
+
+func newChatCompletionOptions(opts []ChatCompletionOption) *chatCompletionOptions {
+	options := &chatCompletionOptions{header: make(http.Header)}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+func (o *chatCompletionOptions) setHeader(key, value, name string) {
+	if value == "" {
+		if o.err == nil {
+			o.err = fmt.Errorf("%w: %s must not be empty", ErrInvalidChatCompletionOption, name)
+		}
+		return
+	}
+	o.header.Set(key, value)
+}
+
+func (o *chatCompletionOptions) applyHeaders(req *http.Request) {
+	for key, values := range o.header {
+		req.Header[key] = values
+	}
+}
+
+// WithoutDefaultSystemPrompt disables ClientConfig.DefaultSystemPrompt for the call.
+func WithoutDefaultSystemPrompt() ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.skipDefaultSystemPrompt = true
+	}
+}
+
+// WithOrganization sets the OpenAI-Organization header for the call,
+// overriding ClientConfig.OrgID.
+func WithOrganization(id string) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.setHeader("OpenAI-Organization", id, "organization")
+	}
+}
+
+// WithProject sets the OpenAI-Project header for the call.
+func WithProject(id string) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.setHeader("OpenAI-Project", id, "project")
+	}
+}
+
+// RequestIDHeader is the header WithRequestID sets.
+const RequestIDHeader = "X-Request-ID"
+
+// WithRequestID sets the X-Request-ID header for the call, e.g. to correlate
+// it with server-side logs. The ID is echoed back in
+// ChatCompletionResponse.RequestID and ChatCompletionStream.RequestID.
+func WithRequestID(id string) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.setHeader(RequestIDHeader, id, "request ID")
+	}
+}
+
+// WithFirstTokenTimeout aborts a stream if no content delta arrives within
+// timeout after the connection is established; Recv then returns an error
+// wrapping ErrFirstTokenTimeout. Once the first token has arrived the timeout
+// no longer applies. It has no effect on non-streaming calls.
+func WithFirstTokenTimeout(timeout time.Duration) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.firstTokenTimeout = timeout
+	}
+}
+
+// WithOnToolCallDelta calls fn for every tool call fragment of a stream, see
+// ChatCompletionAccumulator.OnToolCallDelta. It has no effect on
+// non-streaming calls.
+func WithOnToolCallDelta(fn func(index int, nameFragment, argsFragment string)) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.onToolCallDelta = fn
+	}
+}
+
+// WithClientSideStop enforces the request's stop sequences on the client for
+// providers that do not honor them reliably. Content is cut at the first stop
+// sequence, even one split across deltas, and the choice then reports
+// FinishReasonStop. Content that may be the beginning of a stop sequence is
+// withheld until it is decided, or returned in a final frame if the stream
+// ends first. Once every choice has stopped, the stream is closed and Recv
+// returns io.EOF. It has no effect on non-streaming calls, see
+// ChatCompletionResponse.TrimStopSequence for those.
+func WithClientSideStop() ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.clientSideStop = true
+	}
+}
+
+// WithoutRegisteredTools leaves the tools registered with Client.RegisterTool
+// out of the call's request.
+func WithoutRegisteredTools() ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.skipRegisteredTools = true
+	}
+}
+
+// WithStopWhen calls fn with the content of the first choice accumulated so
+// far after every frame of a stream. Once fn returns true, the stream is
+// closed, which aborts the request so that no further tokens are generated,
+// and Recv returns io.EOF after the frame that satisfied fn. The content is
+// then available from CollectAll or the stream's Accumulator. It has no
+// effect on non-streaming calls.
+func WithStopWhen(fn func(accumulated string) bool) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.stopWhen = fn
+	}
+}
+
+// WithDropRepeatedDeltas skips stream frames whose deltas exactly repeat the
+// previous frame, as re-sent by some proxies after reconnecting, see
+// ChatCompletionAccumulator.DropRepeatedDeltas. Recv never returns the
+// dropped deltas. It has no effect on non-streaming calls.
+func WithDropRepeatedDeltas() ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.dropRepeatedDeltas = true
+	}
+}
+
+// WithTruncationError makes Recv return a *StreamTruncatedError instead of
+// io.EOF when the stream ends without ending cleanly, see
+// ChatCompletionStream.EndedCleanly. It has no effect on non-streaming
+// calls.
+func WithTruncationError() ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.truncationError = true
+	}
+}
+
+// OmitFields drops the named top-level fields, e.g. "logit_bias" or "user",
+// from the JSON body of the call's request, for OpenAI-compatible providers
+// that reject fields they don't know.
+func OmitFields(fields ...string) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.omitFields = append(o.omitFields, fields...)
+	}
+}
+
+// chatCompletionBody returns the value to encode as the body of request.
+func (c *Client) chatCompletionBody(request ChatCompletionRequest, o *chatCompletionOptions) any {
+	if c.config.TranslateMaxTokens {
+		request = translateMaxTokens(request)
+	}
+	if c.config.ResolveModelAliases {
+		request.Model, _ = ResolveModel(request.Model)
+	}
+	if len(o.omitFields) == 0 {
+		return request
+	}
+	return omittingFields{value: request, fields: o.omitFields}
+}
+
+// omittingFields encodes value as a JSON object without fields.
+type omittingFields struct {
+	value  any
+	fields []string
+}
+
+func (o omittingFields) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(o.value)
+	if err != nil {
+		return nil, err
+	}
+	var object map[string]json.RawMessage
+	if err = json.Unmarshal(data, &object); err != nil {
+		return nil, err
+	}
+	for _, field := range o.fields {
+		delete(object, field)
+	}
+	return json.Marshal(object)
+}
@@ -0,0 +1,231 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDefaultSystemPrompt(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var received []ChatCompletionMessage
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		received = req.Messages
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.DefaultSystemPrompt = "You are a pirate."
+	client := NewClientWithConfig(config)
+
+	user := ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Hello!"}
+	system := ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: "You are a robot."}
+
+	testCases := []struct {
+		name     string
+		messages []ChatCompletionMessage
+		opts     []ChatCompletionOption
+		expected []ChatCompletionMessage
+	}{
+		{"prepended", []ChatCompletionMessage{user}, nil,
+			[]ChatCompletionMessage{{Role: ChatMessageRoleSystem, Content: "You are a pirate."}, user}},
+		{"existing system message", []ChatCompletionMessage{system, user}, nil,
+			[]ChatCompletionMessage{system, user}},
+		{"disabled", []ChatCompletionMessage{user}, []ChatCompletionOption{WithoutDefaultSystemPrompt()},
+			[]ChatCompletionMessage{user}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			messages := append([]ChatCompletionMessage(nil), tc.messages...)
+			_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+				Model:    GPT3Dot5Turbo,
+				Messages: messages,
+			}, tc.opts...)
+			checks.NoError(t, err, "CreateChatCompletion error")
+
+			if len(received) != len(tc.expected) {
+				t.Fatalf("expected %d messages, got %d", len(tc.expected), len(received))
+			}
+			for i := range tc.expected {
+				if received[i].Role != tc.expected[i].Role || received[i].Content != tc.expected[i].Content {
+					t.Errorf("message %d is %+v, expected %+v", i, received[i], tc.expected[i])
+				}
+			}
+			if len(messages) != len(tc.messages) || messages[0].Content != tc.messages[0].Content {
+				t.Error("caller's messages were modified")
+			}
+		})
+	}
+}
+
+func TestChatCompletionOrganizationAndProjectOptions(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var org, project string
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		org = r.Header.Get("OpenAI-Organization")
+		project = r.Header.Get("OpenAI-Project")
+		if r.Header.Get("Accept") == "text/event-stream" {
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.OrgID = "org-default"
+	client := NewClientWithConfig(config)
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if org != "org-default" || project != "" {
+		t.Errorf("unexpected headers without options: org=%q project=%q", org, project)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), req,
+		WithOrganization("org-tenant"), WithProject("proj-tenant"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if org != "org-tenant" || project != "proj-tenant" {
+		t.Errorf("unexpected headers with options: org=%q project=%q", org, project)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req,
+		WithOrganization("org-stream"), WithProject("proj-stream"))
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+	if org != "org-stream" || project != "proj-stream" {
+		t.Errorf("unexpected stream headers with options: org=%q project=%q", org, project)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), req, WithProject(""))
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionOption, "expected ErrInvalidChatCompletionOption")
+	_, err = client.CreateChatCompletionStream(context.Background(), req, WithOrganization(""))
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionOption, "expected ErrInvalidChatCompletionOption")
+}
+
+func TestOmitFields(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var body map[string]json.RawMessage
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "could not read request")
+		if _, ok := body["stream"]; ok {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	request := ChatCompletionRequest{
+		Model:     GPT4o,
+		Messages:  []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		User:      "user-1234",
+		LogitBias: map[string]int{"1": 5},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request, OmitFields("user", "logit_bias"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	for _, field := range []string{"user", "logit_bias"} {
+		if _, ok := body[field]; ok {
+			t.Errorf("%s was not omitted", field)
+		}
+	}
+	if _, ok := body["messages"]; !ok {
+		t.Error("messages were omitted")
+	}
+
+	request.Stream = true
+	stream, err := client.CreateChatCompletionStream(context.Background(), request, OmitFields("user"))
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+	if _, ok := body["user"]; ok {
+		t.Error("user was not omitted from the stream request")
+	}
+	if _, ok := body["logit_bias"]; !ok {
+		t.Error("logit_bias was omitted from the stream request")
+	}
+}
+
+func TestUserAgentAndRequestID(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var userAgent, requestID string
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		requestID = r.Header.Get("X-Request-ID")
+		if r.Header.Get("Accept") == "text/event-stream" {
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	client := NewClientWithConfig(config)
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	resp, err := client.CreateChatCompletion(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if !strings.HasPrefix(userAgent, "go-openai/") {
+		t.Errorf("unexpected default User-Agent %q", userAgent)
+	}
+	if requestID != "" || resp.RequestID != "" {
+		t.Errorf("unexpected request ID without option: %q, %q", requestID, resp.RequestID)
+	}
+
+	config.UserAgent = "my-service/1.2"
+	client = NewClientWithConfig(config)
+	resp, err = client.CreateChatCompletion(context.Background(), req, WithRequestID("req-123"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if userAgent != "my-service/1.2" {
+		t.Errorf("unexpected User-Agent %q", userAgent)
+	}
+	if requestID != "req-123" || resp.RequestID != "req-123" {
+		t.Errorf("unexpected request ID: sent %q, echoed %q", requestID, resp.RequestID)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req, WithRequestID("req-456"))
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+	if requestID != "req-456" || stream.RequestID() != "req-456" {
+		t.Errorf("unexpected stream request ID: sent %q, echoed %q", requestID, stream.RequestID())
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), req, WithRequestID(""))
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionOption, "expected ErrInvalidChatCompletionOption")
+}
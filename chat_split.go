@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"context"
+	"sync"
+)
+
+// CreateChatCompletionSplitN sends request like CreateChatCompletion, but if
+// its N exceeds the MaxN registered for the model, the choices are requested
+// with several concurrent requests of at most MaxN choices each. The
+// responses are merged into one, with the choices indexed consecutively and
+// the usage summed; ID, Created and Model are those of the first response.
+func (c *Client) CreateChatCompletionSplitN(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...ChatCompletionOption,
+) (ChatCompletionResponse, error) {
+	capabilities, _ := GetModelCapabilities(request.Model)
+	maxN := capabilities.MaxN
+	if maxN <= 0 || request.N <= maxN {
+		return c.CreateChatCompletion(ctx, request, opts...)
+	}
+
+	var batches []int
+	for remaining := request.N; remaining > 0; remaining -= maxN {
+		if remaining < maxN {
+			batches = append(batches, remaining)
+		} else {
+			batches = append(batches, maxN)
+		}
+	}
+
+	// Batches of the same size are identical on purpose and must not be
+	// deduplicated.
+	opts = append(append([]ChatCompletionOption(nil), opts...), withoutDeduplication())
+	responses := make([]ChatCompletionResponse, len(batches))
+	errs := make([]error, len(batches))
+	var wg sync.WaitGroup
+	for i, n := range batches {
+		wg.Add(1)
+		go func(i int, request ChatCompletionRequest) {
+			defer wg.Done()
+			responses[i], errs[i] = c.CreateChatCompletion(ctx, request, opts...)
+		}(i, withN(request, n))
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return ChatCompletionResponse{}, err
+		}
+	}
+	return mergeChatCompletionResponses(responses), nil
+}
+
+func withN(request ChatCompletionRequest, n int) ChatCompletionRequest {
+	request.N = n
+	return request
+}
+
+// mergeChatCompletionResponses combines the choices and usage of responses
+// into the first response.
+func mergeChatCompletionResponses(responses []ChatCompletionResponse) ChatCompletionResponse {
+	merged := responses[0]
+	merged.Choices = nil
+	merged.Usage = Usage{}
+	for _, response := range responses {
+		for _, choice := range sortedChoices(response.Choices) {
+			choice.Index = len(merged.Choices)
+			merged.Choices = append(merged.Choices, choice)
+		}
+		addUsage(&merged.Usage, response.Usage)
+	}
+	return merged
+}
@@ -0,0 +1,71 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func TestCreateChatCompletionSplitN(t *testing.T) {
+	const model = "split-n-test-model"
+	RegisterModelCapabilities(model, ModelCapabilities{ContextWindow: 4096, MaxN: 2})
+
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var mu sync.Mutex
+	var requestedN []int
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		mu.Lock()
+		requestedN = append(requestedN, req.N)
+		mu.Unlock()
+
+		response := ChatCompletionResponse{ID: "chatcmpl", Model: model, Usage: Usage{
+			PromptTokens: 5, CompletionTokens: req.N, TotalTokens: 5 + req.N,
+		}}
+		for i := 0; i < req.N; i++ {
+			response.Choices = append(response.Choices, ChatCompletionChoice{
+				Index:   i,
+				Message: ChatCompletionMessage{Content: fmt.Sprintf("sample %d", i)},
+			})
+		}
+		resBytes, _ := json.Marshal(response)
+		_, _ = w.Write(resBytes)
+	})
+
+	request := ChatCompletionRequest{
+		Model:    model,
+		N:        5,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "n above MaxN should be rejected")
+
+	response, err := client.CreateChatCompletionSplitN(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletionSplitN error")
+
+	sort.Ints(requestedN)
+	if fmt.Sprint(requestedN) != "[1 2 2]" {
+		t.Errorf("unexpected n of the requests: %v", requestedN)
+	}
+	if len(response.Choices) != 5 {
+		t.Fatalf("expected 5 choices, got %d", len(response.Choices))
+	}
+	for i, choice := range response.Choices {
+		if choice.Index != i {
+			t.Errorf("choice %d has index %d", i, choice.Index)
+		}
+	}
+	if response.Usage.PromptTokens != 15 || response.Usage.CompletionTokens != 5 || response.ID != "chatcmpl" {
+		t.Errorf("unexpected merged response: %+v", response)
+	}
+}
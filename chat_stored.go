@@ -0,0 +1,88 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// GetChatCompletion retrieves a chat completion created with Store set.
+func (c *Client) GetChatCompletion(ctx context.Context, id string) (response ChatCompletionResponse, err error) {
+	urlSuffix := fmt.Sprintf("/chat/completions/%s", url.PathEscape(id))
+	req, err := c.requestBuilder.Build(ctx, http.MethodGet, c.fullURL(urlSuffix), nil)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &response)
+	return
+}
+
+// ListChatCompletionsRequest filters and paginates ListChatCompletions. Zero
+// fields use the API defaults.
+type ListChatCompletionsRequest struct {
+	// After is the ID of the last completion of the previous page, see
+	// ChatCompletionsList.LastID.
+	After string
+	// Limit is the number of completions per page.
+	Limit int
+	// Order sorts the completions by creation time, "asc" or "desc".
+	Order string
+	// Model and Metadata only list completions of the model, or with all of
+	// the metadata, respectively.
+	Model    string
+	Metadata map[string]string
+}
+
+// ChatCompletionsList is a page of stored chat completions.
+type ChatCompletionsList struct {
+	Object  string                   `json:"object"`
+	Data    []ChatCompletionResponse `json:"data"`
+	FirstID string                   `json:"first_id"`
+	LastID  string                   `json:"last_id"`
+	HasMore bool                     `json:"has_more"`
+}
+
+// ListChatCompletions lists a page of the chat completions created with Store
+// set. Pass the LastID of a page as After to fetch the next one while HasMore
+// is true.
+func (c *Client) ListChatCompletions(
+	ctx context.Context,
+	request ListChatCompletionsRequest,
+) (list ChatCompletionsList, err error) {
+	query := url.Values{}
+	if request.After != "" {
+		query.Set("after", request.After)
+	}
+	if request.Limit > 0 {
+		query.Set("limit", strconv.Itoa(request.Limit))
+	}
+	if request.Order != "" {
+		query.Set("order", request.Order)
+	}
+	if request.Model != "" {
+		query.Set("model", request.Model)
+	}
+	for key, value := range request.Metadata {
+		query.Set(fmt.Sprintf("metadata[%s]", key), value)
+	}
+
+	fullURL := c.fullURL("/chat/completions")
+	if len(query) > 0 {
+		separator := "?"
+		if strings.Contains(fullURL, "?") {
+			separator = "&"
+		}
+		fullURL += separator + query.Encode()
+	}
+	req, err := c.requestBuilder.Build(ctx, http.MethodGet, fullURL, nil)
+	if err != nil {
+		return
+	}
+
+	err = c.sendRequest(req, &list)
+	return
+}
@@ -0,0 +1,102 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestStoredChatCompletions(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	stored := []ChatCompletionResponse{
+		{ID: "chatcmpl-1", Object: "chat.completion", Model: GPT4o, Choices: []ChatCompletionChoice{
+			{Message: ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "first"}},
+		}},
+		{ID: "chatcmpl-2", Object: "chat.completion", Model: GPT4o},
+		{ID: "chatcmpl-3", Object: "chat.completion", Model: GPT4o},
+	}
+	var query url.Values
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			req, err := getChatCompletionBody(r)
+			checks.NoError(t, err, "could not read request")
+			if !req.Store || req.Metadata["team"] != "search" {
+				t.Errorf("store and metadata were not sent: %+v", req)
+			}
+			resBytes, _ := json.Marshal(stored[0])
+			_, _ = w.Write(resBytes)
+			return
+		}
+
+		if id := strings.TrimPrefix(r.URL.Path, "/v1/chat/completions/"); id != r.URL.Path {
+			for _, completion := range stored {
+				if completion.ID == id {
+					resBytes, _ := json.Marshal(completion)
+					_, _ = w.Write(resBytes)
+					return
+				}
+			}
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"message":"not found","type":"invalid_request_error"}}`))
+			return
+		}
+
+		query = r.URL.Query()
+		page := stored[:2]
+		if query.Get("after") == "chatcmpl-2" {
+			page = stored[2:]
+		}
+		resBytes, _ := json.Marshal(ChatCompletionsList{
+			Object:  "list",
+			Data:    page,
+			FirstID: page[0].ID,
+			LastID:  page[len(page)-1].ID,
+			HasMore: len(page) == 2,
+		})
+		_, _ = w.Write(resBytes)
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		Store:    true,
+		Metadata: map[string]string{"team": "search"},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+
+	completion, err := client.GetChatCompletion(context.Background(), "chatcmpl-1")
+	checks.NoError(t, err, "GetChatCompletion error")
+	if completion.ID != "chatcmpl-1" || completion.Choices[0].Message.Content != "first" {
+		t.Errorf("unexpected completion %+v", completion)
+	}
+	_, err = client.GetChatCompletion(context.Background(), "chatcmpl-missing")
+	checks.HasError(t, err, "GetChatCompletion should fail for unknown IDs")
+
+	var ids []string
+	request := ListChatCompletionsRequest{Limit: 2, Model: GPT4o, Metadata: map[string]string{"team": "search"}}
+	for {
+		list, listErr := client.ListChatCompletions(context.Background(), request)
+		checks.NoError(t, listErr, "ListChatCompletions error")
+		for _, completion := range list.Data {
+			ids = append(ids, completion.ID)
+		}
+		if !list.HasMore {
+			break
+		}
+		request.After = list.LastID
+	}
+	if strings.Join(ids, ",") != "chatcmpl-1,chatcmpl-2,chatcmpl-3" {
+		t.Errorf("unexpected listed completions %v", ids)
+	}
+	if query.Get("limit") != "2" || query.Get("model") != GPT4o || query.Get("metadata[team]") != "search" {
+		t.Errorf("unexpected query %v", query)
+	}
+}
@@ -4,14 +4,40 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
 
+var (
+	ErrFirstTokenTimeout = errors.New("no content was streamed before the first token timeout")
+	ErrContentFiltered   = errors.New("content was omitted by the content filter")
+)
+
+// ToolCallDelta is a fragment of a streamed tool call. Fragments belonging to
+// the same call share an Index; ID, Type and the function name are usually
+// only present in the first fragment while Arguments arrive in pieces.
+type ToolCallDelta struct {
+	Index    *int         `json:"index,omitempty"`
+	ID       string       `json:"id,omitempty"`
+	Type     ToolType     `json:"type,omitempty"`
+	Function FunctionCall `json:"function,omitempty"`
+}
+
 type ChatCompletionStreamChoiceDelta struct {
-	Content      string       `json:"content,omitempty"`
-	Role         string       `json:"role,omitempty"`
-	FunctionCall FunctionCall `json:"function_call,omitempty"`
+	Content      string          `json:"content,omitempty"`
+	Refusal      string          `json:"refusal,omitempty"`
+	Role         string          `json:"role,omitempty"`
+	FunctionCall FunctionCall    `json:"function_call,omitempty"`
+	ToolCalls    []ToolCallDelta `json:"tool_calls,omitempty"`
+
+	// ReasoningContent is a fragment of the chain of thought streamed by
+	// some OpenAI-compatible reasoning models.
+	ReasoningContent string `json:"reasoning_content,omitempty"`
 }
 
 func (c ChatCompletionStreamChoiceDelta) MarshalJSON() ([]byte, error) {
@@ -44,9 +70,11 @@ type ChatCompletionStreamChoice struct {
 	// or a message terminated by one of the stop sequences provided via the stop parameter
 	// length: Incomplete model output due to max_tokens parameter or token limit
 	// function_call: The model decided to call a function
+	// tool_calls: The model decided to call one or more tools
 	// content_filter: Omitted content due to a flag from our content filters
 	// null: API response still in progress or incomplete
 	FinishReason FinishReason `json:"finish_reason"`
+	LogProbs     *LogProbs    `json:"logprobs,omitempty"`
 }
 
 type ChatCompletionStreamResponse struct {
@@ -55,12 +83,309 @@ type ChatCompletionStreamResponse struct {
 	Created int64                        `json:"created"`
 	Model   string                       `json:"model"`
 	Choices []ChatCompletionStreamChoice `json:"choices"`
+
+	// Usage is only set on the usage frame sent when
+	// StreamOptions.IncludeUsage is requested. That frame has no choices and
+	// is the last frame before [DONE].
+	Usage *Usage `json:"usage,omitempty"`
+}
+
+// IsUsageOnly reports whether the frame carries only usage and no choices.
+func (r ChatCompletionStreamResponse) IsUsageOnly() bool {
+	return r.Usage != nil && len(r.Choices) == 0
 }
 
 // ChatCompletionStream
 // Note: Perhaps it is more elegant to abstract Stream using generics.
 type ChatCompletionStream struct {
 	*streamReader[ChatCompletionStreamResponse]
+
+	accumulator ChatCompletionAccumulator
+	usage       *Usage
+	budget      *TokenBudget
+
+	// ctx is the context of the underlying request and cancel aborts it.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// firstTokenTimer is set before the stream is returned and never
+	// changed, since Close may stop it while Recv does.
+	firstTokenTimer    *time.Timer
+	firstTokenTimedOut int32
+
+	stopper  *streamStopper
+	stopWhen func(accumulated string) bool
+	stopped  bool
+
+	// ended is set once Recv returned io.EOF, see EndedCleanly.
+	ended           bool
+	truncationError bool
+
+	tails []*TailBuffer
+
+	requestID string
+
+	// tokenLimit and tokenizer enforce WithStreamTokenLimit; tokens counts
+	// the content received so far.
+	tokenLimit int
+	tokenizer  Tokenizer
+	tokens     int
+}
+
+// RequestID returns the ID the stream was requested with, see WithRequestID.
+func (stream *ChatCompletionStream) RequestID() string {
+	return stream.requestID
+}
+
+// Recv reads the next frame of the stream. Every frame received is also
+// merged into the stream's accumulator.
+//
+// When StreamOptions.IncludeUsage is set, the last frame before io.EOF has
+// nil Choices and only Usage populated, see IsUsageOnly.
+func (stream *ChatCompletionStream) Recv() (response ChatCompletionStreamResponse, err error) {
+	if stream.stopped {
+		err = io.EOF
+		return
+	}
+
+	response, err = stream.recvFrame()
+	flushed := false
+	if errors.Is(err, io.EOF) && stream.stopper != nil {
+		// Content withheld as a possible stop sequence is complete once the
+		// stream ends.
+		if response, flushed = stream.stopper.flush(); flushed {
+			err = nil
+		}
+	}
+	if errors.Is(err, io.EOF) {
+		stream.ended = true
+		if truncated := stream.truncation(); stream.truncationError && truncated != nil {
+			err = truncated
+		}
+		return
+	}
+	if err != nil {
+		if atomic.LoadInt32(&stream.firstTokenTimedOut) == 1 {
+			err = fmt.Errorf("%w: %v", ErrFirstTokenTimeout, err)
+		} else if ctxErr := stream.contextErr(); ctxErr != nil && !errors.Is(err, ctxErr) {
+			// Reads of a canceled request fail with transport errors that
+			// do not always wrap the cause.
+			err = fmt.Errorf("%w: %v", ctxErr, err)
+		}
+		return
+	}
+
+	if stream.stopper != nil && !flushed && stream.stopper.apply(&response) {
+		// Every choice hit a stop sequence, so there is nothing left to read.
+		stream.stopped = true
+		stream.Close()
+	}
+
+	if stream.firstTokenTimer != nil && hasContentDelta(response) {
+		stream.firstTokenTimer.Stop()
+	}
+
+	if len(response.Choices) == 0 {
+		// Usage frames may carry an empty choices array; normalize it so
+		// consumers can rely on a nil slice.
+		response.Choices = nil
+	}
+	if response.Usage != nil {
+		stream.usage = response.Usage
+		if stream.budget != nil {
+			stream.budget.Spend(response.Usage.TotalTokens)
+		}
+	}
+
+	stream.accumulator.add(response)
+	for _, choice := range response.Choices {
+		if choice.Index != 0 || choice.Delta.Content == "" {
+			continue
+		}
+		for _, tail := range stream.tails {
+			tail.WriteString(choice.Delta.Content)
+		}
+	}
+	if stream.stopWhen != nil && !stream.stopped {
+		if msg, _ := stream.accumulator.Message(0); stream.stopWhen(msg.Content) {
+			stream.stopped = true
+			stream.Close()
+		}
+	}
+	if stream.tokenLimit > 0 && !stream.stopped {
+		stream.enforceTokenLimit(&response)
+	}
+	return
+}
+
+func (stream *ChatCompletionStream) contextErr() error {
+	if stream.ctx == nil {
+		return nil
+	}
+	return stream.ctx.Err()
+}
+
+// recvFrame reads the next frame, skipping frames that only repeat the
+// previous one when the accumulator drops repeated deltas.
+func (stream *ChatCompletionStream) recvFrame() (ChatCompletionStreamResponse, error) {
+	for {
+		response, err := stream.streamReader.Recv()
+		if err != nil || !stream.accumulator.DropRepeatedDeltas || !stream.accumulator.dropRepeated(&response) {
+			return response, err
+		}
+	}
+}
+
+// CollectAll reads the rest of the stream and returns everything received as
+// a ChatCompletionResponse, the same shape CreateChatCompletion returns. All
+// N choices are included; Usage is only populated when requested with
+// StreamOptions.IncludeUsage.
+//
+// On error, e.g. context.Canceled when the stream's context is canceled, the
+// response received so far is returned along with the error, so callers can
+// decide to keep the partial output.
+func (stream *ChatCompletionStream) CollectAll() (ChatCompletionResponse, error) {
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return stream.accumulator.Response(), nil
+		}
+		if err != nil {
+			return stream.accumulator.Response(), err
+		}
+	}
+}
+
+// ToolCalls reads the rest of the stream and returns the tool calls of the
+// first choice, reassembled by index with their arguments concatenated, the
+// streaming counterpart of the ToolCalls of a ChatCompletionMessage. If the
+// stream ends without a finish reason for the first choice while tool calls
+// were received, their arguments may be cut off and ErrToolCallIncomplete is
+// returned along with them.
+func (stream *ChatCompletionStream) ToolCalls() ([]ToolCall, error) {
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	msg, _ := stream.accumulator.Message(0)
+	if len(msg.ToolCalls) > 0 && stream.accumulator.FinishReason(0) == "" {
+		return msg.ToolCalls, ErrToolCallIncomplete
+	}
+	return msg.ToolCalls, nil
+}
+
+// Finish reads the rest of the stream and returns the assistant message of
+// the first choice together with its finish reason. The message is returned
+// even on error: a *StreamTruncatedError if the stream ended without a
+// finish reason for the first choice, ErrContentFiltered if the reason is
+// FinishReasonContentFilter, or the error that ended reading, e.g.
+// context.Canceled, in which case the message holds the content received so
+// far.
+func (stream *ChatCompletionStream) Finish() (ChatCompletionMessage, FinishReason, error) {
+	for {
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) || errors.Is(err, ErrStreamTruncated) {
+			break
+		}
+		if err != nil {
+			return stream.partialMessage(), stream.accumulator.FinishReason(0), err
+		}
+	}
+
+	msg := stream.partialMessage()
+	reason := stream.accumulator.FinishReason(0)
+	switch reason {
+	case "":
+		return msg, reason, &StreamTruncatedError{ReceivedDone: stream.streamReader.isFinished, Unfinished: []int{0}}
+	case FinishReasonContentFilter:
+		return msg, reason, ErrContentFiltered
+	default:
+		return msg, reason, nil
+	}
+}
+
+// partialMessage returns the message of the first choice accumulated so far.
+func (stream *ChatCompletionStream) partialMessage() ChatCompletionMessage {
+	msg, _ := stream.accumulator.Message(0)
+	if msg.Role == "" {
+		msg.Role = ChatMessageRoleAssistant
+	}
+	return msg
+}
+
+// Tee reads the rest of the stream and writes every content delta of the
+// first choice to all writers, e.g. to display and log a response at the same
+// time. It returns nil once the stream ends, or the first error of the
+// stream or of a writer, at which point it stops reading.
+func (stream *ChatCompletionStream) Tee(writers ...io.Writer) error {
+	w := io.MultiWriter(writers...)
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for _, choice := range response.Choices {
+			if choice.Index != 0 || choice.Delta.Content == "" {
+				continue
+			}
+			if _, err = io.WriteString(w, choice.Delta.Content); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Usage returns the token usage reported by the stream, or nil if no usage
+// frame has been received. Usage is only sent when requested with
+// StreamOptions.IncludeUsage and arrives in the last frame before [DONE].
+func (stream *ChatCompletionStream) Usage() *Usage {
+	return stream.usage
+}
+
+// Close closes the stream and aborts the underlying request.
+func (stream *ChatCompletionStream) Close() {
+	if stream.firstTokenTimer != nil {
+		stream.firstTokenTimer.Stop()
+	}
+	stream.streamReader.Close()
+	if stream.cancel != nil {
+		stream.cancel()
+	}
+}
+
+func hasContentDelta(response ChatCompletionStreamResponse) bool {
+	for _, choice := range response.Choices {
+		delta := choice.Delta
+		if delta.Content != "" || delta.Refusal != "" || delta.ReasoningContent != "" ||
+			delta.FunctionCall != zeroFunctionCall || len(delta.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Refusal returns the refusal streamed by the model for the first choice, or
+// an empty string if the model did not refuse. A refusal arrives in refusal
+// deltas instead of content deltas and still ends with a regular finish
+// reason, so consumers rendering live output should check it to tell the two
+// apart.
+func (stream *ChatCompletionStream) Refusal() string {
+	return stream.accumulator.Refusal(0)
+}
+
+// Accumulator returns the accumulator holding the messages reassembled from
+// the frames received so far.
+func (stream *ChatCompletionStream) Accumulator() *ChatCompletionAccumulator {
+	return &stream.accumulator
 }
 
 // CreateChatCompletionStream — API call to create a chat completion w/ streaming
@@ -70,29 +395,65 @@ type ChatCompletionStream struct {
 func (c *Client) CreateChatCompletionStream(
 	ctx context.Context,
 	request ChatCompletionRequest,
+	opts ...ChatCompletionOption,
 ) (stream *ChatCompletionStream, err error) {
-	if !checkModelSupportsPlugins(request.Model) {
+	urlSuffix := "/chat/completions"
+	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
+		err = ErrChatCompletionInvalidModel
+		return
+	}
+
+	options := newChatCompletionOptions(opts)
+	if options.err != nil {
+		err = options.err
+		return
+	}
+	// Stop sequences are enforced as given, before they are escaped.
+	stop := request.Stop
+	request = c.prepareChatCompletionRequest(request, options)
+	if request.requestsFunctions() && !checkModelSupportsPlugins(request.Model) {
 		err = ErrModelNotSupportedWithPlugins
 		return
 	}
+	if err = request.Validate(); err != nil {
+		return
+	}
+	if c.config.ModerationGuard {
+		if err = c.moderateMessages(ctx, request.Messages); err != nil {
+			return
+		}
+	}
 
-	urlSuffix := "/chat/completions"
-	if !checkEndpointSupportsModel(urlSuffix, request.Model) {
-		err = ErrChatCompletionInvalidModel
+	if c.config.TokenBudget != nil {
+		if err = c.config.TokenBudget.Check(); err != nil {
+			return
+		}
+	}
+	if err = c.waitRateLimit(ctx, request); err != nil {
 		return
 	}
 
+	ctx, cancel := context.WithCancel(ctx)
+	defer func() {
+		if err != nil {
+			cancel()
+		}
+	}()
+
 	request.Stream = true
-	req, err := c.newStreamRequest(ctx, "POST", urlSuffix, request, request.Model)
+	req, err := c.newStreamRequest(ctx, "POST", urlSuffix, c.chatCompletionBody(request, options), request.Model)
 	if err != nil {
 		return
 	}
+	options.applyHeaders(req)
 
-	resp, err := c.config.HTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	resp, err := c.streamHTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
 	if err != nil {
 		return
 	}
+	resp.Body = c.limitResponseBody(resp.Body)
 	if isFailureStatusCode(resp) {
+		defer resp.Body.Close()
 		return nil, c.handleErrorResp(resp)
 	}
 
@@ -103,7 +464,31 @@ func (c *Client) CreateChatCompletionStream(
 			response:           resp,
 			errAccumulator:     utils.NewErrorAccumulator(),
 			unmarshaler:        &utils.JSONUnmarshaler{},
+			parser:             c.streamParser(),
+			strict:             c.config.StrictStreamParsing,
+			invalidUTF8:        c.config.InvalidUTF8,
 		},
+		ctx:       ctx,
+		cancel:    cancel,
+		budget:    c.config.TokenBudget,
+		requestID: options.header.Get(RequestIDHeader),
+	}
+	stream.accumulator.OnToolCallDelta = options.onToolCallDelta
+	if options.clientSideStop && len(stop) > 0 {
+		stream.stopper = newStreamStopper(stop, request.N)
+	}
+	stream.stopWhen = options.stopWhen
+	stream.accumulator.DropRepeatedDeltas = options.dropRepeatedDeltas
+	stream.truncationError = options.truncationError
+	if options.streamTokenLimit > 0 {
+		stream.tokenLimit = options.streamTokenLimit
+		stream.tokenizer = tokenizerForModel(request.Model)
+	}
+	if options.firstTokenTimeout > 0 {
+		stream.firstTokenTimer = time.AfterFunc(options.firstTokenTimeout, func() {
+			atomic.StoreInt32(&stream.firstTokenTimedOut, 1)
+			cancel()
+		})
 	}
 	return
 }
@@ -0,0 +1,305 @@
+package openai
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ChatCompletionAccumulator reassembles the frames of a chat completion stream
+// into complete messages. Choices are merged by their index, and so are the
+// tool calls inside a choice, which keeps the fragments of parallel tool calls
+// from interleaving.
+//
+// The zero value is ready to use.
+type ChatCompletionAccumulator struct {
+	// OnToolCallDelta, when set, is called for every streamed tool call
+	// fragment with the position of the tool call in its message and the
+	// fragments of the function name and arguments it carries. It allows
+	// rendering a tool call while its arguments are still streaming.
+	OnToolCallDelta func(index int, nameFragment, argsFragment string)
+
+	// DropRepeatedDeltas drops a choice delta that repeats the delta of the
+	// same choice in the immediately preceding frame exactly, as sent by
+	// proxies that re-send the last frame after reconnecting upstream. To
+	// avoid dropping tokens the model legitimately repeats, deltas whose
+	// content is a single character or only whitespace are always kept, as
+	// are deltas that differ in any field, including their logprobs.
+	DropRepeatedDeltas bool
+
+	choices map[int]*accumulatedChoice
+	// lastDeltas holds the encoded choices of the previous frame by index,
+	// see DropRepeatedDeltas.
+	lastDeltas map[int]string
+
+	id      string
+	model   string
+	created int64
+	usage   Usage
+}
+
+type accumulatedChoice struct {
+	role         string
+	content      strings.Builder
+	refusal      strings.Builder
+	reasoning    strings.Builder
+	functionCall FunctionCall
+	toolCalls    []ToolCall
+	// toolCallIndexes maps the index of a streamed tool call to its
+	// position in toolCalls.
+	toolCallIndexes map[int]int
+	finishReason    FinishReason
+}
+
+// AddChunk merges a single stream frame into the accumulated state.
+func (a *ChatCompletionAccumulator) AddChunk(chunk ChatCompletionStreamResponse) {
+	if a.DropRepeatedDeltas && a.dropRepeated(&chunk) {
+		return
+	}
+	a.add(chunk)
+}
+
+// dropRepeated removes the choices of chunk that repeat the previous frame,
+// see DropRepeatedDeltas, and reports whether chunk had choices and all of
+// them were removed.
+func (a *ChatCompletionAccumulator) dropRepeated(chunk *ChatCompletionStreamResponse) bool {
+	previous := a.lastDeltas
+	a.lastDeltas = make(map[int]string, len(chunk.Choices))
+	if len(chunk.Choices) == 0 {
+		return false
+	}
+
+	kept := chunk.Choices[:0:0]
+	for _, choice := range chunk.Choices {
+		encoded, err := json.Marshal(choice)
+		if err != nil {
+			kept = append(kept, choice)
+			continue
+		}
+		a.lastDeltas[choice.Index] = string(encoded)
+		if last, ok := previous[choice.Index]; ok && last == string(encoded) && isDistinctiveDelta(choice.Delta) {
+			continue
+		}
+		kept = append(kept, choice)
+	}
+	if len(kept) == 0 {
+		return true
+	}
+	chunk.Choices = kept
+	return false
+}
+
+// isDistinctiveDelta reports whether delta carries enough content that an
+// exact repetition is more likely a re-sent frame than a repeated token.
+func isDistinctiveDelta(delta ChatCompletionStreamChoiceDelta) bool {
+	if len(delta.ToolCalls) > 0 || delta.FunctionCall.Arguments != "" {
+		return true
+	}
+	for _, text := range []string{delta.Content, delta.Refusal, delta.ReasoningContent} {
+		if utf8.RuneCountInString(text) > 1 && strings.TrimFunc(text, unicode.IsSpace) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *ChatCompletionAccumulator) add(chunk ChatCompletionStreamResponse) {
+	if a.choices == nil {
+		a.choices = make(map[int]*accumulatedChoice)
+	}
+
+	if chunk.ID != "" {
+		a.id = chunk.ID
+	}
+	if chunk.Model != "" {
+		a.model = chunk.Model
+	}
+	if chunk.Created != 0 {
+		a.created = chunk.Created
+	}
+	if chunk.Usage != nil {
+		a.usage = *chunk.Usage
+	}
+
+	for _, choice := range chunk.Choices {
+		acc, ok := a.choices[choice.Index]
+		if !ok {
+			acc = &accumulatedChoice{toolCallIndexes: make(map[int]int)}
+			a.choices[choice.Index] = acc
+		}
+		acc.add(choice, a.OnToolCallDelta)
+	}
+}
+
+func (c *accumulatedChoice) add(
+	choice ChatCompletionStreamChoice,
+	onToolCallDelta func(index int, nameFragment, argsFragment string),
+) {
+	delta := choice.Delta
+	if delta.Role != "" {
+		c.role = delta.Role
+	}
+	c.content.WriteString(delta.Content)
+	c.refusal.WriteString(delta.Refusal)
+	c.reasoning.WriteString(delta.ReasoningContent)
+	c.functionCall.Name += delta.FunctionCall.Name
+	c.functionCall.Arguments += delta.FunctionCall.Arguments
+
+	for _, toolCall := range delta.ToolCalls {
+		pos := c.addToolCall(toolCall)
+		if onToolCallDelta != nil {
+			onToolCallDelta(pos, toolCall.Function.Name, string(toolCall.Function.Arguments))
+		}
+	}
+
+	if choice.FinishReason != "" {
+		c.finishReason = choice.FinishReason
+	}
+}
+
+// addToolCall merges delta into the tool calls and returns the position of
+// the tool call it belongs to.
+func (c *accumulatedChoice) addToolCall(delta ToolCallDelta) int {
+	pos := c.toolCallPosition(delta)
+	if pos < 0 {
+		c.toolCalls = append(c.toolCalls, ToolCall{})
+		pos = len(c.toolCalls) - 1
+		if delta.Index != nil {
+			c.toolCallIndexes[*delta.Index] = pos
+		}
+	}
+
+	toolCall := &c.toolCalls[pos]
+	if delta.ID != "" {
+		toolCall.ID = delta.ID
+	}
+	if delta.Type != "" {
+		toolCall.Type = delta.Type
+	}
+	toolCall.Function.Name += delta.Function.Name
+	toolCall.Function.Arguments += delta.Function.Arguments
+	return pos
+}
+
+// toolCallPosition returns the position in toolCalls the delta belongs to,
+// or -1 if it starts a new tool call.
+func (c *accumulatedChoice) toolCallPosition(delta ToolCallDelta) int {
+	if delta.Index != nil {
+		pos, ok := c.toolCallIndexes[*delta.Index]
+		if !ok {
+			return -1
+		}
+		return pos
+	}
+
+	// Some providers omit the index. Fragments without an ID then continue
+	// the most recent tool call, while a new ID starts another one.
+	last := len(c.toolCalls) - 1
+	if last < 0 {
+		return -1
+	}
+	if delta.ID != "" && delta.ID != c.toolCalls[last].ID {
+		return -1
+	}
+	return last
+}
+
+func (c *accumulatedChoice) message() ChatCompletionMessage {
+	role := c.role
+	if role == "" {
+		// Providers that omit the role from every delta still stream
+		// assistant messages.
+		role = ChatMessageRoleAssistant
+	}
+	msg := ChatCompletionMessage{
+		Role:         role,
+		Content:      c.content.String(),
+		Refusal:      c.refusal.String(),
+		FunctionCall: c.functionCall,
+
+		ReasoningContent: c.reasoning.String(),
+	}
+	if len(c.toolCalls) > 0 {
+		msg.ToolCalls = make([]ToolCall, len(c.toolCalls))
+		copy(msg.ToolCalls, c.toolCalls)
+	}
+	return msg
+}
+
+// indexes returns the accumulated choice indexes in ascending order.
+func (a *ChatCompletionAccumulator) indexes() []int {
+	indexes := make([]int, 0, len(a.choices))
+	for index := range a.choices {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	return indexes
+}
+
+// Message returns the message accumulated so far for the choice with the
+// given index.
+func (a *ChatCompletionAccumulator) Message(index int) (ChatCompletionMessage, bool) {
+	acc, ok := a.choices[index]
+	if !ok {
+		return ChatCompletionMessage{}, false
+	}
+	return acc.message(), true
+}
+
+// Messages returns the messages accumulated so far for every choice, ordered
+// by choice index.
+func (a *ChatCompletionAccumulator) Messages() []ChatCompletionMessage {
+	indexes := a.indexes()
+	messages := make([]ChatCompletionMessage, 0, len(indexes))
+	for _, index := range indexes {
+		messages = append(messages, a.choices[index].message())
+	}
+	return messages
+}
+
+// FinishReason returns the finish reason received for the choice with the
+// given index, or an empty string if none has arrived yet.
+func (a *ChatCompletionAccumulator) FinishReason(index int) FinishReason {
+	acc, ok := a.choices[index]
+	if !ok {
+		return ""
+	}
+	return acc.finishReason
+}
+
+// Refusal returns the refusal accumulated so far for the choice with the
+// given index.
+func (a *ChatCompletionAccumulator) Refusal(index int) string {
+	acc, ok := a.choices[index]
+	if !ok {
+		return ""
+	}
+	return acc.refusal.String()
+}
+
+// Response returns the accumulated state in the shape of a non-streaming
+// response: every choice with its message and finish reason, ordered by
+// index, the ID, model and creation time of the stream, and the usage if
+// a usage frame was received.
+func (a *ChatCompletionAccumulator) Response() ChatCompletionResponse {
+	indexes := a.indexes()
+	choices := make([]ChatCompletionChoice, 0, len(indexes))
+	for _, index := range indexes {
+		acc := a.choices[index]
+		choices = append(choices, ChatCompletionChoice{
+			Index:        index,
+			Message:      acc.message(),
+			FinishReason: acc.finishReason,
+		})
+	}
+	return ChatCompletionResponse{
+		ID:      a.id,
+		Object:  "chat.completion",
+		Created: a.created,
+		Model:   a.model,
+		Choices: choices,
+		Usage:   a.usage,
+	}
+}
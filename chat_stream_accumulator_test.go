@@ -0,0 +1,361 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestChatCompletionAccumulatorMergesToolCallsByIndex(t *testing.T) {
+	frames := []ChatCompletionStreamResponse{
+		{Choices: []ChatCompletionStreamChoice{{Delta: ChatCompletionStreamChoiceDelta{
+			Role: ChatMessageRoleAssistant,
+			ToolCalls: []ToolCallDelta{
+				{Index: intPtr(0), ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather"}},
+			},
+		}}}},
+		{Choices: []ChatCompletionStreamChoice{{Delta: ChatCompletionStreamChoiceDelta{
+			ToolCalls: []ToolCallDelta{
+				{Index: intPtr(1), ID: "call_b", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_time"}},
+			},
+		}}}},
+		{Choices: []ChatCompletionStreamChoice{{Delta: ChatCompletionStreamChoiceDelta{
+			ToolCalls: []ToolCallDelta{
+				{Index: intPtr(1), Function: FunctionCall{Arguments: `{"tz":`}},
+				{Index: intPtr(0), Function: FunctionCall{Arguments: `{"city":`}},
+			},
+		}}}},
+		{Choices: []ChatCompletionStreamChoice{{Delta: ChatCompletionStreamChoiceDelta{
+			ToolCalls: []ToolCallDelta{
+				{Index: intPtr(0), Function: FunctionCall{Arguments: `"Paris"}`}},
+				{Index: intPtr(1), Function: FunctionCall{Arguments: `"UTC"}`}},
+			},
+		}}}},
+		{Choices: []ChatCompletionStreamChoice{{FinishReason: FinishReasonToolCalls}}},
+	}
+
+	var acc ChatCompletionAccumulator
+	for _, frame := range frames {
+		acc.AddChunk(frame)
+	}
+
+	msg, ok := acc.Message(0)
+	if !ok {
+		t.Fatal("accumulator has no message for choice 0")
+	}
+	if msg.Role != ChatMessageRoleAssistant {
+		t.Errorf("unexpected role: %q", msg.Role)
+	}
+	expected := []ToolCall{
+		{ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		{ID: "call_b", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_time", Arguments: `{"tz":"UTC"}`}},
+	}
+	if len(msg.ToolCalls) != len(expected) {
+		t.Fatalf("expected %d tool calls, got %d", len(expected), len(msg.ToolCalls))
+	}
+	for i := range expected {
+		if msg.ToolCalls[i] != expected[i] {
+			t.Errorf("tool call %d is %+v, expected %+v", i, msg.ToolCalls[i], expected[i])
+		}
+	}
+	if acc.FinishReason(0) != FinishReasonToolCalls {
+		t.Errorf("unexpected finish reason: %q", acc.FinishReason(0))
+	}
+}
+
+func TestChatCompletionAccumulatorToolCallsWithoutIndex(t *testing.T) {
+	var acc ChatCompletionAccumulator
+	for _, delta := range []ToolCallDelta{
+		{ID: "call_a", Function: FunctionCall{Name: "a", Arguments: "{"}},
+		{Function: FunctionCall{Arguments: "}"}},
+		{ID: "call_b", Function: FunctionCall{Name: "b", Arguments: "{}"}},
+	} {
+		acc.AddChunk(ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{{
+			Delta: ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCallDelta{delta}},
+		}}})
+	}
+
+	msg, _ := acc.Message(0)
+	if len(msg.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(msg.ToolCalls))
+	}
+	if msg.ToolCalls[0].Function.Arguments != "{}" || msg.ToolCalls[1].ID != "call_b" {
+		t.Errorf("unexpected tool calls: %+v", msg.ToolCalls)
+	}
+}
+
+func TestCreateChatCompletionStreamAccumulatesToolCalls(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		//nolint:lll
+		frames := []string{
+			`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"a","arguments":""}},{"index":1,"id":"call_b","type":"function","function":{"name":"b","arguments":""}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"function":{"arguments":"{\"y\":2}"}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"x\":1}"}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		}
+		for _, frame := range frames {
+			_, err := w.Write([]byte("data: " + frame + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	var fragments []string
+	onToolCallDelta := func(index int, nameFragment, argsFragment string) {
+		fragments = append(fragments, fmt.Sprintf("%d:%s:%s", index, nameFragment, argsFragment))
+	}
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}, WithOnToolCallDelta(onToolCallDelta))
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	for {
+		_, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "stream.Recv() failed")
+	}
+
+	expectedFragments := []string{"0:a:", "1:b:", `1::{"y":2}`, `0::{"x":1}`}
+	if strings.Join(fragments, "|") != strings.Join(expectedFragments, "|") {
+		t.Errorf("unexpected tool call fragments: %q", fragments)
+	}
+
+	msg, _ := stream.Accumulator().Message(0)
+	if len(msg.ToolCalls) != 2 {
+		t.Fatalf("expected 2 tool calls, got %d", len(msg.ToolCalls))
+	}
+	if msg.ToolCalls[0].Function.Arguments != `{"x":1}` || msg.ToolCalls[1].Function.Arguments != `{"y":2}` {
+		t.Errorf("tool call arguments were not merged by index: %+v", msg.ToolCalls)
+	}
+}
+
+func TestCreateChatCompletionStreamRefusal(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		frames := []string{
+			`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","refusal":"I can't "}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"refusal":"help with that."}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		}
+		for _, frame := range frames {
+			_, err := w.Write([]byte("data: " + frame + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	for {
+		_, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "stream.Recv() failed")
+	}
+
+	if stream.Refusal() != "I can't help with that." {
+		t.Errorf("unexpected refusal: %q", stream.Refusal())
+	}
+	msg, _ := stream.Accumulator().Message(0)
+	if msg.Content != "" || msg.Refusal != stream.Refusal() {
+		t.Errorf("refusal was not accumulated separately from content: %+v", msg)
+	}
+}
+
+func TestChatCompletionAccumulatorReasoningContent(t *testing.T) {
+	var acc ChatCompletionAccumulator
+	for _, delta := range []ChatCompletionStreamChoiceDelta{
+		{Role: ChatMessageRoleAssistant, ReasoningContent: "Let me think. "},
+		{ReasoningContent: "Two plus two is four."},
+		{Content: "4"},
+	} {
+		acc.AddChunk(ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{{Delta: delta}}})
+	}
+
+	msg, _ := acc.Message(0)
+	if msg.ReasoningContent != "Let me think. Two plus two is four." || msg.Content != "4" {
+		t.Errorf("reasoning content was not accumulated separately: %+v", msg)
+	}
+}
+
+func TestChatCompletionStreamCollectAll(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		//nolint:lll
+		frames := []string{
+			`{"id":"chatcmpl-1","model":"gpt-4o","created":1700000000,"choices":[{"index":1,"delta":{"role":"assistant","content":"Hey"}},{"index":0,"delta":{"role":"assistant","content":"Hi"}}]}`,
+			`{"id":"chatcmpl-1","model":"gpt-4o","created":1700000000,"choices":[{"index":0,"delta":{"content":"!"},"finish_reason":"stop"},{"index":1,"delta":{},"finish_reason":"length"}]}`,
+			`{"id":"chatcmpl-1","model":"gpt-4o","created":1700000000,"choices":[],"usage":{"prompt_tokens":5,"completion_tokens":3,"total_tokens":8}}`,
+		}
+		for _, frame := range frames {
+			_, err := w.Write([]byte("data: " + frame + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:         GPT4o,
+		N:             2,
+		Messages:      []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	response, err := stream.CollectAll()
+	checks.NoError(t, err, "CollectAll returned error")
+	if response.ID != "chatcmpl-1" || response.Model != GPT4o || response.Created != 1700000000 {
+		t.Errorf("unexpected response metadata: %+v", response)
+	}
+	if response.Usage.TotalTokens != 8 {
+		t.Errorf("unexpected usage: %+v", response.Usage)
+	}
+	if len(response.Choices) != 2 {
+		t.Fatalf("expected 2 choices, got %d", len(response.Choices))
+	}
+	first, second := response.Choices[0], response.Choices[1]
+	if first.Index != 0 || first.Message.Content != "Hi!" || first.FinishReason != FinishReasonStop {
+		t.Errorf("unexpected first choice: %+v", first)
+	}
+	if second.Index != 1 || second.Message.Content != "Hey" || second.FinishReason != FinishReasonLength {
+		t.Errorf("unexpected second choice: %+v", second)
+	}
+}
+
+func TestChatCompletionAccumulatorRole(t *testing.T) {
+	var acc ChatCompletionAccumulator
+	acc.AddChunk(ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{
+		{Delta: ChatCompletionStreamChoiceDelta{Role: ChatMessageRoleAssistant}},
+		{Index: 1},
+	}})
+	for _, content := range []string{"Hello", ", world"} {
+		acc.AddChunk(ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{
+			{Delta: ChatCompletionStreamChoiceDelta{Content: content}},
+			{Index: 1, Delta: ChatCompletionStreamChoiceDelta{Content: content}},
+		}})
+	}
+
+	msg, ok := acc.Message(0)
+	if !ok || msg.Role != ChatMessageRoleAssistant || msg.Content != "Hello, world" {
+		t.Errorf("role of the first frame was lost: %+v", msg)
+	}
+	// The second choice never received a role.
+	if msg, _ = acc.Message(1); msg.Role != ChatMessageRoleAssistant {
+		t.Errorf("expected the role to default to assistant, got %q", msg.Role)
+	}
+}
+
+func TestChatCompletionAccumulatorDropRepeatedDeltas(t *testing.T) {
+	frame := func(content string) ChatCompletionStreamResponse {
+		return ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{
+			{Index: 0, Delta: ChatCompletionStreamChoiceDelta{Content: content}},
+		}}
+	}
+
+	acc := ChatCompletionAccumulator{DropRepeatedDeltas: true}
+	for _, content := range []string{"Hello", " world", " world", "!", "!", " ha", " ha", " ha"} {
+		acc.AddChunk(frame(content))
+	}
+	// The repeated " ha" frames are dropped too; a single-character delta
+	// such as "!" is never treated as a repetition.
+	if msg, _ := acc.Message(0); msg.Content != "Hello world!! ha" {
+		t.Errorf("unexpected content with DropRepeatedDeltas: %q", msg.Content)
+	}
+
+	acc = ChatCompletionAccumulator{}
+	for _, content := range []string{"Hello", " world", " world"} {
+		acc.AddChunk(frame(content))
+	}
+	if msg, _ := acc.Message(0); msg.Content != "Hello world world" {
+		t.Errorf("unexpected content without DropRepeatedDeltas: %q", msg.Content)
+	}
+
+	acc = ChatCompletionAccumulator{DropRepeatedDeltas: true}
+	acc.AddChunk(frame(" world"))
+	acc.AddChunk(frame("Hello"))
+	acc.AddChunk(frame(" world"))
+	if msg, _ := acc.Message(0); msg.Content != " worldHello world" {
+		t.Errorf("a delta repeated after another one must be kept, got %q", msg.Content)
+	}
+}
+
+func TestCreateChatCompletionStreamDropRepeatedDeltas(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		// The proxy loses its upstream connection after the second frame and
+		// re-sends it once it has reconnected.
+		frames := []string{
+			`data: {"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":"The answer"}}]}`,
+			`data: {"id":"1","choices":[{"index":0,"delta":{"content":" is"}}]}`,
+			`: proxy reconnected`,
+			`data: {"id":"1","choices":[{"index":0,"delta":{"content":" is"}}]}`,
+			`data: {"id":"1","choices":[{"index":0,"delta":{"content":" 42"}}]}`,
+			`data: {"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+			`data: [DONE]`,
+		}
+		for _, frame := range frames {
+			_, err := w.Write([]byte(frame + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}, WithDropRepeatedDeltas())
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	var received strings.Builder
+	for {
+		response, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "stream.Recv() failed")
+		for _, choice := range response.Choices {
+			received.WriteString(choice.Delta.Content)
+		}
+	}
+
+	if received.String() != "The answer is 42" {
+		t.Errorf("unexpected deltas received: %q", received.String())
+	}
+	if msg, _ := stream.Accumulator().Message(0); msg.Content != "The answer is 42" {
+		t.Errorf("unexpected accumulated content: %q", msg.Content)
+	}
+}
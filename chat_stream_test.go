@@ -2,6 +2,7 @@ package openai_test
 
 import (
 	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 
 	"context"
@@ -9,7 +10,10 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestChatCompletionsStreamWrongModel(t *testing.T) {
@@ -292,3 +296,438 @@ func compareChatStreamResponseChoices(c1, c2 ChatCompletionStreamChoice) bool {
 	}
 	return true
 }
+
+func TestCreateChatCompletionStreamRequestHeaders(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		expected := map[string]string{
+			"Accept":          "text/event-stream",
+			"Cache-Control":   "no-cache",
+			"Connection":      "keep-alive",
+			"Accept-Encoding": "identity",
+		}
+		for header, value := range expected {
+			if got := r.Header.Get(header); got != value {
+				t.Errorf("header %s is %q, expected %q", header, got, value)
+			}
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	stream.Close()
+}
+
+func TestCreateChatCompletionStreamHTTPVersion(t *testing.T) {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: {\"model\":\"" + r.Proto + "\"}\n\ndata: [DONE]\n\n"))
+	}))
+	ts.EnableHTTP2 = true
+	ts.StartTLS()
+	defer ts.Close()
+
+	for _, tc := range []struct {
+		version  StreamHTTPVersion
+		expected string
+	}{
+		{StreamHTTPVersion1, "HTTP/1.1"},
+		{StreamHTTPVersion2, "HTTP/2.0"},
+	} {
+		config := DefaultConfig(test.GetTestToken())
+		config.BaseURL = ts.URL + "/v1"
+		config.HTTPClient = ts.Client()
+		config.StreamHTTPVersion = tc.version
+		client := NewClientWithConfig(config)
+
+		stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+			Model:    GPT3Dot5Turbo,
+			Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		})
+		checks.NoError(t, err, "CreateChatCompletionStream returned error")
+
+		resp, err := stream.Recv()
+		checks.NoError(t, err, "stream.Recv() failed")
+		if resp.Model != tc.expected {
+			t.Errorf("stream with %s used protocol %s", tc.version, resp.Model)
+		}
+		stream.Close()
+	}
+}
+
+func TestCreateChatCompletionStreamFirstTokenTimeout(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		delay := 0 * time.Millisecond
+		if r.Header.Get("OpenAI-Project") == "slow" {
+			delay = time.Second
+		}
+
+		// The role frame does not count as the first token.
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"role":"assistant"}}]}` + "\n\n"))
+		flusher.Flush()
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"hi"}}]}` + "\n\n"))
+		flusher.Flush()
+		// Once the first token arrived, later pauses are fine.
+		time.Sleep(100 * time.Millisecond)
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"!"}}]}` + "\n\ndata: [DONE]\n\n"))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req,
+		WithFirstTokenTimeout(50*time.Millisecond), WithProject("slow"))
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	checks.ErrorIs(t, err, ErrFirstTokenTimeout, "expected ErrFirstTokenTimeout", err.Error())
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), req,
+		WithFirstTokenTimeout(50*time.Millisecond))
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+	for err == nil {
+		_, err = stream.Recv()
+	}
+	checks.ErrorIs(t, err, io.EOF, "stream should end normally", err.Error())
+}
+
+func TestCreateChatCompletionStreamFirstTokenTimeoutConcurrentClose(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"hi"}}]}` + "\n\n"))
+		flusher.Flush()
+		<-r.Context().Done()
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}, WithFirstTokenTimeout(time.Second))
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, recvErr := stream.Recv(); recvErr != nil {
+				return
+			}
+		}
+	}()
+	// Close while Recv is blocked after the first token stopped the timer.
+	time.Sleep(50 * time.Millisecond)
+	stream.Close()
+	<-done
+}
+
+func TestCreateChatCompletionStreamUsageFrame(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		if req.StreamOptions == nil || !req.StreamOptions.IncludeUsage {
+			t.Errorf("stream_options were not sent: %+v", req.StreamOptions)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		frames := []string{
+			`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}]}`,
+			`{"id":"1","choices":[],"usage":{"prompt_tokens":5,"completion_tokens":1,"total_tokens":6}}`,
+		}
+		for _, frame := range frames {
+			_, err = w.Write([]byte("data: " + frame + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err = w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:         GPT4o,
+		Messages:      []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		StreamOptions: &StreamOptions{IncludeUsage: true},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	response, err := stream.Recv()
+	checks.NoError(t, err, "stream.Recv() failed")
+	if response.IsUsageOnly() {
+		t.Error("content frame reported as usage only")
+	}
+
+	response, err = stream.Recv()
+	checks.NoError(t, err, "usage frame should not be an error")
+	if !response.IsUsageOnly() || response.Choices != nil {
+		t.Errorf("expected a usage only frame with nil choices, got %+v", response)
+	}
+	if response.Usage.TotalTokens != 6 || stream.Usage() == nil || stream.Usage().TotalTokens != 6 {
+		t.Errorf("usage was not populated: %+v", response.Usage)
+	}
+
+	_, err = stream.Recv()
+	checks.ErrorIs(t, err, io.EOF, "expected io.EOF after the usage frame")
+}
+
+func TestCreateChatCompletionStreamToolCalls(t *testing.T) {
+	for _, finished := range []bool{true, false} {
+		client, server, teardown := setupOpenAITestServer()
+		server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			frames := []string{
+				`{"choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[` +
+					`{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+				`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}`,
+				`{"choices":[{"index":0,"delta":{"tool_calls":[` +
+					`{"index":1,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+				`{"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]}}]}`,
+			}
+			if finished {
+				frames = append(frames, `{"choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`)
+			}
+			for _, frame := range frames {
+				_, _ = w.Write([]byte("data: " + frame + "\n\n"))
+			}
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+		})
+
+		stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+			Model:    GPT4o,
+			Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Weather in Paris?"}},
+		})
+		checks.NoError(t, err, "CreateChatCompletionStream returned error")
+
+		calls, err := stream.ToolCalls()
+		stream.Close()
+		teardown()
+		if finished {
+			checks.NoError(t, err, "ToolCalls error")
+		} else {
+			checks.ErrorIs(t, err, ErrToolCallIncomplete, "stream without finish reason should be incomplete")
+		}
+		if len(calls) != 2 {
+			t.Fatalf("expected 2 tool calls, got %+v", calls)
+		}
+		if calls[0].ID != "call_a" || calls[0].Function.Name != "get_weather" ||
+			calls[0].Function.Arguments != `{"city":"Paris"}` {
+			t.Errorf("unexpected first tool call: %+v", calls[0])
+		}
+		if calls[1].ID != "call_b" || calls[1].Function.Arguments != "{}" {
+			t.Errorf("unexpected second tool call: %+v", calls[1])
+		}
+	}
+}
+
+func TestCreateChatCompletionStreamStopWhen(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	aborted := make(chan struct{})
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		for _, content := range []string{"The answer", " is 42.", " END", " More"} {
+			frame := `{"choices":[{"index":0,"delta":{"content":"` + content + `"}}]}`
+			_, _ = w.Write([]byte("data: " + frame + "\n\n"))
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			close(aborted)
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+		}
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}, WithStopWhen(func(accumulated string) bool {
+		return strings.Contains(accumulated, "END")
+	}))
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	response, err := stream.CollectAll()
+	checks.NoError(t, err, "CollectAll error")
+	if content := response.Choices[0].Message.Content; content != "The answer is 42. END" {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Error("the request was not aborted")
+	}
+}
+
+type failingWriter struct {
+	writes int
+}
+
+var errTestWriteFailed = errors.New("write failed")
+
+func (w *failingWriter) Write(_ []byte) (int, error) {
+	w.writes++
+	return 0, errTestWriteFailed
+}
+
+func TestCreateChatCompletionStreamTee(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		frames := []string{
+			`{"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}},{"index":1,"delta":{"content":"Other"}}]}`,
+			`{"choices":[{"index":0,"delta":{"content":" world"}}]}`,
+			`{"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+		}
+		for _, frame := range frames {
+			_, _ = w.Write([]byte("data: " + frame + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	var display, log strings.Builder
+	err = stream.Tee(&display, &log)
+	stream.Close()
+	checks.NoError(t, err, "Tee error")
+	if display.String() != "Hello world" || log.String() != "Hello world" {
+		t.Errorf("unexpected output %q and %q", display.String(), log.String())
+	}
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+	failing := &failingWriter{}
+	err = stream.Tee(failing, &display)
+	checks.ErrorIs(t, err, errTestWriteFailed, "expected the writer error")
+	if failing.writes != 1 {
+		t.Errorf("expected Tee to stop after the failed write, got %d writes", failing.writes)
+	}
+}
+
+func TestCreateChatCompletionStreamFinish(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames []string
+		reason FinishReason
+		err    error
+	}{
+		{"stop", []string{
+			`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+			`data: {"choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"stop"}]}`,
+			`data: [DONE]`,
+		}, FinishReasonStop, nil},
+		{"content filter", []string{
+			`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+			`data: {"choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"content_filter"}]}`,
+			`data: [DONE]`,
+		}, FinishReasonContentFilter, ErrContentFiltered},
+		{"no finish reason", []string{
+			`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}`,
+			`data: {"choices":[{"index":0,"delta":{"content":" world"}}]}`,
+		}, "", ErrStreamTruncated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, server, teardown := setupOpenAITestServer()
+			defer teardown()
+			server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				for _, frame := range tt.frames {
+					_, _ = w.Write([]byte(frame + "\n\n"))
+				}
+			})
+
+			stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+				Model:    GPT4o,
+				Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+			}, WithTruncationError())
+			checks.NoError(t, err, "CreateChatCompletionStream returned error")
+			defer stream.Close()
+
+			msg, reason, err := stream.Finish()
+			if tt.err == nil {
+				checks.NoError(t, err, "Finish error")
+			} else {
+				checks.ErrorIs(t, err, tt.err, "unexpected Finish error")
+			}
+			if reason != tt.reason {
+				t.Errorf("expected finish reason %q, got %q", tt.reason, reason)
+			}
+			if msg.Role != ChatMessageRoleAssistant || msg.Content != "Hello world" {
+				t.Errorf("unexpected message %+v", msg)
+			}
+		})
+	}
+}
+
+func TestCreateChatCompletionStreamCanceledPartial(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}` + "\n\n"))
+		_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":" wor"}}]}` + "\n\n"))
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream, err := client.CreateChatCompletionStream(ctx, ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err = stream.Recv()
+		checks.NoError(t, err, "Recv error")
+	}
+	cancel()
+
+	msg, reason, err := stream.Finish()
+	checks.ErrorIs(t, err, context.Canceled, "Finish should report the cancellation")
+	if msg.Role != ChatMessageRoleAssistant || msg.Content != "Hello wor" || reason != "" {
+		t.Errorf("expected the partial message without finish reason, got %+v and %q", msg, reason)
+	}
+
+	response, err := stream.CollectAll()
+	checks.HasError(t, err, "CollectAll should fail after the cancellation")
+	if len(response.Choices) != 1 || response.Choices[0].Message.Content != "Hello wor" {
+		t.Errorf("expected the partial response, got %+v", response)
+	}
+}
@@ -2,13 +2,16 @@ package openai_test
 
 import (
 	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"runtime"
 	"strconv"
 	"strings"
 	"testing"
@@ -146,3 +149,415 @@ func getChatCompletionBody(r *http.Request) (ChatCompletionRequest, error) {
 	}
 	return completion, nil
 }
+
+func TestChatCompletionResponseWasTruncated(t *testing.T) {
+	resp := ChatCompletionResponse{Choices: []ChatCompletionChoice{
+		{Index: 0, FinishReason: FinishReasonStop},
+	}}
+	if resp.WasTruncated() {
+		t.Error("response without length finish reason reported as truncated")
+	}
+
+	resp.Choices = append(resp.Choices, ChatCompletionChoice{Index: 1, FinishReason: FinishReasonLength})
+	if !resp.WasTruncated() {
+		t.Error("response with length finish reason not reported as truncated")
+	}
+	if resp.Choices[0].Truncated() || !resp.Choices[1].Truncated() {
+		t.Error("unexpected per-choice truncation")
+	}
+}
+
+func TestArgumentsPretty(t *testing.T) {
+	args := Arguments(`{"city":"Paris","days":[1,2]}`)
+	if !args.Valid() {
+		t.Fatal("valid arguments reported as invalid")
+	}
+	pretty, err := args.Pretty()
+	checks.NoError(t, err, "Pretty error")
+	expected := "{\n  \"city\": \"Paris\",\n  \"days\": [\n    1,\n    2\n  ]\n}"
+	if pretty != expected {
+		t.Errorf("unexpected pretty arguments:\n%s", pretty)
+	}
+
+	invalid := Arguments(`{"city":`)
+	if invalid.Valid() {
+		t.Error("invalid arguments reported as valid")
+	}
+	_, err = invalid.Pretty()
+	checks.HasError(t, err, "Pretty should fail for invalid JSON")
+}
+
+func TestChatCompletionMessageReasoningContentMarshal(t *testing.T) {
+	msg := ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "4", ReasoningContent: "2+2"}
+	b, err := json.Marshal(msg)
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(b), `"reasoning_content":"2+2"`) {
+		t.Errorf("reasoning_content missing from %s", b)
+	}
+
+	msg.ReasoningContent = ""
+	msg.FunctionCall = FunctionCall{Name: "f"}
+	b, err = json.Marshal(msg)
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(b), "reasoning_content") {
+		t.Errorf("empty reasoning_content should be omitted: %s", b)
+	}
+
+	var decoded ChatCompletionMessage
+	err = json.Unmarshal([]byte(`{"role":"assistant","content":"4","reasoning_content":"2+2"}`), &decoded)
+	checks.NoError(t, err, "Unmarshal error")
+	if decoded.ReasoningContent != "2+2" {
+		t.Errorf("reasoning_content was not decoded: %+v", decoded)
+	}
+}
+
+func TestChatCompletionMessageMultiContent(t *testing.T) {
+	msg := ChatCompletionMessage{
+		Role: ChatMessageRoleUser,
+		MultiContent: []ChatMessagePart{
+			{Type: ChatMessagePartTypeText, Text: "What is this?"},
+			{Type: ChatMessagePartTypeImageURL, ImageURL: &ChatMessageImageURL{URL: "https://example.com/cat.png"}},
+		},
+	}
+	b, err := json.Marshal(msg)
+	checks.NoError(t, err, "Marshal error")
+	//nolint:lll
+	expected := `{"role":"user","content":[{"type":"text","text":"What is this?"},{"type":"image_url","image_url":{"url":"https://example.com/cat.png"}}]}`
+	if string(b) != expected {
+		t.Errorf("unexpected JSON:\n%s\nexpected:\n%s", b, expected)
+	}
+
+	var decoded ChatCompletionMessage
+	checks.NoError(t, json.Unmarshal(b, &decoded), "Unmarshal error")
+	if decoded.Content != "" || len(decoded.MultiContent) != 2 || decoded.MultiContent[1].ImageURL.URL == "" {
+		t.Errorf("multi content was not decoded: %+v", decoded)
+	}
+
+	checks.NoError(t, json.Unmarshal([]byte(`{"role":"user","content":"hi"}`), &decoded), "Unmarshal error")
+	if decoded.Content != "hi" || decoded.MultiContent != nil {
+		t.Errorf("string content was not decoded: %+v", decoded)
+	}
+
+	msg.Content = "text"
+	_, err = json.Marshal(msg)
+	checks.ErrorIs(t, err, ErrContentFieldsMisused, "expected ErrContentFieldsMisused")
+}
+
+func TestChatCompletionRequestPenalties(t *testing.T) {
+	zero := float32(0)
+	b, err := json.Marshal(ChatCompletionRequest{Model: GPT4o, PresencePenalty: &zero})
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(b), `"presence_penalty":0`) {
+		t.Errorf("explicit zero presence_penalty was omitted: %s", b)
+	}
+	if strings.Contains(string(b), "frequency_penalty") {
+		t.Errorf("unset frequency_penalty was sent: %s", b)
+	}
+
+	for _, penalty := range []float32{-2, 2} {
+		penalty := penalty
+		checks.NoError(t, ChatCompletionRequest{FrequencyPenalty: &penalty}.Validate(), "penalty in range")
+	}
+	for _, penalty := range []float32{-2.1, 2.5} {
+		penalty := penalty
+		err = ChatCompletionRequest{PresencePenalty: &penalty}.Validate()
+		checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "presence_penalty out of range")
+		err = ChatCompletionRequest{FrequencyPenalty: &penalty}.Validate()
+		checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "frequency_penalty out of range")
+	}
+}
+
+func TestChatCompletionRequestTemperature(t *testing.T) {
+	b, err := json.Marshal(ChatCompletionRequest{Model: GPT4o, Temperature: Float32(0)})
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(b), `"temperature":0`) {
+		t.Errorf("explicit zero temperature was omitted: %s", b)
+	}
+
+	b, err = json.Marshal(ChatCompletionRequest{Model: GPT4o, Temperature: OmitZeroFloat32(0), TopP: OmitZeroFloat32(0.5)})
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(b), "temperature") || !strings.Contains(string(b), `"top_p":0.5`) {
+		t.Errorf("OmitZeroFloat32 did not keep the old behavior: %s", b)
+	}
+}
+
+func TestChatCompletionRequestValidateImageDetail(t *testing.T) {
+	request := func(detail ImageURLDetail) ChatCompletionRequest {
+		return ChatCompletionRequest{Messages: []ChatCompletionMessage{{
+			Role: ChatMessageRoleUser,
+			MultiContent: []ChatMessagePart{{
+				Type:     ChatMessagePartTypeImageURL,
+				ImageURL: &ChatMessageImageURL{URL: "https://example.com/cat.png", Detail: detail},
+			}},
+		}}}
+	}
+	for _, detail := range []ImageURLDetail{"", ImageURLDetailLow, ImageURLDetailHigh, ImageURLDetailAuto} {
+		checks.NoError(t, request(detail).Validate(), "valid image detail")
+	}
+	err := request("medium").Validate()
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "unknown image detail should be rejected")
+}
+
+func TestCreateChatCompletionCanceled(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	closed := make(chan struct{})
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			close(closed)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	requested := make(chan struct{})
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		// The server only notices the client going away once the body is read.
+		_, _ = io.ReadAll(r.Body)
+		close(requested)
+		<-r.Context().Done()
+	})
+
+	transport := &http.Transport{}
+	defer transport.CloseIdleConnections()
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.HTTPClient = &http.Client{Transport: transport}
+	client := NewClientWithConfig(config)
+
+	baseline := runtime.NumGoroutine()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-requested
+		cancel()
+	}()
+	_, err := client.CreateChatCompletion(ctx, ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.ErrorIs(t, err, ErrRequestCanceled, "expected ErrRequestCanceled")
+	checks.ErrorIs(t, err, context.Canceled, "expected the error to wrap context.Canceled")
+
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatalf("connection was not released after cancellation: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := runtime.NumGoroutine(); n > baseline {
+		t.Errorf("goroutines leaked after cancellation: %d, baseline %d", n, baseline)
+	}
+}
+
+func TestChatCompletionRequestToolChoice(t *testing.T) {
+	tools := []Tool{{Type: ToolTypeFunction, Function: &Functions{Name: "get_weather"}}}
+	testCases := []struct {
+		choice   any
+		expected string
+	}{
+		{ToolChoiceAuto(), `"auto"`},
+		{ToolChoiceNone(), `"none"`},
+		{ToolChoiceRequired(), `"required"`},
+		{ToolChoiceFunction("get_weather"), `{"type":"function","function":{"name":"get_weather"}}`},
+	}
+	for _, tc := range testCases {
+		request := ChatCompletionRequest{Model: GPT4o, Tools: tools, ToolChoice: tc.choice}
+		checks.NoError(t, request.Validate(), "valid tool choice rejected")
+
+		data, err := json.Marshal(request)
+		checks.NoError(t, err, "marshal error")
+		var body map[string]json.RawMessage
+		checks.NoError(t, json.Unmarshal(data, &body), "unmarshal error")
+		if string(body["tool_choice"]) != tc.expected {
+			t.Errorf("expected tool_choice %s, got %s", tc.expected, body["tool_choice"])
+		}
+	}
+
+	request := ChatCompletionRequest{Model: GPT4o, Tools: tools, ToolChoice: ToolChoiceFunction("get_time")}
+	checks.ErrorIs(t, request.Validate(), ErrInvalidChatCompletionRequest, "undeclared tool accepted")
+
+	request = ChatCompletionRequest{Model: GPT4o, ToolChoice: ToolChoiceRequired()}
+	checks.ErrorIs(t, request.Validate(), ErrInvalidChatCompletionRequest, "tool choice without tools accepted")
+
+	request = ChatCompletionRequest{Model: GPT4o, ToolChoice: ToolChoiceNone()}
+	checks.NoError(t, request.Validate(), "tool choice none without tools rejected")
+}
+
+func TestChatCompletionRequestMistralExtensions(t *testing.T) {
+	b, err := json.Marshal(ChatCompletionRequest{Model: GPT4o})
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(b), "safe_prompt") || strings.Contains(string(b), "random_seed") {
+		t.Errorf("unset provider extensions were sent: %s", b)
+	}
+
+	b, err = json.Marshal(ChatCompletionRequest{Model: "mistral-large-latest", SafePrompt: Bool(false), RandomSeed: Int(0)})
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(b), `"safe_prompt":false`) || !strings.Contains(string(b), `"random_seed":0`) {
+		t.Errorf("explicit provider extensions were omitted: %s", b)
+	}
+}
+
+func TestChatCompletionRequestThinkingBudget(t *testing.T) {
+	b, err := json.Marshal(ChatCompletionRequest{Model: O1})
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(b), "thinking_budget") {
+		t.Errorf("unset thinking budget was sent: %s", b)
+	}
+
+	request := ChatCompletionRequest{Model: O1, ThinkingBudget: Int(0)}
+	b, err = json.Marshal(request)
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(b), `"thinking_budget":0`) {
+		t.Errorf("explicit thinking budget was omitted: %s", b)
+	}
+	checks.NoError(t, request.Validate(), "zero thinking budget rejected")
+
+	request.ThinkingBudget = Int(-1)
+	checks.ErrorIs(t, request.Validate(), ErrInvalidChatCompletionRequest, "negative thinking budget accepted")
+
+	var usage Usage
+	if usage.ReasoningTokens() != 0 {
+		t.Error("usage without details should report no reasoning tokens")
+	}
+	checks.NoError(t, json.Unmarshal([]byte(`{"completion_tokens":300,"completion_tokens_details":{"reasoning_tokens":256}}`),
+		&usage), "Unmarshal error")
+	if usage.ReasoningTokens() != 256 {
+		t.Errorf("expected 256 reasoning tokens, got %d", usage.ReasoningTokens())
+	}
+}
+
+func TestChatCompletionMessageAnnotations(t *testing.T) {
+	data := `{"role":"assistant","content":"Paris is the capital of France.","annotations":[` +
+		`{"type":"url_citation","url_citation":{"url":"https://example.com/paris","title":"Paris",` +
+		`"start_index":0,"end_index":31}}]}`
+	var msg ChatCompletionMessage
+	checks.NoError(t, json.Unmarshal([]byte(data), &msg), "Unmarshal error")
+	expected := []Annotation{{Type: AnnotationTypeURLCitation, URLCitation: &URLCitation{
+		URL: "https://example.com/paris", Title: "Paris", StartIndex: 0, EndIndex: 31,
+	}}}
+	if len(msg.Annotations) != 1 || msg.Annotations[0].Type != expected[0].Type ||
+		*msg.Annotations[0].URLCitation != *expected[0].URLCitation {
+		t.Fatalf("unexpected annotations: %+v", msg.Annotations)
+	}
+
+	b, err := json.Marshal(msg)
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(b), `"url_citation":{"url":"https://example.com/paris"`) {
+		t.Errorf("annotations were not marshaled: %s", b)
+	}
+
+	b, err = json.Marshal(ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Hi"})
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(b), "annotations") {
+		t.Errorf("empty annotations were marshaled: %s", b)
+	}
+}
+
+// retryTransport sends every request twice, replaying the body with
+// GetBody, and returns the second response.
+type retryTransport struct {
+	bodies []string
+}
+
+func (rt *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	for attempt := 0; attempt < 2; attempt++ {
+		attemptReq := req.Clone(req.Context())
+		if attempt > 0 {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq.Body = body
+		}
+		data, err := io.ReadAll(attemptReq.Body)
+		if err != nil {
+			return nil, err
+		}
+		rt.bodies = append(rt.bodies, string(data))
+		attemptReq.Body = io.NopCloser(strings.NewReader(string(data)))
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+		resp, err = http.DefaultTransport.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func TestCreateChatCompletionRetriedBody(t *testing.T) {
+	server := test.NewTestServer()
+	server.RegisterHandler("/v1/chat/completions", handleChatCompletionEndpoint)
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	transport := &retryTransport{}
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.HTTPClient = &http.Client{Transport: transport}
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:     GPT3Dot5Turbo,
+		MaxTokens: 5,
+		Messages:  []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(transport.bodies) != 2 || transport.bodies[0] == "" || transport.bodies[1] != transport.bodies[0] {
+		t.Errorf("retried request did not carry the same body: %q", transport.bodies)
+	}
+}
+
+func TestChatCompletionMessageCacheControl(t *testing.T) {
+	ephemeral := &CacheControl{Type: CacheControlTypeEphemeral}
+	b, err := json.Marshal(ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: "Long prompt", CacheControl: ephemeral})
+	checks.NoError(t, err, "Marshal error")
+	if !strings.Contains(string(b), `"cache_control":{"type":"ephemeral"}`) {
+		t.Errorf("cache control was not marshaled: %s", b)
+	}
+
+	b, err = json.Marshal(ChatCompletionMessage{Role: ChatMessageRoleUser, MultiContent: []ChatMessagePart{
+		{Type: ChatMessagePartTypeText, Text: "Long document", CacheControl: ephemeral},
+		{Type: ChatMessagePartTypeText, Text: "Question"},
+	}})
+	checks.NoError(t, err, "Marshal error")
+	if strings.Count(string(b), "cache_control") != 1 {
+		t.Errorf("cache control of parts was not marshaled only when set: %s", b)
+	}
+
+	var msg ChatCompletionMessage
+	checks.NoError(t, json.Unmarshal(b, &msg), "Unmarshal error")
+	if msg.MultiContent[0].CacheControl == nil || msg.MultiContent[0].CacheControl.Type != CacheControlTypeEphemeral {
+		t.Errorf("cache control was not decoded: %+v", msg.MultiContent[0])
+	}
+}
+
+func TestChatCompletionMessageTextContent(t *testing.T) {
+	multi := ChatCompletionMessage{Role: ChatMessageRoleUser, MultiContent: []ChatMessagePart{
+		{Type: ChatMessagePartTypeText, Text: "What is this?"},
+		{Type: ChatMessagePartTypeImageURL, ImageURL: &ChatMessageImageURL{URL: "https://example.com/a.png"}},
+		{Type: ChatMessagePartTypeText, Text: "Be brief."},
+	}}
+	if got := multi.TextContent(); got != "What is this?\nBe brief." {
+		t.Errorf("unexpected text of multimodal message: %q", got)
+	}
+	plain := ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "What is this?\nBe brief."}
+	if got := plain.TextContent(); got != plain.Content {
+		t.Errorf("unexpected text of plain message: %q", got)
+	}
+	if got := (ChatCompletionMessage{}).TextContent(); got != "" {
+		t.Errorf("unexpected text of empty message: %q", got)
+	}
+
+	if CountMessageTokens(GPT4o, []ChatCompletionMessage{multi}) != CountMessageTokens(GPT4o, []ChatCompletionMessage{plain}) {
+		t.Error("the text parts of a multimodal message should be counted like plain content")
+	}
+}
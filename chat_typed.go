@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+)
+
+var invalidSchemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// CreateChatCompletionTyped sends request with a response format requesting
+// JSON matching the schema of T, derived like the parameters of RegisterTool,
+// and decodes the content of the first choice into a T. T should be a struct,
+// as the API requires an object at the root of the schema. A ResponseFormat
+// already set on request is kept.
+//
+// Content that is not JSON fails with a *ResponseNotJSONError, see
+// CreateChatCompletionJSON, whose options such as WithJSONRetries apply.
+// Content that does not match the derived schema, e.g. because a required
+// field is missing, fails with a *SchemaViolationError and is still decoded
+// as far as possible.
+func CreateChatCompletionTyped[T any](
+	ctx context.Context,
+	client *Client,
+	request ChatCompletionRequest,
+	opts ...JSONOption,
+) (value T, response ChatCompletionResponse, err error) {
+	var schema json.RawMessage
+	if request.ResponseFormat == nil {
+		request.ResponseFormat, schema, err = jsonSchemaResponseFormat[T]()
+		if err != nil {
+			return
+		}
+	}
+
+	response, err = client.CreateChatCompletionJSON(ctx, request, &value, opts...)
+	if err != nil || schema == nil {
+		return
+	}
+	err = ValidateAgainstSchema(response.Choices[0].Message.TextContent(), schema)
+	return
+}
+
+// jsonSchemaResponseFormat returns a response format requesting JSON matching
+// the schema of T, and the schema.
+func jsonSchemaResponseFormat[T any]() (*ChatCompletionResponseFormat, json.RawMessage, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema, err := json.Marshal(schemaForType(t))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ChatCompletionResponseFormat{
+		Type: ChatCompletionResponseFormatTypeJSONSchema,
+		JSONSchema: &ChatCompletionResponseFormatJSONSchema{
+			Name:   schemaName(t),
+			Schema: schema,
+		},
+	}, schema, nil
+}
+
+// schemaName returns the name of t as a valid schema name.
+func schemaName(t reflect.Type) string {
+	name := invalidSchemaNameChars.ReplaceAllString(indirectType(t).Name(), "_")
+	if name == "" || name == "_" {
+		return "response"
+	}
+	return name
+}
@@ -0,0 +1,74 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type typedForecast struct {
+	City string  `json:"city"`
+	High float64 `json:"high"`
+	Note string  `json:"note,omitempty"`
+}
+
+func TestCreateChatCompletionTyped(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	content := `{"city":"Paris","high":21.5}`
+	var received ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		received, err = getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		resBytes, _ := json.Marshal(ChatCompletionResponse{ID: "typed", Choices: []ChatCompletionChoice{{
+			Message: ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: content},
+		}}})
+		_, _ = w.Write(resBytes)
+	})
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Forecast for Paris"}},
+	}
+	forecast, response, err := CreateChatCompletionTyped[typedForecast](context.Background(), client, request)
+	checks.NoError(t, err, "CreateChatCompletionTyped error")
+	if forecast.City != "Paris" || forecast.High != 21.5 || response.ID != "typed" {
+		t.Errorf("unexpected result %+v, %+v", forecast, response)
+	}
+
+	format := received.ResponseFormat
+	if format == nil || format.Type != ChatCompletionResponseFormatTypeJSONSchema || format.JSONSchema == nil ||
+		format.JSONSchema.Name != "typedForecast" {
+		t.Fatalf("unexpected response format %+v", format)
+	}
+	//nolint:lll
+	expected := `{"type":"object","properties":{"city":{"type":"string"},"high":{"type":"number"},"note":{"type":"string"}},"required":["city","high"]}`
+	if string(format.JSONSchema.Schema) != expected {
+		t.Errorf("unexpected schema:\n%s\nexpected:\n%s", format.JSONSchema.Schema, expected)
+	}
+
+	content = `{"city":"Paris"}`
+	forecast, _, err = CreateChatCompletionTyped[typedForecast](context.Background(), client, request)
+	checks.ErrorIs(t, err, ErrSchemaViolation, "missing required field should be reported")
+	if forecast.City != "Paris" {
+		t.Errorf("expected the content to be decoded anyway, got %+v", forecast)
+	}
+
+	content = "It will be sunny."
+	_, _, err = CreateChatCompletionTyped[typedForecast](context.Background(), client, request)
+	checks.ErrorIs(t, err, ErrResponseNotJSON, "prose should be rejected")
+
+	content = `{"anything":true}`
+	request.ResponseFormat = &ChatCompletionResponseFormat{Type: ChatCompletionResponseFormatTypeJSONObject}
+	_, _, err = CreateChatCompletionTyped[typedForecast](context.Background(), client, request)
+	checks.NoError(t, err, "a given response format should not be validated")
+	if received.ResponseFormat.Type != ChatCompletionResponseFormatTypeJSONObject {
+		t.Errorf("expected the given response format to be kept, got %+v", received.ResponseFormat)
+	}
+}
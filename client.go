@@ -1,22 +1,42 @@
 package openai
 
 import (
+	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"runtime/debug"
 	"strings"
+	"sync"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
 
+const modulePath = "github.com/sashabaranov/go-openai"
+
 // Client is OpenAI GPT-3 API client.
 type Client struct {
 	config ClientConfig
 
 	requestBuilder    utils.RequestBuilder
 	createFormBuilder func(io.Writer) utils.FormBuilder
+
+	// streamHTTPClient is used for streaming requests and may differ from
+	// config.HTTPClient in its protocol version, see ClientConfig.StreamHTTPVersion.
+	streamHTTPClient *http.Client
+
+	// tools holds the tools registered with RegisterTool.
+	tools toolRegistry
+
+	// inflight deduplicates chat completion requests, see
+	// ClientConfig.DeduplicateRequests.
+	inflight flightGroup
+
+	// defaults holds the parameters set with SetModelDefaults.
+	defaults modelDefaults
 }
 
 // NewClient creates new OpenAI API client.
@@ -33,9 +53,45 @@ func NewClientWithConfig(config ClientConfig) *Client {
 		createFormBuilder: func(body io.Writer) utils.FormBuilder {
 			return utils.NewFormBuilder(body)
 		},
+		streamHTTPClient: newStreamHTTPClient(config),
 	}
 }
 
+func newStreamHTTPClient(config ClientConfig) *http.Client {
+	if config.StreamHTTPVersion == StreamHTTPVersionDefault || config.HTTPClient == nil {
+		return config.HTTPClient
+	}
+
+	var transport *http.Transport
+	switch t := config.HTTPClient.Transport.(type) {
+	case nil:
+		transport, _ = http.DefaultTransport.(*http.Transport)
+	case *http.Transport:
+		transport = t
+	}
+	if transport == nil {
+		// A custom RoundTripper is left untouched.
+		return config.HTTPClient
+	}
+
+	transport = transport.Clone()
+	switch config.StreamHTTPVersion {
+	case StreamHTTPVersion1:
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+		if transport.TLSClientConfig != nil {
+			transport.TLSClientConfig.NextProtos = []string{"http/1.1"}
+		}
+	case StreamHTTPVersion2:
+		transport.ForceAttemptHTTP2 = true
+	case StreamHTTPVersionDefault:
+	}
+
+	client := *config.HTTPClient
+	client.Transport = transport
+	return &client
+}
+
 // NewOrgClient creates new OpenAI API client for specified Organization ID.
 //
 // Deprecated: Please use NewClientWithConfig.
@@ -55,34 +111,82 @@ func (c *Client) sendRequest(req *http.Request, v any) error {
 		req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	}
 
-	c.setCommonHeaders(req)
+	if err := c.setCommonHeaders(req); err != nil {
+		return err
+	}
+	if err := c.compressRequest(req); err != nil {
+		return err
+	}
 
 	res, err := c.config.HTTPClient.Do(req)
 	if err != nil {
-		return err
+		return canceledOr(req, err)
 	}
 
 	defer res.Body.Close()
+	res.Body = c.limitResponseBody(res.Body)
 
 	if isFailureStatusCode(res) {
 		return c.handleErrorResp(res)
 	}
 
-	return decodeResponse(res.Body, v)
+	return canceledOr(req, decodeResponse(res.Body, v))
 }
 
-func (c *Client) setCommonHeaders(req *http.Request) {
+func (c *Client) setCommonHeaders(req *http.Request) error {
+	authToken := c.config.authToken
+	if c.config.TokenProvider != nil {
+		var err error
+		authToken, err = c.config.TokenProvider(req.Context())
+		if err != nil {
+			return fmt.Errorf("token provider: %w", err)
+		}
+	}
+
 	// https://learn.microsoft.com/en-us/azure/cognitive-services/openai/reference#authentication
 	// Azure API Key authentication
 	if c.config.APIType == APITypeAzure {
-		req.Header.Set(AzureAPIKeyHeader, c.config.authToken)
+		req.Header.Set(AzureAPIKeyHeader, authToken)
 	} else {
 		// OpenAI or Azure AD authentication
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.config.authToken))
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", authToken))
 	}
-	if c.config.OrgID != "" {
+	// A per-request organization takes precedence over the configured one.
+	if c.config.OrgID != "" && req.Header.Get("OpenAI-Organization") == "" {
 		req.Header.Set("OpenAI-Organization", c.config.OrgID)
 	}
+	userAgent := c.config.UserAgent
+	if userAgent == "" {
+		userAgent = defaultUserAgent()
+	}
+	req.Header.Set("User-Agent", userAgent)
+	c.setTraceHeaders(req)
+	return nil
+}
+
+var (
+	userAgentOnce sync.Once
+	userAgent     string
+)
+
+// defaultUserAgent returns "go-openai/<version>" with the version of this
+// module in the build of the running program.
+func defaultUserAgent() string {
+	userAgentOnce.Do(func() {
+		version := "devel"
+		if info, ok := debug.ReadBuildInfo(); ok {
+			for _, dep := range info.Deps {
+				if dep.Path == modulePath {
+					version = dep.Version
+					if dep.Replace != nil && dep.Replace.Version != "" {
+						version = dep.Replace.Version
+					}
+				}
+			}
+		}
+		userAgent = "go-openai/" + version
+	})
+	return userAgent
 }
 
 func isFailureStatusCode(resp *http.Response) bool {
@@ -153,14 +257,27 @@ func (c *Client) newStreamRequest(
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
+	// Compressed responses are typically buffered by proxies and by the
+	// transport until a full block can be decoded, which delays deltas.
+	req.Header.Set("Accept-Encoding", "identity")
 
-	c.setCommonHeaders(req)
+	if err = c.setCommonHeaders(req); err != nil {
+		return nil, err
+	}
+	if err = c.compressRequest(req); err != nil {
+		return nil, err
+	}
 	return req, nil
 }
 
 func (c *Client) handleErrorResp(resp *http.Response) error {
+	c.reportError(resp)
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
 	var errRes ErrorResponse
-	err := json.NewDecoder(resp.Body).Decode(&errRes)
+	if err == nil {
+		err = json.NewDecoder(bytes.NewReader(body)).Decode(&errRes)
+	}
 	if err != nil || errRes.Error == nil {
 		reqErr := &RequestError{
 			HTTPStatusCode: resp.StatusCode,
@@ -169,9 +286,23 @@ func (c *Client) handleErrorResp(resp *http.Response) error {
 		if errRes.Error != nil {
 			reqErr.Err = errRes.Error
 		}
-		return reqErr
+		// Proxies and gateways reject credentials with bodies of their own,
+		// e.g. HTML pages, which must still be recognizable as auth errors.
+		if authErr := newAuthError(&APIError{
+			HTTPStatusCode: resp.StatusCode,
+			Message:        errorBodyMessage(body, resp.StatusCode),
+		}); authErr != nil {
+			return authErr
+		}
+		return newRateLimitError(resp, reqErr)
 	}
 
 	errRes.Error.HTTPStatusCode = resp.StatusCode
-	return errRes.Error
+	if lengthErr := newContextLengthExceededError(errRes.Error); lengthErr != nil {
+		return lengthErr
+	}
+	if authErr := newAuthError(errRes.Error); authErr != nil {
+		return authErr
+	}
+	return newRateLimitError(resp, errRes.Error)
 }
@@ -4,7 +4,53 @@ package openai
 
 // Usage Represents the total token usage per request to OpenAI.
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int                      `json:"prompt_tokens"`
+	CompletionTokens        int                      `json:"completion_tokens"`
+	TotalTokens             int                      `json:"total_tokens"`
+	PromptTokensDetails     *PromptTokensDetails     `json:"prompt_tokens_details,omitempty"`
+	CompletionTokensDetails *CompletionTokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+// PromptTokensDetails breaks down the prompt tokens of a request.
+type PromptTokensDetails struct {
+	// CachedTokens is the part of the prompt tokens served from the prompt cache.
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// CompletionTokensDetails breaks down the completion tokens of a request.
+type CompletionTokensDetails struct {
+	// ReasoningTokens is the part of the completion tokens spent on reasoning.
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// ReasoningTokens returns the completion tokens spent on reasoning, or zero if
+// the usage does not break them down.
+func (u Usage) ReasoningTokens() int {
+	if u.CompletionTokensDetails == nil {
+		return 0
+	}
+	return u.CompletionTokensDetails.ReasoningTokens
+}
+
+// addUsage adds u to total, including the token details.
+func addUsage(total *Usage, u Usage) {
+	total.PromptTokens += u.PromptTokens
+	total.CompletionTokens += u.CompletionTokens
+	total.TotalTokens += u.TotalTokens
+	if u.PromptTokensDetails != nil {
+		details := PromptTokensDetails{}
+		if total.PromptTokensDetails != nil {
+			details = *total.PromptTokensDetails
+		}
+		details.CachedTokens += u.PromptTokensDetails.CachedTokens
+		total.PromptTokensDetails = &details
+	}
+	if u.CompletionTokensDetails != nil {
+		details := CompletionTokensDetails{}
+		if total.CompletionTokensDetails != nil {
+			details = *total.CompletionTokensDetails
+		}
+		details.ReasoningTokens += u.CompletionTokensDetails.ReasoningTokens
+		total.CompletionTokensDetails = &details
+	}
 }
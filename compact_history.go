@@ -0,0 +1,99 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CompactionPrompt instructs the model to summarize the oldest messages in
+// CompactHistory.
+const CompactionPrompt = "Summarize the following conversation concisely. " +
+	"Keep every fact, decision, name and open question that later messages may rely on. " +
+	"Reply with the summary only."
+
+// SummaryPrefix starts the content of the system message that replaces the
+// messages CompactHistory summarized.
+const SummaryPrefix = "Summary of the earlier conversation: "
+
+var ErrCannotCompactHistory = errors.New("history can't be compacted into the token limit")
+
+// CompactHistory fits messages into maxTokens prompt tokens of model, counted
+// like CountMessageTokens. If they exceed it, the oldest messages are
+// summarized with a chat completion request to client and replaced with a
+// single system message starting with SummaryPrefix, placed after the leading
+// system messages; the summary is limited to a quarter of maxTokens. Enough
+// messages are summarized to fit the rest and the summary into maxTokens, but
+// the last message is always kept verbatim, and tool results are summarized
+// together with the assistant message that called them.
+//
+// Compacting is idempotent: messages that fit are returned unchanged, and a
+// summary of a previous call is summarized again together with the next
+// oldest messages. If even summarizing all but the last message can't fit
+// the limit, ErrCannotCompactHistory is returned without a request.
+func CompactHistory(
+	ctx context.Context,
+	client *Client,
+	messages []ChatCompletionMessage,
+	model string,
+	maxTokens int,
+) ([]ChatCompletionMessage, error) {
+	tokenizer := tokenizerForModel(model)
+	total := CountMessageTokens(model, messages)
+	if total <= maxTokens {
+		return messages, nil
+	}
+
+	// Leading system messages other than a previous summary are kept.
+	start := 0
+	for start < len(messages) && messages[start].Role == ChatMessageRoleSystem &&
+		!strings.HasPrefix(messages[start].Content, SummaryPrefix) {
+		start++
+	}
+
+	summaryTokens := maxTokens / 4
+	if summaryTokens < 1 {
+		summaryTokens = 1
+	}
+	// The summary costs its content, its role and the message overhead.
+	budget := maxTokens - summaryTokens - tokensPerMessage - tokenizer.CountTokens(ChatMessageRoleSystem) -
+		tokenizer.CountTokens(SummaryPrefix)
+
+	end := start
+	for end < len(messages)-1 && total > budget {
+		total -= countMessageTokens(tokenizer, messages[end])
+		end++
+		for end < len(messages)-1 && messages[end].Role == ChatMessageRoleTool {
+			total -= countMessageTokens(tokenizer, messages[end])
+			end++
+		}
+	}
+	if total > budget {
+		return messages, fmt.Errorf("%w: %d tokens left after summarizing, limit %d",
+			ErrCannotCompactHistory, total, budget)
+	}
+
+	response, err := client.CreateChatCompletion(ctx, ChatCompletionRequest{
+		Model: model,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: CompactionPrompt},
+			{Role: ChatMessageRoleUser, Content: FormatTranscript(messages[start:end])},
+		},
+		MaxTokens: summaryTokens,
+	})
+	if err != nil {
+		return messages, err
+	}
+	if len(response.Choices) == 0 {
+		return messages, ErrNoChoices
+	}
+
+	compacted := make([]ChatCompletionMessage, 0, len(messages)-(end-start)+1)
+	compacted = append(compacted, messages[:start]...)
+	compacted = append(compacted, ChatCompletionMessage{
+		Role:    ChatMessageRoleSystem,
+		Content: SummaryPrefix + strings.TrimSpace(response.Choices[0].Message.Content),
+	})
+	return append(compacted, messages[end:]...), nil
+}
@@ -0,0 +1,81 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCompactHistory(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var summarized []ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		summarized = append(summarized, req)
+		resBytes, _ := json.Marshal(ChatCompletionResponse{Choices: []ChatCompletionChoice{{
+			Message: ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: " They talked about cats. "},
+		}}})
+		_, _ = w.Write(resBytes)
+	})
+
+	RegisterTokenizer("compact-model", wordTokenizer{})
+	messages := []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "You are helpful."},
+		{Role: ChatMessageRoleUser, Content: "Tell me about cats please"},
+		{Role: ChatMessageRoleAssistant, Content: "Cats are small furry carnivores kept as pets"},
+		{Role: ChatMessageRoleUser, Content: "And their whiskers?"},
+		{Role: ChatMessageRoleAssistant, Content: "Whiskers help them sense their surroundings"},
+		{Role: ChatMessageRoleUser, Content: "Thanks"},
+	}
+	ctx := context.Background()
+
+	fitting, err := CompactHistory(ctx, client, messages, "compact-model", 1000)
+	checks.NoError(t, err, "CompactHistory error")
+	if len(fitting) != len(messages) || len(summarized) != 0 {
+		t.Fatalf("fitting messages should be returned unchanged without a request")
+	}
+
+	const limit = 40
+	compacted, err := CompactHistory(ctx, client, messages, "compact-model", limit)
+	checks.NoError(t, err, "CompactHistory error")
+	if len(summarized) != 1 || summarized[0].MaxTokens != limit/4 {
+		t.Fatalf("expected one summary request limited to a quarter of the limit, got %+v", summarized)
+	}
+	transcript := summarized[0].Messages[1].Content
+	if !strings.Contains(transcript, "Tell me about cats please") || strings.Contains(transcript, "Thanks") ||
+		strings.Contains(transcript, "You are helpful.") {
+		t.Errorf("unexpected summarized transcript %q", transcript)
+	}
+	if compacted[0].Content != "You are helpful." || compacted[1].Role != ChatMessageRoleSystem ||
+		compacted[1].Content != SummaryPrefix+"They talked about cats." ||
+		compacted[len(compacted)-1].Content != "Thanks" {
+		t.Errorf("unexpected compacted messages %+v", compacted)
+	}
+	if got := CountMessageTokens("compact-model", compacted); got > limit {
+		t.Errorf("compacted messages take %d tokens, limit %d", got, limit)
+	}
+
+	again, err := CompactHistory(ctx, client, compacted, "compact-model", limit)
+	checks.NoError(t, err, "CompactHistory error")
+	if len(again) != len(compacted) || len(summarized) != 1 {
+		t.Errorf("compacting again should be a no-op, got %+v", again)
+	}
+
+	longer := append(append([]ChatCompletionMessage(nil), compacted...), messages[1:]...)
+	_, err = CompactHistory(ctx, client, longer, "compact-model", limit)
+	checks.NoError(t, err, "CompactHistory error")
+	if len(summarized) != 2 || !strings.Contains(summarized[1].Messages[1].Content, SummaryPrefix) {
+		t.Errorf("expected the previous summary to be summarized again, got %+v", summarized)
+	}
+
+	_, err = CompactHistory(ctx, client, messages, "compact-model", 5)
+	checks.ErrorIs(t, err, ErrCannotCompactHistory, "a limit below the last message should be rejected")
+}
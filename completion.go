@@ -17,6 +17,11 @@ var (
 // GPT3 Models are designed for text-based tasks. For code-specific
 // tasks, please refer to the Codex series of models.
 const (
+	O1Mini                  = "o1-mini"
+	O1                      = "o1"
+	GPT4oMini               = "gpt-4o-mini"
+	GPT4o                   = "gpt-4o"
+	GPT4Turbo               = "gpt-4-turbo"
 	GPT432K0613             = "gpt-4-32k-0613"
 	GPT432K0314             = "gpt-4-32k-0314"
 	GPT432K                 = "gpt-4-32k"
@@ -28,6 +33,7 @@ const (
 	GPT3Dot5Turbo16K        = "gpt-3.5-turbo-16k"
 	GPT3Dot5Turbo16K0613    = "gpt-3.5-turbo-16k-0613"
 	GPT3Dot5Turbo           = "gpt-3.5-turbo"
+	GPT35Turbo              = GPT3Dot5Turbo
 	GPT3TextDavinci003      = "text-davinci-003"
 	GPT3TextDavinci002      = "text-davinci-002"
 	GPT3TextCurie001        = "text-curie-001"
@@ -53,6 +59,11 @@ const (
 
 var disabledModelsForEndpoints = map[string]map[string]bool{
 	"/completions": {
+		O1Mini:               true,
+		O1:                   true,
+		GPT4oMini:            true,
+		GPT4o:                true,
+		GPT4Turbo:            true,
 		GPT3Dot5Turbo:        true,
 		GPT3Dot5Turbo0301:    true,
 		GPT3Dot5Turbo0613:    true,
@@ -88,17 +99,10 @@ func checkEndpointSupportsModel(endpoint, model string) bool {
 	return !disabledModelsForEndpoints[endpoint][model]
 }
 
-// 模型是否支持插件
-var disabledPluginsForModels = map[string]bool{
-	GPT3Dot5Turbo0613:    true,
-	GPT3Dot5Turbo16K:     true,
-	GPT3Dot5Turbo16K0613: true,
-	GPT40613:             true,
-	GPT432K0613:          true,
-}
-
+// 模型是否支持插件，未登记的模型视为支持
 func checkModelSupportsPlugins(model string) bool {
-	return disabledPluginsForModels[model]
+	capabilities, ok := GetModelCapabilities(model)
+	return !ok || capabilities.SupportsFunctions
 }
 
 func checkPromptType(prompt any) bool {
@@ -0,0 +1,54 @@
+package openai
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DefaultCompressionThreshold is the body size in bytes from which requests
+// are compressed with ClientConfig.CompressRequests unless
+// ClientConfig.CompressionThreshold is set.
+const DefaultCompressionThreshold = 64 << 10
+
+// compressRequest gzips the JSON body of req if compression is enabled and
+// the body is at least the configured threshold.
+func (c *Client) compressRequest(req *http.Request) error {
+	if !c.config.CompressRequests || req.GetBody == nil || req.Header.Get("Content-Encoding") != "" ||
+		!strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+	threshold := c.config.CompressionThreshold
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+	if req.ContentLength < int64(threshold) {
+		return nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err = io.Copy(zw, body); err != nil {
+		return err
+	}
+	if err = zw.Close(); err != nil {
+		return err
+	}
+
+	data := compressed.Bytes()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Encoding", "gzip")
+	return nil
+}
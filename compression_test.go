@@ -0,0 +1,84 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCompressRequests(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var encoding string
+	var received ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		encoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			checks.NoError(t, err, "could not decompress request")
+			defer zr.Close()
+			body = zr
+		}
+		received = ChatCompletionRequest{}
+		checks.NoError(t, json.NewDecoder(body).Decode(&received), "could not decode request")
+		if received.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[]}`))
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.CompressRequests = true
+	config.CompressionThreshold = 1024
+	client := NewClientWithConfig(config)
+
+	large := strings.Repeat("context ", 1000)
+	request := func(content string) ChatCompletionRequest {
+		return ChatCompletionRequest{
+			Model:    GPT4o,
+			Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: content}},
+		}
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), request(large))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if encoding != "gzip" || received.Messages[0].Content != large {
+		t.Errorf("expected a gzipped request with the full content, got encoding %q", encoding)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), request(large))
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+	if encoding != "gzip" || received.Messages[0].Content != large {
+		t.Errorf("expected a gzipped stream request with the full content, got encoding %q", encoding)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), request("Hello!"))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if encoding != "" || received.Messages[0].Content != "Hello!" {
+		t.Errorf("expected a small request to be sent uncompressed, got encoding %q", encoding)
+	}
+
+	config.CompressRequests = false
+	client = NewClientWithConfig(config)
+	_, err = client.CreateChatCompletion(context.Background(), request(large))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if encoding != "" {
+		t.Errorf("expected no compression without CompressRequests, got encoding %q", encoding)
+	}
+}
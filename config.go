@@ -1,6 +1,7 @@
 package openai
 
 import (
+	"context"
 	"net/http"
 	"regexp"
 )
@@ -23,6 +24,19 @@ const (
 
 const AzureAPIKeyHeader = "api-key"
 
+// StreamHTTPVersion selects the HTTP protocol version used for streaming requests.
+type StreamHTTPVersion string
+
+const (
+	// StreamHTTPVersionDefault lets the HTTP client negotiate the protocol.
+	StreamHTTPVersionDefault StreamHTTPVersion = ""
+	// StreamHTTPVersion1 forces HTTP/1.1, for proxies that buffer HTTP/2 streams.
+	StreamHTTPVersion1 StreamHTTPVersion = "HTTP/1.1"
+	// StreamHTTPVersion2 attempts HTTP/2 even with a customized transport.
+	// HTTP/2 is only negotiated over TLS.
+	StreamHTTPVersion2 StreamHTTPVersion = "HTTP/2"
+)
+
 // ClientConfig is a configuration of a client.
 type ClientConfig struct {
 	authToken string
@@ -33,8 +47,118 @@ type ClientConfig struct {
 	APIVersion           string                    // required when APIType is APITypeAzure or APITypeAzureAD
 	AzureModelMapperFunc func(model string) string // replace model to azure deployment name func
 	HTTPClient           *http.Client
+	// TokenProvider, when set, is called for every request to fetch the
+	// token placed in the authentication header instead of the static one,
+	// e.g. for short-lived workload identity credentials.
+	TokenProvider func(ctx context.Context) (string, error)
 
 	EmptyMessagesLimit uint
+
+	// MaxResponseBytes caps the bytes read from the body of a response,
+	// including the whole of a stream, to protect against runaway or
+	// malicious upstreams. Reading past it fails with ErrResponseTooLarge.
+	// Zero means no limit.
+	MaxResponseBytes int64
+
+	// UserAgent is sent as the User-Agent header of every request. It
+	// defaults to "go-openai/<version>" with the version of this module.
+	UserAgent string
+
+	// CompressRequests gzips JSON request bodies of at least
+	// CompressionThreshold bytes and sends them with Content-Encoding: gzip,
+	// e.g. to save upload bandwidth for large prompts. Only enable it for
+	// servers or proxies that accept compressed request bodies.
+	CompressRequests bool
+	// CompressionThreshold defaults to DefaultCompressionThreshold.
+	CompressionThreshold int
+
+	// StreamParser parses the lines of streamed responses. It defaults to
+	// OpenAIStreamParser.
+	StreamParser StreamParser
+
+	// StrictStreamParsing makes Recv fail with a *StreamFrameError holding
+	// the raw line as soon as a stream frame is not valid JSON or a "data:"
+	// line is not recognized by the StreamParser, instead of counting it
+	// towards EmptyMessagesLimit. This surfaces provider bugs immediately.
+	StrictStreamParsing bool
+
+	// InvalidUTF8 selects whether stream frames that are not valid UTF-8
+	// fail Recv instead of having their invalid sequences replaced with
+	// U+FFFD. Frames are checked individually, so a character split across
+	// frames counts as invalid.
+	InvalidUTF8 InvalidUTF8Handling
+
+	// StreamHTTPVersion forces the protocol version of streaming requests.
+	// It only applies when HTTPClient uses an *http.Transport (or the
+	// default one), which is cloned for streaming.
+	StreamHTTPVersion StreamHTTPVersion
+
+	// DefaultSystemPrompt is prepended as a system message to chat completion
+	// requests that don't start with one. Disable it for a single call with
+	// WithoutDefaultSystemPrompt.
+	DefaultSystemPrompt string
+
+	// RateLimiter, when set, is waited on before every chat completion request.
+	RateLimiter RateLimiter
+
+	// EscapeStopSequences sends line breaks and tabs in stop sequences as
+	// backslash escapes, for proxies that mangle raw control characters.
+	// Only enable it for providers that unescape them again.
+	EscapeStopSequences bool
+
+	// TrimOnContextLengthExceeded makes CreateChatCompletion retry once with
+	// the oldest non-system messages dropped when the API reports that the
+	// request exceeds the model's context window.
+	TrimOnContextLengthExceeded bool
+
+	// ModerationGuard runs the content of every user message through the
+	// moderation endpoint before a chat completion request is sent, and
+	// fails the request with a *ContentFlaggedError if any is flagged.
+	ModerationGuard bool
+
+	// TokenBudget, when set, is charged with the total tokens of every chat
+	// completion response. Once it is exhausted, requests fail with
+	// ErrBudgetExceeded before they are sent. Streams are only charged when
+	// they request usage with StreamOptions.IncludeUsage.
+	TokenBudget *TokenBudget
+
+	// DeduplicateRequests makes concurrent CreateChatCompletion calls with
+	// identical requests and options share a single upstream request and its
+	// result, e.g. to prevent a cache stampede. Streams are not deduplicated.
+	// The shared request runs with the context of the first caller: if that
+	// context is canceled, every caller waiting for the request fails with
+	// the cancellation, while waiting callers whose own context ends return
+	// early.
+	DeduplicateRequests bool
+
+	// PropagateTraceContext sends the W3C traceparent and tracestate headers
+	// of the trace context that TraceContextExtractor finds in the context of
+	// each request, tying the requests into distributed traces.
+	PropagateTraceContext bool
+	// TraceContextExtractor returns the trace context of ctx. It defaults to
+	// TraceContextFromContext; set it to adapt a tracing library, e.g. to
+	// render the current OpenTelemetry span as a TraceContext.
+	TraceContextExtractor func(ctx context.Context) (TraceContext, bool)
+
+	// ResolveModelAliases sends chat completion requests for a model alias
+	// such as "gpt-4o" with the snapshot it resolves to, see ResolveModel, so
+	// that logs and usage reflect the concrete model. Capabilities, prices
+	// and Azure deployments are still looked up by the requested model.
+	ResolveModelAliases bool
+
+	// TranslateMaxTokens sends the token limit of chat completion requests in
+	// the field the model accepts: MaxTokens is sent as max_completion_tokens
+	// to models whose capabilities require it, such as o1, and
+	// MaxCompletionTokens as max_tokens to other registered models. A limit
+	// set in both fields is kept as is, except that max_tokens is never sent
+	// to models that reject it. Requests for models without registered
+	// capabilities are sent as they are.
+	TranslateMaxTokens bool
+
+	// OnError is called with a DebugBundle of every request the API answers
+	// with a non-2xx status, before the error is returned, e.g. to save it
+	// for a bug report. Secret headers are redacted.
+	OnError func(DebugBundle)
 }
 
 func DefaultConfig(authToken string) ClientConfig {
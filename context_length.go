@@ -0,0 +1,145 @@
+package openai
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+// ErrContextLengthExceeded is matched by errors returned when a request does
+// not fit into the model's context window.
+var ErrContextLengthExceeded = errors.New("context length exceeded")
+
+const contextLengthExceededCode = "context_length_exceeded"
+
+var (
+	maxContextLengthRe = regexp.MustCompile(`maximum context length is (\d+) tokens`)
+	requestedTokensRe  = regexp.MustCompile(`(?:requested|resulted in) (\d+) tokens`)
+	messageTokensRe    = regexp.MustCompile(`(\d+) in the messages`)
+	completionTokensRe = regexp.MustCompile(`(\d+) in the completion`)
+)
+
+// ContextLengthExceededError is returned when the API rejects a request with
+// the context_length_exceeded code. The token numbers are parsed from the
+// error message and are zero when the message does not mention them.
+// It matches ErrContextLengthExceeded with errors.Is and unwraps to the
+// underlying *APIError.
+type ContextLengthExceededError struct {
+	// MaxTokens is the context window of the model.
+	MaxTokens int
+	// RequestedTokens is the total of PromptTokens and CompletionTokens.
+	RequestedTokens  int
+	PromptTokens     int
+	CompletionTokens int
+
+	Err *APIError
+}
+
+func (e *ContextLengthExceededError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ContextLengthExceededError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ContextLengthExceededError) Is(target error) bool {
+	return target == ErrContextLengthExceeded
+}
+
+// ExcessTokens returns how many tokens the request is over the context
+// window, or zero if that is unknown.
+func (e *ContextLengthExceededError) ExcessTokens() int {
+	if e.MaxTokens == 0 || e.RequestedTokens <= e.MaxTokens {
+		return 0
+	}
+	return e.RequestedTokens - e.MaxTokens
+}
+
+// newContextLengthExceededError returns a *ContextLengthExceededError if
+// apiErr reports an exceeded context length, or nil otherwise.
+func newContextLengthExceededError(apiErr *APIError) *ContextLengthExceededError {
+	if code, ok := apiErr.Code.(string); !ok || code != contextLengthExceededCode {
+		return nil
+	}
+
+	e := &ContextLengthExceededError{
+		MaxTokens:        parseTokenNumber(maxContextLengthRe, apiErr.Message),
+		RequestedTokens:  parseTokenNumber(requestedTokensRe, apiErr.Message),
+		PromptTokens:     parseTokenNumber(messageTokensRe, apiErr.Message),
+		CompletionTokens: parseTokenNumber(completionTokensRe, apiErr.Message),
+		Err:              apiErr,
+	}
+	if e.PromptTokens == 0 && e.CompletionTokens == 0 {
+		// "your messages resulted in N tokens" counts the prompt only.
+		e.PromptTokens = e.RequestedTokens
+	}
+	return e
+}
+
+func parseTokenNumber(re *regexp.Regexp, message string) int {
+	match := re.FindStringSubmatch(message)
+	if match == nil {
+		return 0
+	}
+	n, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// trimOldestMessages drops the oldest messages until at least excess prompt
+// tokens have been removed, or a single message if excess is unknown. Tool
+// results are dropped together with the assistant message that called them.
+// Leading system messages and the last message are always kept, the latter
+// with the assistant message and tool results it belongs to. It reports
+// false if nothing could be dropped.
+func trimOldestMessages(
+	model string,
+	messages []ChatCompletionMessage,
+	excess int,
+) ([]ChatCompletionMessage, bool) {
+	start := 0
+	for start < len(messages) && messages[start].Role == ChatMessageRoleSystem {
+		start++
+	}
+	last := len(messages) - 1
+	for last > start && messages[last].Role == ChatMessageRoleTool {
+		last--
+	}
+
+	tokenizer := tokenizerForModel(model)
+	end, removed := start, 0
+	for end < last && (end == start || removed < excess) {
+		removed += countMessageTokens(tokenizer, messages[end])
+		end++
+		for end < last && messages[end].Role == ChatMessageRoleTool {
+			removed += countMessageTokens(tokenizer, messages[end])
+			end++
+		}
+	}
+	if end == start {
+		return messages, false
+	}
+
+	trimmed := make([]ChatCompletionMessage, 0, len(messages)-(end-start))
+	trimmed = append(trimmed, messages[:start]...)
+	trimmed = append(trimmed, messages[end:]...)
+	return trimmed, true
+}
+
+// retryTrimmedRequest returns request with its oldest messages trimmed to fit
+// the context window reported by err, and whether a retry is worth it.
+func retryTrimmedRequest(request ChatCompletionRequest, err error) (ChatCompletionRequest, bool) {
+	var lengthErr *ContextLengthExceededError
+	if !errors.As(err, &lengthErr) {
+		return request, false
+	}
+	messages, ok := trimOldestMessages(request.Model, request.Messages, lengthErr.ExcessTokens())
+	if !ok {
+		return request, false
+	}
+	request.Messages = messages
+	return request, true
+}
@@ -0,0 +1,145 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+//nolint:lll
+const contextLengthExceededBody = `{"error":{"message":"This model's maximum context length is 4097 tokens. However, you requested 4250 tokens (4000 in the messages, 250 in the completion). Please reduce the length of the messages or completion.","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`
+
+func TestContextLengthExceededError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(contextLengthExceededBody))
+	})
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.ErrorIs(t, err, ErrContextLengthExceeded, "expected ErrContextLengthExceeded")
+
+	var lengthErr *ContextLengthExceededError
+	if !errors.As(err, &lengthErr) {
+		t.Fatalf("expected *ContextLengthExceededError, got %T", err)
+	}
+	if lengthErr.MaxTokens != 4097 || lengthErr.RequestedTokens != 4250 ||
+		lengthErr.PromptTokens != 4000 || lengthErr.CompletionTokens != 250 {
+		t.Errorf("unexpected token numbers: %+v", lengthErr)
+	}
+	if lengthErr.ExcessTokens() != 153 {
+		t.Errorf("unexpected excess tokens: %d", lengthErr.ExcessTokens())
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Errorf("expected the error to unwrap to *APIError, got %v", err)
+	}
+}
+
+func TestTrimOnContextLengthExceeded(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var received [][]ChatCompletionMessage
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		received = append(received, req.Messages)
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(contextLengthExceededBody))
+			return
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.TrimOnContextLengthExceeded = true
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model: GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: ChatMessageRoleUser, Content: "Hi"},
+			{Role: ChatMessageRoleAssistant, Content: "Hello!"},
+			{Role: ChatMessageRoleUser, Content: "How are you?"},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion should succeed after trimming")
+
+	if len(received) != 2 {
+		t.Fatalf("expected one retry, got %d requests", len(received))
+	}
+	retried := received[1]
+	if len(retried) >= len(received[0]) || retried[0].Role != ChatMessageRoleSystem ||
+		retried[len(retried)-1].Content != "How are you?" {
+		t.Errorf("unexpected trimmed messages: %+v", retried)
+	}
+}
+
+func TestTrimOnContextLengthExceededKeepsToolResults(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var received [][]ChatCompletionMessage
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		received = append(received, req.Messages)
+		if len(received) == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(contextLengthExceededBody))
+			return
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.TrimOnContextLengthExceeded = true
+	client := NewClientWithConfig(config)
+
+	toolCalls := []ToolCall{
+		{ID: "call_1", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather", Arguments: "{}"}},
+		{ID: "call_2", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_time", Arguments: "{}"}},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model: GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: "Be brief."},
+			{Role: ChatMessageRoleUser, Content: "What's the weather and time?"},
+			{Role: ChatMessageRoleAssistant, ToolCalls: toolCalls},
+			{Role: ChatMessageRoleTool, ToolCallID: "call_1", Content: "sunny"},
+			{Role: ChatMessageRoleTool, ToolCallID: "call_2", Content: "noon"},
+		},
+	})
+	checks.NoError(t, err, "CreateChatCompletion should succeed after trimming")
+
+	if len(received) != 2 {
+		t.Fatalf("expected one retry, got %d requests", len(received))
+	}
+	retried := received[1]
+	if len(retried) != 4 || retried[0].Role != ChatMessageRoleSystem || len(retried[1].ToolCalls) != 2 ||
+		retried[2].ToolCallID != "call_1" || retried[3].ToolCallID != "call_2" {
+		t.Errorf("the last message must be kept with its assistant message and tool results: %+v", retried)
+	}
+}
@@ -0,0 +1,86 @@
+package openai
+
+import (
+	"context"
+	"errors"
+)
+
+// DefaultMaxContinuations is the number of follow-up requests ContinueCompletion
+// issues at most before returning the stitched result.
+const DefaultMaxContinuations = 3
+
+// ContinuationPrompt is the instruction sent to the model to carry on a
+// truncated answer.
+const ContinuationPrompt = "Continue exactly where you left off. Do not repeat any previous text."
+
+var ErrContinuationNoChoices = errors.New("cannot continue a response without choices")
+
+// ContinueCompletion continues prev, the response to request, for as long as
+// it was cut off by the token limit, using at most DefaultMaxContinuations
+// follow-up requests. See ContinueCompletionN.
+func ContinueCompletion(
+	ctx context.Context,
+	client *Client,
+	request ChatCompletionRequest,
+	prev ChatCompletionResponse,
+) (ChatCompletionResponse, error) {
+	return ContinueCompletionN(ctx, client, request, prev, DefaultMaxContinuations)
+}
+
+// ContinueCompletionN continues the first choice of prev while its finish
+// reason is FinishReasonLength. Every follow-up request carries the partial
+// assistant answer and ContinuationPrompt, and the returned contents are
+// stitched together. It stops once the model finishes for another reason or
+// after maxContinuations follow-up requests.
+//
+// The returned response is the last one received, with the stitched message
+// as its only choice and the usage of all requests summed up.
+func ContinueCompletionN(
+	ctx context.Context,
+	client *Client,
+	request ChatCompletionRequest,
+	prev ChatCompletionResponse,
+	maxContinuations int,
+) (response ChatCompletionResponse, err error) {
+	if len(prev.Choices) == 0 {
+		err = ErrContinuationNoChoices
+		return
+	}
+
+	response = prev
+	choice := prev.Choices[0]
+	content := choice.Message.Content
+	usage := prev.Usage
+
+	request.N = 0
+	baseMessages := request.Messages
+	for i := 0; i < maxContinuations && choice.Truncated(); i++ {
+		request.Messages = make([]ChatCompletionMessage, 0, len(baseMessages)+2)
+		request.Messages = append(request.Messages, baseMessages...)
+		request.Messages = append(request.Messages,
+			ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: content},
+			ChatCompletionMessage{Role: ChatMessageRoleUser, Content: ContinuationPrompt},
+		)
+
+		var next ChatCompletionResponse
+		next, err = client.CreateChatCompletion(ctx, request)
+		if err != nil {
+			return
+		}
+		if len(next.Choices) == 0 {
+			err = ErrContinuationNoChoices
+			return
+		}
+
+		response = next
+		choice = next.Choices[0]
+		content += choice.Message.Content
+		addUsage(&usage, next.Usage)
+	}
+
+	choice.Index = 0
+	choice.Message.Content = content
+	response.Choices = []ChatCompletionChoice{choice}
+	response.Usage = usage
+	return
+}
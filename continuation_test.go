@@ -0,0 +1,111 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestContinueCompletion(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	parts := []string{" world", ", again"}
+	calls := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role != ChatMessageRoleUser || last.Content != ContinuationPrompt {
+			t.Errorf("last message is not the continuation prompt: %+v", last)
+		}
+		partial := req.Messages[len(req.Messages)-2]
+		if partial.Role != ChatMessageRoleAssistant {
+			t.Errorf("partial answer was not sent as assistant message: %+v", partial)
+		}
+
+		finishReason := FinishReasonLength
+		if calls == len(parts)-1 {
+			finishReason = FinishReasonStop
+		}
+		res := ChatCompletionResponse{
+			ID: "next",
+			Choices: []ChatCompletionChoice{{
+				Message:      ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: parts[calls]},
+				FinishReason: finishReason,
+			}},
+			Usage: Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+		}
+		calls++
+		resBytes, _ := json.Marshal(res)
+		_, _ = w.Write(resBytes)
+	})
+
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Say hello"}},
+	}
+	prev := ChatCompletionResponse{
+		Choices: []ChatCompletionChoice{{
+			Message:      ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "Hello"},
+			FinishReason: FinishReasonLength,
+		}},
+		Usage: Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+	}
+
+	resp, err := ContinueCompletion(context.Background(), client, req, prev)
+	checks.NoError(t, err, "ContinueCompletion error")
+	if calls != 2 {
+		t.Errorf("expected 2 continuation requests, got %d", calls)
+	}
+	if got := resp.Choices[0].Message.Content; got != "Hello world, again" {
+		t.Errorf("unexpected stitched content: %q", got)
+	}
+	if resp.Choices[0].FinishReason != FinishReasonStop {
+		t.Errorf("unexpected finish reason: %q", resp.Choices[0].FinishReason)
+	}
+	if resp.Usage.TotalTokens != 6 {
+		t.Errorf("usage was not summed up: %+v", resp.Usage)
+	}
+}
+
+func TestContinueCompletionNStopsAtLimit(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	calls := 0
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		resBytes, _ := json.Marshal(ChatCompletionResponse{Choices: []ChatCompletionChoice{{
+			Message:      ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "."},
+			FinishReason: FinishReasonLength,
+		}}})
+		_, _ = w.Write(resBytes)
+	})
+
+	prev := ChatCompletionResponse{Choices: []ChatCompletionChoice{{FinishReason: FinishReasonLength}}}
+	resp, err := ContinueCompletionN(context.Background(), client, ChatCompletionRequest{Model: GPT3Dot5Turbo}, prev, 2)
+	checks.NoError(t, err, "ContinueCompletionN error")
+	if calls != 2 {
+		t.Errorf("expected 2 continuation requests, got %d", calls)
+	}
+	if !resp.WasTruncated() {
+		t.Error("response should still be truncated after reaching the limit")
+	}
+
+	// A response that is not truncated is returned without further requests.
+	prev.Choices[0].FinishReason = FinishReasonStop
+	_, err = ContinueCompletion(context.Background(), client, ChatCompletionRequest{Model: GPT3Dot5Turbo}, prev)
+	checks.NoError(t, err, "ContinueCompletion error")
+	if calls != 2 {
+		t.Errorf("finished response should not be continued, got %d requests", calls)
+	}
+
+	_, err = ContinueCompletion(context.Background(), client, ChatCompletionRequest{}, ChatCompletionResponse{})
+	checks.ErrorIs(t, err, ErrContinuationNoChoices, "expected ErrContinuationNoChoices")
+}
@@ -0,0 +1,91 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrModelPriceUnknown = errors.New("no price registered for model")
+
+// ModelPrice is the price of a model in US dollars per million tokens.
+type ModelPrice struct {
+	Input  float64
+	Output float64
+	// CachedInput is the price of prompt tokens served from the prompt cache.
+	// Zero means cached tokens are billed at the Input price.
+	CachedInput float64
+	// Reasoning is the price of reasoning tokens. Zero means reasoning tokens
+	// are billed at the Output price.
+	Reasoning float64
+}
+
+const tokensPerPriceUnit = 1_000_000
+
+var (
+	modelPricesMu sync.RWMutex
+
+	modelPrices = map[string]ModelPrice{
+		O1:               {Input: 15, CachedInput: 7.5, Output: 60},
+		O1Mini:           {Input: 3, CachedInput: 1.5, Output: 12},
+		GPT4o:            {Input: 2.5, CachedInput: 1.25, Output: 10},
+		GPT4oMini:        {Input: 0.15, CachedInput: 0.075, Output: 0.6},
+		GPT4Turbo:        {Input: 10, Output: 30},
+		GPT4:             {Input: 30, Output: 60},
+		GPT432K:          {Input: 60, Output: 120},
+		GPT35Turbo:       {Input: 0.5, Output: 1.5},
+		GPT3Dot5Turbo16K: {Input: 3, Output: 4},
+	}
+)
+
+// RegisterModelPrice registers or overrides the price used by EstimateCost
+// for model.
+func RegisterModelPrice(model string, price ModelPrice) {
+	modelPricesMu.Lock()
+	defer modelPricesMu.Unlock()
+
+	modelPrices[model] = price
+}
+
+// GetModelPrice returns the price registered for model.
+func GetModelPrice(model string) (ModelPrice, bool) {
+	modelPricesMu.RLock()
+	defer modelPricesMu.RUnlock()
+
+	price, ok := modelPrices[model]
+	return price, ok
+}
+
+// EstimateCost estimates the cost in US dollars of a request to model that
+// consumed usage. Cached prompt tokens and reasoning tokens are billed at
+// their own rates when the price defines them. The built-in prices are a
+// snapshot and may be outdated; use RegisterModelPrice to override them.
+func EstimateCost(model string, usage Usage) (float64, error) {
+	price, ok := GetModelPrice(model)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrModelPriceUnknown, model)
+	}
+
+	var cachedTokens, reasoningTokens int
+	if usage.PromptTokensDetails != nil {
+		cachedTokens = usage.PromptTokensDetails.CachedTokens
+	}
+	if usage.CompletionTokensDetails != nil {
+		reasoningTokens = usage.CompletionTokensDetails.ReasoningTokens
+	}
+
+	cachedPrice := price.CachedInput
+	if cachedPrice == 0 {
+		cachedPrice = price.Input
+	}
+	reasoningPrice := price.Reasoning
+	if reasoningPrice == 0 {
+		reasoningPrice = price.Output
+	}
+
+	cost := float64(usage.PromptTokens-cachedTokens)*price.Input +
+		float64(cachedTokens)*cachedPrice +
+		float64(usage.CompletionTokens-reasoningTokens)*price.Output +
+		float64(reasoningTokens)*reasoningPrice
+	return cost / tokensPerPriceUnit, nil
+}
@@ -0,0 +1,34 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"math"
+	"testing"
+)
+
+func TestEstimateCost(t *testing.T) {
+	RegisterModelPrice("test-priced-model", ModelPrice{Input: 2, CachedInput: 1, Output: 8, Reasoning: 16})
+
+	cost, err := EstimateCost("test-priced-model", Usage{
+		PromptTokens:            1_000_000,
+		CompletionTokens:        1_000_000,
+		PromptTokensDetails:     &PromptTokensDetails{CachedTokens: 500_000},
+		CompletionTokensDetails: &CompletionTokensDetails{ReasoningTokens: 250_000},
+	})
+	checks.NoError(t, err, "EstimateCost error")
+	// 0.5M*2 + 0.5M*1 + 0.75M*8 + 0.25M*16
+	if expected := 11.5; math.Abs(cost-expected) > 1e-9 {
+		t.Errorf("expected cost %f, got %f", expected, cost)
+	}
+
+	cost, err = EstimateCost(GPT4o, Usage{PromptTokens: 1000, CompletionTokens: 1000})
+	checks.NoError(t, err, "EstimateCost error")
+	if expected := 0.0125; math.Abs(cost-expected) > 1e-9 {
+		t.Errorf("expected cost %f, got %f", expected, cost)
+	}
+
+	_, err = EstimateCost("unknown-model", Usage{})
+	checks.ErrorIs(t, err, ErrModelPriceUnknown, "expected ErrModelPriceUnknown")
+}
@@ -0,0 +1,100 @@
+package openai
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+)
+
+// redactedValue replaces the values of secret headers in a DebugBundle.
+const redactedValue = "REDACTED"
+
+var secretHeaders = []string{"Authorization", "Api-Key", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+
+// DebugBundle captures a failed request and the API's response, everything
+// needed to reproduce the failure in a bug report, see ClientConfig.OnError.
+// Secret headers such as Authorization and api-key are redacted.
+type DebugBundle struct {
+	Method        string      `json:"method"`
+	URL           string      `json:"url"`
+	RequestHeader http.Header `json:"request_header,omitempty"`
+	// RequestBody is the JSON sent, decompressed if it was compressed.
+	RequestBody string `json:"request_body,omitempty"`
+
+	StatusCode     int         `json:"status_code"`
+	ResponseHeader http.Header `json:"response_header,omitempty"`
+	ResponseBody   string      `json:"response_body,omitempty"`
+}
+
+// Interaction returns the failure as an Interaction, to reproduce it with
+// ReplayTransport.
+func (b DebugBundle) Interaction() Interaction {
+	return Interaction{
+		Method:      b.Method,
+		URL:         b.URL,
+		RequestBody: b.RequestBody,
+		StatusCode:  b.StatusCode,
+		Header:      b.ResponseHeader.Clone(),
+		Chunks:      []RecordedChunk{{Data: b.ResponseBody}},
+	}
+}
+
+// reportError passes a DebugBundle of the failed response resp to
+// ClientConfig.OnError, leaving the body of resp readable.
+func (c *Client) reportError(resp *http.Response) {
+	if c.config.OnError == nil {
+		return
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	bundle := DebugBundle{
+		StatusCode:     resp.StatusCode,
+		ResponseHeader: redactHeaders(resp.Header),
+		ResponseBody:   string(body),
+	}
+	if req := resp.Request; req != nil {
+		bundle.Method = req.Method
+		bundle.URL = req.URL.String()
+		bundle.RequestHeader = redactHeaders(req.Header)
+		bundle.RequestBody = requestBody(req)
+	}
+	c.config.OnError(bundle)
+}
+
+func redactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, key := range secretHeaders {
+		if _, ok := redacted[key]; ok {
+			redacted[key] = []string{redactedValue}
+		}
+	}
+	return redacted
+}
+
+// requestBody returns the body of req, or "" if it can't be read again.
+func requestBody(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+
+	var r io.Reader = body
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(body)
+		if err != nil {
+			return ""
+		}
+		r = zr
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
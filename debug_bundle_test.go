@@ -0,0 +1,79 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOnErrorDebugBundle(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	errorBody := `{"error":{"message":"Invalid value for 'temperature'.","type":"invalid_request_error"}}`
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(errorBody))
+	})
+
+	var bundles []DebugBundle
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.CompressRequests = true
+	config.CompressionThreshold = 1
+	config.OnError = func(bundle DebugBundle) {
+		bundles = append(bundles, bundle)
+	}
+	client := NewClientWithConfig(config)
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Message != "Invalid value for 'temperature'." {
+		t.Fatalf("expected the APIError to be decoded after the hook, got %v", err)
+	}
+	_, err = client.CreateChatCompletionStream(context.Background(), request)
+	checks.HasError(t, err, "stream should fail")
+
+	if len(bundles) != 2 {
+		t.Fatalf("expected a bundle per failed request, got %d", len(bundles))
+	}
+	bundle := bundles[0]
+	if bundle.Method != http.MethodPost || !strings.HasSuffix(bundle.URL, "/v1/chat/completions") ||
+		bundle.StatusCode != http.StatusBadRequest || bundle.ResponseBody != errorBody {
+		t.Errorf("unexpected bundle %+v", bundle)
+	}
+	if bundle.RequestHeader.Get("Authorization") != "REDACTED" || bundle.ResponseHeader.Get("Set-Cookie") != "REDACTED" {
+		t.Errorf("expected secrets to be redacted, got %v and %v", bundle.RequestHeader, bundle.ResponseHeader)
+	}
+	var sent ChatCompletionRequest
+	checks.NoError(t, json.Unmarshal([]byte(bundle.RequestBody), &sent), "request body is not the sent JSON")
+	if sent.Model != GPT4o || len(sent.Messages) != 1 {
+		t.Errorf("unexpected request body %s", bundle.RequestBody)
+	}
+	if _, err = json.Marshal(bundle); err != nil {
+		t.Errorf("bundle can't be serialized: %v", err)
+	}
+
+	replayConfig := DefaultConfig(test.GetTestToken())
+	replayConfig.BaseURL = ts.URL + "/v1"
+	replayConfig.HTTPClient = &http.Client{Transport: NewReplayTransport([]Interaction{bundle.Interaction()})}
+	_, err = NewClientWithConfig(replayConfig).CreateChatCompletion(context.Background(), request)
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusBadRequest {
+		t.Errorf("expected the replayed failure, got %v", err)
+	}
+}
@@ -2,9 +2,18 @@ package openai
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 )
 
+// ErrRequestCanceled is matched by errors returned when the context of a
+// request is canceled or its deadline is exceeded before the response was
+// read. These errors also match the context error, i.e. context.Canceled or
+// context.DeadlineExceeded. The response body is always closed, releasing the
+// connection.
+var ErrRequestCanceled = errors.New("request canceled")
+
 // APIError provides error information returned by the OpenAI API.
 type APIError struct {
 	Code           any     `json:"code,omitempty"`
@@ -84,3 +93,34 @@ func (e *RequestError) Error() string {
 func (e *RequestError) Unwrap() error {
 	return e.Err
 }
+
+type requestCanceledError struct {
+	ctxErr error
+	err    error
+}
+
+func (e *requestCanceledError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrRequestCanceled, e.err)
+}
+
+// Unwrap returns the context error so that errors.Is matches it.
+func (e *requestCanceledError) Unwrap() error {
+	return e.ctxErr
+}
+
+func (e *requestCanceledError) Is(target error) bool {
+	return target == ErrRequestCanceled
+}
+
+// canceledOr returns err, or an error matching ErrRequestCanceled if err was
+// caused by the cancellation of the request's context.
+func canceledOr(req *http.Request, err error) error {
+	if err == nil {
+		return nil
+	}
+	ctxErr := req.Context().Err()
+	if ctxErr == nil {
+		return err
+	}
+	return &requestCanceledError{ctxErr: ctxErr, err: err}
+}
@@ -37,6 +37,31 @@ func Example() {
 	fmt.Println(resp.Choices[0].Message.Content)
 }
 
+func ExampleAddExamples() {
+	req := openai.ChatCompletionRequest{
+		Model: openai.GPT3Dot5Turbo,
+		Messages: []openai.ChatCompletionMessage{
+			{Role: openai.ChatMessageRoleSystem, Content: "Translate English to French."},
+			{Role: openai.ChatMessageRoleUser, Content: "Good morning"},
+		},
+	}
+	openai.AddExamples(&req, []openai.FewShotExample{
+		{Input: "Hello", Output: "Bonjour"},
+		{Input: "Thank you", Output: "Merci"},
+	})
+
+	for _, message := range req.Messages {
+		fmt.Printf("%s: %s\n", message.Role, message.Content)
+	}
+	// Output:
+	// system: Translate English to French.
+	// user: Hello
+	// assistant: Bonjour
+	// user: Thank you
+	// assistant: Merci
+	// user: Good morning
+}
+
 func ExampleClient_CreateChatCompletionStream() {
 	client := openai.NewClient(os.Getenv("OPENAI_API_KEY"))
 
@@ -0,0 +1,70 @@
+package openai
+
+// FewShotExample is a few-shot example of the expected answer to an input.
+type FewShotExample struct {
+	Input  string
+	Output string
+}
+
+// AddExamples inserts a user message with the Input and an assistant message
+// with the Output of each example into the messages of req, after the leading
+// system messages and before the rest of the conversation, preserving their
+// order. Examples that directly follow the system messages already, e.g.
+// because AddExamples was called before, are not added again, and the other
+// examples are inserted after them.
+func AddExamples(req *ChatCompletionRequest, examples []FewShotExample) {
+	pos := 0
+	for pos < len(req.Messages) && req.Messages[pos].Role == ChatMessageRoleSystem {
+		pos++
+	}
+
+	present := make(map[FewShotExample]bool)
+	for pos+1 < len(req.Messages) {
+		example, ok := exampleAt(req.Messages, pos)
+		if !ok || !containsExample(examples, example) {
+			break
+		}
+		present[example] = true
+		pos += 2
+	}
+
+	var inserted []ChatCompletionMessage
+	for _, example := range examples {
+		if present[example] {
+			continue
+		}
+		present[example] = true
+		inserted = append(inserted,
+			ChatCompletionMessage{Role: ChatMessageRoleUser, Content: example.Input},
+			ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: example.Output},
+		)
+	}
+	if len(inserted) == 0 {
+		return
+	}
+
+	messages := make([]ChatCompletionMessage, 0, len(req.Messages)+len(inserted))
+	messages = append(messages, req.Messages[:pos]...)
+	messages = append(messages, inserted...)
+	req.Messages = append(messages, req.Messages[pos:]...)
+}
+
+// exampleAt returns the example formed by the user and assistant messages at
+// messages[i] and messages[i+1].
+func exampleAt(messages []ChatCompletionMessage, i int) (FewShotExample, bool) {
+	input, output := messages[i], messages[i+1]
+	if input.Role != ChatMessageRoleUser || output.Role != ChatMessageRoleAssistant ||
+		len(input.MultiContent) > 0 || len(output.MultiContent) > 0 {
+		return FewShotExample{}, false
+	}
+	return FewShotExample{Input: input.Content, Output: output.Content}, true
+}
+
+func containsExample(examples []FewShotExample, example FewShotExample) bool {
+	for _, e := range examples {
+		if e == example {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"reflect"
+	"testing"
+)
+
+func TestAddExamples(t *testing.T) {
+	req := ChatCompletionRequest{Messages: []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "Translate to French."},
+		{Role: ChatMessageRoleUser, Content: "Good night"},
+	}}
+	examples := []FewShotExample{
+		{Input: "Hello", Output: "Bonjour"},
+		{Input: "Thank you", Output: "Merci"},
+	}
+
+	AddExamples(&req, examples)
+	expected := []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "Translate to French."},
+		{Role: ChatMessageRoleUser, Content: "Hello"},
+		{Role: ChatMessageRoleAssistant, Content: "Bonjour"},
+		{Role: ChatMessageRoleUser, Content: "Thank you"},
+		{Role: ChatMessageRoleAssistant, Content: "Merci"},
+		{Role: ChatMessageRoleUser, Content: "Good night"},
+	}
+	if !reflect.DeepEqual(req.Messages, expected) {
+		t.Fatalf("unexpected messages: %+v", req.Messages)
+	}
+
+	AddExamples(&req, examples)
+	if !reflect.DeepEqual(req.Messages, expected) {
+		t.Fatalf("examples were added twice: %+v", req.Messages)
+	}
+
+	AddExamples(&req, []FewShotExample{examples[0], {Input: "Yes", Output: "Oui"}})
+	expected = append(expected[:3:3],
+		ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Yes"},
+		ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "Oui"},
+		ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Thank you"},
+		ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "Merci"},
+		ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Good night"},
+	)
+	if !reflect.DeepEqual(req.Messages, expected) {
+		t.Fatalf("unexpected messages after adding another example: %+v", req.Messages)
+	}
+}
+
+func TestAddExamplesWithoutSystemMessage(t *testing.T) {
+	req := ChatCompletionRequest{Messages: []ChatCompletionMessage{
+		{Role: ChatMessageRoleUser, Content: "Good night"},
+	}}
+	AddExamples(&req, []FewShotExample{{Input: "Hello", Output: "Bonjour"}})
+	if len(req.Messages) != 3 || req.Messages[0].Content != "Hello" || req.Messages[2].Content != "Good night" {
+		t.Fatalf("unexpected messages: %+v", req.Messages)
+	}
+}
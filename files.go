@@ -112,7 +112,10 @@ func (c *Client) GetFileContent(ctx context.Context, fileID string) (content io.
 		return
 	}
 
-	c.setCommonHeaders(req)
+	err = c.setCommonHeaders(req)
+	if err != nil {
+		return
+	}
 
 	res, err := c.config.HTTPClient.Do(req)
 	if err != nil {
@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// fineTuningMessage holds the fields of a message that fine-tuning files
+// accept.
+type fineTuningMessage struct {
+	Role         string        `json:"role"`
+	Content      any           `json:"content,omitempty"`
+	Name         string        `json:"name,omitempty"`
+	FunctionCall *FunctionCall `json:"function_call,omitempty"`
+	ToolCalls    []ToolCall    `json:"tool_calls,omitempty"`
+	ToolCallID   string        `json:"tool_call_id,omitempty"`
+}
+
+// ExportForFineTuning writes each conversation as a line of JSONL in the
+// {"messages": [...]} format of chat fine-tuning files. Fields that
+// fine-tuning doesn't accept are stripped: refusals, reasoning content,
+// annotations and cache control, as well as function and tool calls of
+// messages other than assistant messages and tool call IDs of messages other
+// than tool messages.
+func ExportForFineTuning(conversations [][]ChatCompletionMessage, w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	for _, conversation := range conversations {
+		line := struct {
+			Messages []fineTuningMessage `json:"messages"`
+		}{Messages: make([]fineTuningMessage, len(conversation))}
+		for i, msg := range conversation {
+			line.Messages[i] = newFineTuningMessage(msg)
+		}
+		// Encode terminates every line with a newline.
+		if err := encoder.Encode(line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newFineTuningMessage(msg ChatCompletionMessage) fineTuningMessage {
+	m := fineTuningMessage{Role: msg.Role, Name: msg.Name}
+	if msg.Role == ChatMessageRoleAssistant {
+		if msg.FunctionCall != zeroFunctionCall {
+			m.FunctionCall = &msg.FunctionCall
+		}
+		m.ToolCalls = msg.ToolCalls
+	}
+	if msg.Role == ChatMessageRoleTool {
+		m.ToolCallID = msg.ToolCallID
+	}
+
+	switch {
+	case msg.MultiContent != nil:
+		parts := make([]ChatMessagePart, len(msg.MultiContent))
+		for i, part := range msg.MultiContent {
+			part.CacheControl = nil
+			parts[i] = part
+		}
+		m.Content = parts
+	case msg.Content != "" || (m.FunctionCall == nil && len(m.ToolCalls) == 0):
+		// Only assistant messages that call functions or tools may omit
+		// their content.
+		m.Content = msg.Content
+	}
+	return m
+}
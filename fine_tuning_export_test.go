@@ -0,0 +1,41 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"bytes"
+	"testing"
+)
+
+func TestExportForFineTuning(t *testing.T) {
+	conversations := [][]ChatCompletionMessage{
+		{
+			{Role: ChatMessageRoleSystem, Content: "Be brief.", CacheControl: &CacheControl{Type: CacheControlTypeEphemeral}},
+			{Role: ChatMessageRoleUser, Content: "Weather in Paris?", FunctionCall: FunctionCall{Name: "ignored"}},
+			{Role: ChatMessageRoleAssistant, ReasoningContent: "Look it up.", ToolCalls: []ToolCall{
+				{ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+			}},
+			{Role: ChatMessageRoleTool, ToolCallID: "call_a", Content: "21"},
+			{Role: ChatMessageRoleAssistant, Content: "21 degrees.", Refusal: "ignored"},
+		},
+		{
+			{Role: ChatMessageRoleUser, Content: "Hi", ToolCallID: "ignored"},
+			{Role: ChatMessageRoleAssistant, Content: ""},
+		},
+	}
+
+	var buf bytes.Buffer
+	checks.NoError(t, ExportForFineTuning(conversations, &buf), "ExportForFineTuning error")
+
+	expected := `{"messages":[{"role":"system","content":"Be brief."},` +
+		`{"role":"user","content":"Weather in Paris?"},` +
+		`{"role":"assistant","tool_calls":[{"id":"call_a","type":"function",` +
+		`"function":{"name":"get_weather","arguments":"{\"city\":\"Paris\"}"}}]},` +
+		`{"role":"tool","content":"21","tool_call_id":"call_a"},` +
+		`{"role":"assistant","content":"21 degrees."}]}` + "\n" +
+		`{"messages":[{"role":"user","content":"Hi"},{"role":"assistant","content":""}]}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected export:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
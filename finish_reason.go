@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"strings"
+	"sync"
+)
+
+var (
+	finishReasonsMu sync.RWMutex
+
+	// finishReasons maps lowercase provider-specific finish reasons to the
+	// canonical ones.
+	finishReasons = map[string]FinishReason{
+		"stop":          FinishReasonStop,
+		"eos":           FinishReasonStop,
+		"eos_token":     FinishReasonStop,
+		"end_turn":      FinishReasonStop,
+		"stop_sequence": FinishReasonStop,
+		"complete":      FinishReasonStop,
+
+		"length":       FinishReasonLength,
+		"max_tokens":   FinishReasonLength,
+		"model_length": FinishReasonLength,
+
+		"tool_calls": FinishReasonToolCalls,
+		"tool_use":   FinishReasonToolCalls,
+
+		"function_call": FinishReasonFunctionCall,
+
+		"content_filter":     FinishReasonContentFilter,
+		"safety":             FinishReasonContentFilter,
+		"recitation":         FinishReasonContentFilter,
+		"blocklist":          FinishReasonContentFilter,
+		"prohibited_content": FinishReasonContentFilter,
+
+		"null": FinishReasonNull,
+	}
+)
+
+// NormalizeFinishReason maps a finish reason of any provider, e.g. "eos",
+// "max_tokens", "tool_use" or Gemini's "MAX_TOKENS", to the canonical
+// FinishReason constants, ignoring case. An empty reason, which means that a
+// streamed choice has not finished yet, is returned as is, and unknown reasons
+// map to FinishReasonUnknown. Use RegisterFinishReason for other providers.
+func NormalizeFinishReason(raw string) FinishReason {
+	key := strings.ToLower(strings.TrimSpace(raw))
+	if key == "" {
+		return ""
+	}
+
+	finishReasonsMu.RLock()
+	defer finishReasonsMu.RUnlock()
+
+	if reason, ok := finishReasons[key]; ok {
+		return reason
+	}
+	return FinishReasonUnknown
+}
+
+// RegisterFinishReason makes NormalizeFinishReason map the provider-specific
+// finish reason raw, ignoring case, to reason.
+func RegisterFinishReason(raw string, reason FinishReason) {
+	finishReasonsMu.Lock()
+	defer finishReasonsMu.Unlock()
+
+	finishReasons[strings.ToLower(strings.TrimSpace(raw))] = reason
+}
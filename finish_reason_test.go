@@ -0,0 +1,39 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"testing"
+)
+
+func TestNormalizeFinishReason(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected FinishReason
+	}{
+		{"stop", FinishReasonStop},
+		{"eos", FinishReasonStop},
+		{"end_turn", FinishReasonStop},
+		{"STOP", FinishReasonStop},
+		{"length", FinishReasonLength},
+		{"max_tokens", FinishReasonLength},
+		{"MAX_TOKENS", FinishReasonLength},
+		{"tool_use", FinishReasonToolCalls},
+		{"tool_calls", FinishReasonToolCalls},
+		{"function_call", FinishReasonFunctionCall},
+		{"SAFETY", FinishReasonContentFilter},
+		{"content_filter", FinishReasonContentFilter},
+		{"", ""},
+		{"something_else", FinishReasonUnknown},
+	}
+	for _, tt := range tests {
+		if got := NormalizeFinishReason(tt.raw); got != tt.expected {
+			t.Errorf("NormalizeFinishReason(%q) = %q, expected %q", tt.raw, got, tt.expected)
+		}
+	}
+
+	RegisterFinishReason("Finished_Normally", FinishReasonStop)
+	if got := NormalizeFinishReason("finished_normally"); got != FinishReasonStop {
+		t.Errorf("registered finish reason was not normalized, got %q", got)
+	}
+}
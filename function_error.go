@@ -0,0 +1,90 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// DefaultErrorField is the argument AsError looks for unless configured
+// otherwise.
+const DefaultErrorField = "error"
+
+// FunctionCallError is returned by AsError when the model called a function
+// to signal an error.
+type FunctionCallError struct {
+	// Function is the name of the called function.
+	Function string
+	// Message is the error reported by the model.
+	Message string
+}
+
+func (e *FunctionCallError) Error() string {
+	return fmt.Sprintf("function %s reported an error: %s", e.Function, e.Message)
+}
+
+// AsErrorOption configures AsError.
+type AsErrorOption func(*asErrorOptions)
+
+type asErrorOptions struct {
+	field string
+}
+
+// WithErrorField makes AsError look for the error in the argument named
+// field instead of DefaultErrorField.
+func WithErrorField(field string) AsErrorOption {
+	return func(o *asErrorOptions) {
+		o.field = field
+	}
+}
+
+// AsError returns a *FunctionCallError if the arguments of the call are a
+// JSON object with a non-empty "error" argument, the convention for functions
+// the model calls to signal a failure, and nil otherwise. Use WithErrorField
+// for another convention. The error may be a string or an object with a
+// "message" string; other values are reported as their JSON encoding, except
+// that null and false report no error.
+func (f FunctionCall) AsError(opts ...AsErrorOption) error {
+	options := &asErrorOptions{field: DefaultErrorField}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var arguments map[string]json.RawMessage
+	if err := f.Arguments.Decode(&arguments); err != nil {
+		return nil
+	}
+	raw, ok := arguments[options.field]
+	if !ok {
+		return nil
+	}
+
+	message := errorMessage(raw)
+	if message == "" {
+		return nil
+	}
+	return &FunctionCallError{Function: f.Name, Message: message}
+}
+
+// errorMessage returns the message of an error argument, or an empty string
+// if it reports no error.
+func errorMessage(raw json.RawMessage) string {
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case bool:
+		if !v {
+			return ""
+		}
+	case string:
+		return v
+	case map[string]any:
+		if message, ok := v["message"].(string); ok {
+			return message
+		}
+	}
+	return string(raw)
+}
@@ -0,0 +1,48 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"errors"
+	"testing"
+)
+
+func TestFunctionCallAsError(t *testing.T) {
+	tests := []struct {
+		name      string
+		arguments Arguments
+		opts      []AsErrorOption
+		message   string
+	}{
+		{"string", `{"error":"city not found"}`, nil, "city not found"},
+		{"object", `{"error":{"message":"quota exceeded","code":429}}`, nil, "quota exceeded"},
+		{"object without message", `{"error":{"code":429}}`, nil, `{"code":429}`},
+		{"true", `{"error":true}`, nil, "true"},
+		{"no error", `{"city":"Paris"}`, nil, ""},
+		{"empty error", `{"error":""}`, nil, ""},
+		{"null error", `{"error":null}`, nil, ""},
+		{"false", `{"error":false}`, nil, ""},
+		{"invalid JSON", `{"error":`, nil, ""},
+		{"not an object", `["error"]`, nil, ""},
+		{"custom field", `{"failure":"timed out","error":"ignored"}`, []AsErrorOption{WithErrorField("failure")}, "timed out"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := FunctionCall{Name: "report", Arguments: tt.arguments}.AsError(tt.opts...)
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+			var callErr *FunctionCallError
+			if !errors.As(err, &callErr) {
+				t.Fatalf("expected a FunctionCallError, got %v", err)
+			}
+			if callErr.Function != "report" || callErr.Message != tt.message {
+				t.Errorf("unexpected error %+v", callErr)
+			}
+		})
+	}
+}
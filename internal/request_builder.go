@@ -31,6 +31,9 @@ func (b *HTTPRequestBuilder) Build(ctx context.Context, method, url string, requ
 		return nil, err
 	}
 
+	// With a *bytes.Buffer body, the request's GetBody returns a fresh
+	// reader of the encoded body, so that transports can replay it when
+	// they retry or follow redirects.
 	return http.NewRequestWithContext(
 		ctx,
 		method,
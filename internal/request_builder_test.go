@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"reflect"
 	"testing"
@@ -59,3 +60,24 @@ func TestRequestBuilderReturnsRequestWhenRequestOfArgsIsNil(t *testing.T) {
 		t.Errorf("Build() got = %v, want %v", got, want)
 	}
 }
+
+func TestRequestBuilderBodyIsReplayable(t *testing.T) {
+	b := NewRequestBuilder()
+	req, err := b.Build(context.Background(), http.MethodPost, "/foo", map[string]string{"foo": "bar"})
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+	if req.GetBody == nil {
+		t.Fatal("GetBody is not set")
+	}
+
+	first, _ := io.ReadAll(req.Body)
+	body, err := req.GetBody()
+	if err != nil {
+		t.Fatalf("GetBody() error = %v", err)
+	}
+	second, _ := io.ReadAll(body)
+	if len(first) == 0 || !bytes.Equal(first, second) {
+		t.Errorf("GetBody() = %q, want %q", second, first)
+	}
+}
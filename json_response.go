@@ -0,0 +1,115 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// JSONRetryPrompt is the instruction sent to the model after it answered
+// with something other than JSON, see WithJSONRetries.
+const JSONRetryPrompt = "Your previous answer was not valid JSON. " +
+	"Reply again with only the JSON value, without any other text."
+
+var ErrResponseNotJSON = errors.New("response content is not valid JSON")
+
+// ResponseNotJSONError is returned when the content of a response that should
+// be JSON does not parse as JSON, e.g. because the model ignored JSON mode and
+// answered with prose. It matches ErrResponseNotJSON with errors.Is.
+type ResponseNotJSONError struct {
+	// Content is the raw content of the response.
+	Content string
+}
+
+func (e *ResponseNotJSONError) Error() string {
+	const maxQuoted = 80
+	content := []rune(e.Content)
+	if len(content) > maxQuoted {
+		return fmt.Sprintf("%s: %q...", ErrResponseNotJSON, string(content[:maxQuoted]))
+	}
+	return fmt.Sprintf("%s: %q", ErrResponseNotJSON, e.Content)
+}
+
+func (e *ResponseNotJSONError) Is(target error) bool {
+	return target == ErrResponseNotJSON
+}
+
+// DecodeJSON decodes the content of the first choice into v. If the content
+// is not JSON, it returns a *ResponseNotJSONError holding the raw content
+// instead of leaving v partially filled.
+func (r ChatCompletionResponse) DecodeJSON(v any) error {
+	if len(r.Choices) == 0 {
+		return ErrNoChoices
+	}
+	content := r.Choices[0].Message.TextContent()
+	if !json.Valid([]byte(content)) {
+		return &ResponseNotJSONError{Content: content}
+	}
+	return json.Unmarshal([]byte(content), v)
+}
+
+// JSONOption configures CreateChatCompletionJSON.
+type JSONOption func(*jsonOptions)
+
+type jsonOptions struct {
+	retries     int
+	chatOptions []ChatCompletionOption
+}
+
+// WithJSONRetries asks the model up to n more times for JSON when it answers
+// with something else, sending its answer back followed by JSONRetryPrompt.
+func WithJSONRetries(n int) JSONOption {
+	return func(o *jsonOptions) {
+		o.retries = n
+	}
+}
+
+// WithJSONChatOptions passes opts to every chat completion request of
+// CreateChatCompletionJSON.
+func WithJSONChatOptions(opts ...ChatCompletionOption) JSONOption {
+	return func(o *jsonOptions) {
+		o.chatOptions = append(o.chatOptions, opts...)
+	}
+}
+
+// CreateChatCompletionJSON sends request, which should ask for JSON, e.g.
+// with ResponseFormat, and decodes the content of the first choice of the
+// response into v, see DecodeJSON. If the content is not JSON, it fails with a
+// *ResponseNotJSONError unless WithJSONRetries allows asking again. The
+// returned response is the last one received, with the usage of all requests
+// summed up.
+func (c *Client) CreateChatCompletionJSON(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	v any,
+	opts ...JSONOption,
+) (response ChatCompletionResponse, err error) {
+	options := &jsonOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var usage Usage
+	messages := append([]ChatCompletionMessage(nil), request.Messages...)
+	for attempt := 0; ; attempt++ {
+		request.Messages = messages
+		response, err = c.CreateChatCompletion(ctx, request, options.chatOptions...)
+		if err != nil {
+			return
+		}
+		addUsage(&usage, response.Usage)
+		response.Usage = usage
+
+		err = response.DecodeJSON(v)
+		var notJSON *ResponseNotJSONError
+		if attempt >= options.retries || !errors.As(err, &notJSON) {
+			return
+		}
+		messages = append(messages,
+			ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: strings.TrimSpace(notJSON.Content)},
+			ChatCompletionMessage{Role: ChatMessageRoleUser, Content: JSONRetryPrompt},
+		)
+	}
+}
@@ -0,0 +1,84 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestChatCompletionResponseDecodeJSON(t *testing.T) {
+	var v struct {
+		City string `json:"city"`
+	}
+	response := ChatCompletionResponse{Choices: []ChatCompletionChoice{{
+		Message: ChatCompletionMessage{Content: ` {"city": "Paris"} `},
+	}}}
+	checks.NoError(t, response.DecodeJSON(&v), "DecodeJSON error")
+	if v.City != "Paris" {
+		t.Errorf("unexpected decoded value %+v", v)
+	}
+
+	prose := "Sure! The city is Paris."
+	response.Choices[0].Message.Content = prose
+	err := response.DecodeJSON(&v)
+	checks.ErrorIs(t, err, ErrResponseNotJSON, "prose should be rejected")
+	var notJSON *ResponseNotJSONError
+	if !errors.As(err, &notJSON) || notJSON.Content != prose {
+		t.Errorf("expected the raw content in the error, got %v", err)
+	}
+
+	err = ChatCompletionResponse{}.DecodeJSON(&v)
+	checks.ErrorIs(t, err, ErrNoChoices, "response without choices should be rejected")
+}
+
+func TestCreateChatCompletionJSON(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	answers := []string{"Here is the weather: sunny.", `{"weather": "sunny"}`}
+	var received []ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		res := ChatCompletionResponse{
+			Choices: []ChatCompletionChoice{{
+				Message: ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: answers[len(received)]},
+			}},
+			Usage: Usage{PromptTokens: 1, CompletionTokens: 1, TotalTokens: 2},
+		}
+		received = append(received, req)
+		resBytes, _ := json.Marshal(res)
+		_, _ = w.Write(resBytes)
+	})
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Weather as JSON"}},
+	}
+	var v struct {
+		Weather string `json:"weather"`
+	}
+
+	_, err := client.CreateChatCompletionJSON(context.Background(), request, &v)
+	checks.ErrorIs(t, err, ErrResponseNotJSON, "prose should fail without retries")
+	if len(received) != 1 || v.Weather != "" {
+		t.Fatalf("expected a single request and no decoded value, got %d requests and %+v", len(received), v)
+	}
+
+	received = nil
+	response, err := client.CreateChatCompletionJSON(context.Background(), request, &v, WithJSONRetries(2))
+	checks.NoError(t, err, "CreateChatCompletionJSON error")
+	if v.Weather != "sunny" || len(received) != 2 || response.Usage.TotalTokens != 4 {
+		t.Errorf("expected sunny after one retry with summed usage, got %+v after %d requests, usage %+v",
+			v, len(received), response.Usage)
+	}
+	retry := received[1].Messages
+	if len(retry) != 3 || retry[1].Content != answers[0] || retry[2].Content != JSONRetryPrompt {
+		t.Errorf("unexpected retry conversation %+v", retry)
+	}
+}
@@ -0,0 +1,131 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrTokenizerCannotEncode = errors.New("tokenizer of the model can't encode token IDs")
+
+const (
+	// MaxLogitBiasEntries is the number of logit_bias entries the API accepts.
+	MaxLogitBiasEntries = 300
+
+	minLogitBias = -100
+	maxLogitBias = 100
+)
+
+// CapLogitBias returns a copy of biases with every bias clamped to the valid
+// range of -100 to 100 and, if there are more than limit entries, only the
+// limit entries with the largest magnitude. A negative limit is treated as
+// zero. Ties are broken by token to keep the result deterministic.
+func CapLogitBias(biases map[string]int, limit int) map[string]int {
+	if limit < 0 {
+		limit = 0
+	}
+	type entry struct {
+		token string
+		bias  int
+	}
+	entries := make([]entry, 0, len(biases))
+	for token, bias := range biases {
+		entries = append(entries, entry{token: token, bias: clampLogitBias(bias)})
+	}
+	if len(entries) > limit {
+		sort.Slice(entries, func(i, j int) bool {
+			a, b := absInt(entries[i].bias), absInt(entries[j].bias)
+			if a != b {
+				return a > b
+			}
+			return entries[i].token < entries[j].token
+		})
+		entries = entries[:limit]
+	}
+
+	capped := make(map[string]int, len(entries))
+	for _, e := range entries {
+		capped[e.token] = e.bias
+	}
+	return capped
+}
+
+// BiasWords returns a logit_bias map that biases each word of words, in the
+// spelling given, with a leading space and capitalized, by assigning its bias
+// to every token ID of these variants. A word that spans several tokens thus
+// biases each of its tokens, including ones it shares with other words; a
+// token shared by words with different biases gets the bias of the largest
+// magnitude. The result can be merged into ChatCompletionRequest.LogitBias.
+//
+// The tokenizer registered for model must be a TokenEncoder, otherwise
+// ErrTokenizerCannotEncode is returned.
+func BiasWords(model string, words map[string]int) (map[string]int, error) {
+	encoder, ok := tokenizerForModel(model).(TokenEncoder)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTokenizerCannotEncode, model)
+	}
+
+	biases := make(map[string]int)
+	for word, bias := range words {
+		for _, variant := range wordVariants(word) {
+			for _, token := range encoder.Encode(variant) {
+				key := strconv.Itoa(token)
+				if current, ok := biases[key]; !ok || absInt(bias) > absInt(current) ||
+					(absInt(bias) == absInt(current) && bias > current) {
+					biases[key] = bias
+				}
+			}
+		}
+	}
+	return biases, nil
+}
+
+// wordVariants returns the spellings of word that tokenize differently:
+// as given, with a leading space and capitalized.
+func wordVariants(word string) []string {
+	word = strings.TrimSpace(word)
+	if word == "" {
+		return nil
+	}
+	variants := []string{word, " " + word}
+	first, size := utf8.DecodeRuneInString(word)
+	if capitalized := string(unicode.ToUpper(first)) + word[size:]; capitalized != word {
+		variants = append(variants, capitalized, " "+capitalized)
+	}
+	return variants
+}
+
+func clampLogitBias(bias int) int {
+	if bias < minLogitBias {
+		return minLogitBias
+	}
+	if bias > maxLogitBias {
+		return maxLogitBias
+	}
+	return bias
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func validateLogitBias(biases map[string]int) error {
+	if len(biases) > MaxLogitBiasEntries {
+		return fmt.Errorf("%w: at most %d logit_bias entries are allowed, got %d",
+			ErrInvalidChatCompletionRequest, MaxLogitBiasEntries, len(biases))
+	}
+	for token, bias := range biases {
+		if bias != clampLogitBias(bias) {
+			return fmt.Errorf("%w: logit_bias for token %s must be between %d and %d, got %d",
+				ErrInvalidChatCompletionRequest, token, minLogitBias, maxLogitBias, bias)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"strconv"
+	"testing"
+)
+
+func TestCapLogitBias(t *testing.T) {
+	biases := map[string]int{"1": 5, "2": -150, "3": 50, "4": -20}
+	capped := CapLogitBias(biases, 2)
+	if len(capped) != 2 || capped["2"] != -100 || capped["3"] != 50 {
+		t.Errorf("unexpected capped biases: %v", capped)
+	}
+	if biases["2"] != -150 {
+		t.Error("CapLogitBias modified its input")
+	}
+
+	capped = CapLogitBias(biases, MaxLogitBiasEntries)
+	if len(capped) != 4 || capped["2"] != -100 || capped["1"] != 5 {
+		t.Errorf("unexpected clamped biases: %v", capped)
+	}
+
+	if capped = CapLogitBias(biases, -1); len(capped) != 0 {
+		t.Errorf("a negative limit should keep no biases, got %v", capped)
+	}
+}
+
+func TestChatCompletionRequestValidateLogitBias(t *testing.T) {
+	biases := make(map[string]int, MaxLogitBiasEntries+1)
+	for i := 0; i <= MaxLogitBiasEntries; i++ {
+		biases[strconv.Itoa(i)] = 1
+	}
+	err := ChatCompletionRequest{LogitBias: biases}.Validate()
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "too many logit_bias entries should be rejected")
+
+	err = ChatCompletionRequest{LogitBias: CapLogitBias(biases, MaxLogitBiasEntries)}.Validate()
+	checks.NoError(t, err, "capped logit_bias should be valid")
+
+	err = ChatCompletionRequest{LogitBias: map[string]int{"1": 101}}.Validate()
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "out of range logit_bias should be rejected")
+}
+
+func TestBiasWords(t *testing.T) {
+	const model = "bias-words-test-model"
+	encoder := newWordEncoder()
+	RegisterTokenizer(model, encoder)
+
+	biases, err := BiasWords(model, map[string]int{"paris": -100, "ice cream": 20})
+	checks.NoError(t, err, "BiasWords error")
+
+	expected := map[string]int{}
+	for _, variant := range []string{"paris", " paris", "Paris", " Paris"} {
+		expected[strconv.Itoa(encoder.Encode(variant)[0])] = -100
+	}
+	// Both words of the multi-token variants get the bias.
+	for _, variant := range []string{"ice cream", " ice cream", "Ice cream", " Ice cream"} {
+		for _, token := range encoder.Encode(variant) {
+			expected[strconv.Itoa(token)] = 20
+		}
+	}
+	if len(biases) != len(expected) {
+		t.Fatalf("expected %d biased tokens, got %v", len(expected), biases)
+	}
+	for token, bias := range expected {
+		if biases[token] != bias {
+			t.Errorf("token %s: expected bias %d, got %d", token, bias, biases[token])
+		}
+	}
+
+	_, err = BiasWords("unregistered-model", map[string]int{"paris": -100})
+	checks.ErrorIs(t, err, ErrTokenizerCannotEncode, "approximate tokenizer accepted")
+}
@@ -0,0 +1,90 @@
+package openai
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// LogProbs holds the log probabilities of the tokens of a chat completion
+// choice.
+type LogProbs struct {
+	Content []LogProb `json:"content"`
+}
+
+// LogProb is the log probability of a single token.
+type LogProb struct {
+	Token   string  `json:"token"`
+	LogProb float64 `json:"logprob"`
+	// Bytes is the UTF-8 representation of the token, which is useful when a
+	// character is split across several tokens.
+	Bytes []int `json:"bytes,omitempty"`
+	// TopLogProbs are the most likely tokens at this position, most likely
+	// first, as requested with TopLogProbs on the request.
+	TopLogProbs []TopLogProbs `json:"top_logprobs"`
+}
+
+// TopLogProbs is one of the most likely tokens at a position.
+type TopLogProbs struct {
+	Token   string  `json:"token"`
+	LogProb float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes,omitempty"`
+}
+
+// legacyLogProbs is the shape of logprobs used by the completions API, which
+// some OpenAI-compatible providers also return for chat completions.
+type legacyLogProbs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []float64            `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+}
+
+// UnmarshalJSON decodes both the chat logprobs shape and the legacy
+// {tokens, token_logprobs, top_logprobs} shape of the completions API, which
+// is detected automatically.
+func (l *LogProbs) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	_, hasTokens := fields["tokens"]
+	_, hasTokenLogprobs := fields["token_logprobs"]
+	if !hasTokens && !hasTokenLogprobs {
+		type Alias LogProbs
+		return json.Unmarshal(data, (*Alias)(l))
+	}
+
+	var legacy legacyLogProbs
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+	*l = legacy.logProbs()
+	return nil
+}
+
+func (l legacyLogProbs) logProbs() LogProbs {
+	content := make([]LogProb, len(l.Tokens))
+	for i, token := range l.Tokens {
+		content[i].Token = token
+		if i < len(l.TokenLogprobs) {
+			content[i].LogProb = l.TokenLogprobs[i]
+		}
+		if i < len(l.TopLogprobs) {
+			content[i].TopLogProbs = topLogProbsFromMap(l.TopLogprobs[i])
+		}
+	}
+	return LogProbs{Content: content}
+}
+
+func topLogProbsFromMap(m map[string]float64) []TopLogProbs {
+	top := make([]TopLogProbs, 0, len(m))
+	for token, logProb := range m {
+		top = append(top, TopLogProbs{Token: token, LogProb: logProb})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].LogProb != top[j].LogProb {
+			return top[i].LogProb > top[j].LogProb
+		}
+		return top[i].Token < top[j].Token
+	})
+	return top
+}
@@ -0,0 +1,39 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"encoding/json"
+	"testing"
+)
+
+func TestLogProbsUnmarshal(t *testing.T) {
+	//nolint:lll
+	chatShape := `{"content":[{"token":"Hi","logprob":-0.1,"bytes":[72,105],"top_logprobs":[{"token":"Hi","logprob":-0.1},{"token":"Hey","logprob":-2.5}]}]}`
+	legacyShape := `{"tokens":["Hi"],"token_logprobs":[-0.1],"top_logprobs":[{"Hey":-2.5,"Hi":-0.1}]}`
+
+	for name, data := range map[string]string{"chat": chatShape, "legacy": legacyShape} {
+		var choice ChatCompletionChoice
+		err := json.Unmarshal([]byte(`{"index":0,"logprobs":`+data+`}`), &choice)
+		checks.NoError(t, err, "Unmarshal error")
+		if choice.LogProbs == nil || len(choice.LogProbs.Content) != 1 {
+			t.Fatalf("%s: logprobs were not decoded: %+v", name, choice.LogProbs)
+		}
+
+		logProb := choice.LogProbs.Content[0]
+		if logProb.Token != "Hi" || logProb.LogProb != -0.1 {
+			t.Errorf("%s: unexpected token logprob: %+v", name, logProb)
+		}
+		if len(logProb.TopLogProbs) != 2 || logProb.TopLogProbs[0].Token != "Hi" ||
+			logProb.TopLogProbs[1].Token != "Hey" || logProb.TopLogProbs[1].LogProb != -2.5 {
+			t.Errorf("%s: unexpected top logprobs: %+v", name, logProb.TopLogProbs)
+		}
+	}
+
+	var choice ChatCompletionChoice
+	checks.NoError(t, json.Unmarshal([]byte(`{"index":0,"logprobs":null}`), &choice), "Unmarshal error")
+	if choice.LogProbs != nil {
+		t.Errorf("null logprobs should decode to nil, got %+v", choice.LogProbs)
+	}
+}
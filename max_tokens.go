@@ -0,0 +1,31 @@
+package openai
+
+// completionTokenLimit returns the token limit of the request,
+// MaxCompletionTokens taking precedence over MaxTokens, or zero if none is
+// set.
+func (r ChatCompletionRequest) completionTokenLimit() int {
+	if r.MaxCompletionTokens > 0 {
+		return r.MaxCompletionTokens
+	}
+	return r.MaxTokens
+}
+
+// translateMaxTokens moves the token limit of request into the field its
+// model accepts, see ClientConfig.TranslateMaxTokens. A limit is only moved
+// if the other field is unset, but MaxTokens is always cleared for models
+// that reject it.
+func translateMaxTokens(request ChatCompletionRequest) ChatCompletionRequest {
+	capabilities, ok := GetModelCapabilities(request.Model)
+	if !ok {
+		return request
+	}
+	if capabilities.RequiresMaxCompletionTokens {
+		if request.MaxCompletionTokens == 0 {
+			request.MaxCompletionTokens = request.MaxTokens
+		}
+		request.MaxTokens = 0
+	} else if request.MaxTokens == 0 {
+		request.MaxTokens, request.MaxCompletionTokens = request.MaxCompletionTokens, 0
+	}
+	return request
+}
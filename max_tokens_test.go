@@ -0,0 +1,80 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestTranslateMaxTokens(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var body map[string]any
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		body = nil
+		checks.NoError(t, json.NewDecoder(r.Body).Decode(&body), "could not decode request")
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.TranslateMaxTokens = true
+	client := NewClientWithConfig(config)
+	messages := []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}}
+
+	tests := []struct {
+		name                   string
+		request                ChatCompletionRequest
+		maxTokens, maxComplete any
+	}{
+		{"o1 with max_tokens", ChatCompletionRequest{Model: O1, MaxTokens: 100}, nil, float64(100)},
+		{"legacy with max_completion_tokens", ChatCompletionRequest{Model: GPT4, MaxCompletionTokens: 100}, float64(100), nil},
+		{"legacy with max_tokens", ChatCompletionRequest{Model: GPT4, MaxTokens: 100}, float64(100), nil},
+		{"both set", ChatCompletionRequest{Model: O1, MaxTokens: 50, MaxCompletionTokens: 100}, nil, float64(100)},
+		{"legacy both set", ChatCompletionRequest{Model: GPT4, MaxTokens: 50, MaxCompletionTokens: 100},
+			float64(50), float64(100)},
+		{"unknown model", ChatCompletionRequest{Model: "my-model", MaxCompletionTokens: 100}, nil, float64(100)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.request.Messages = messages
+			_, err := client.CreateChatCompletion(context.Background(), tt.request)
+			checks.NoError(t, err, "CreateChatCompletion error")
+			if body["max_tokens"] != tt.maxTokens || body["max_completion_tokens"] != tt.maxComplete {
+				t.Errorf("expected max_tokens %v and max_completion_tokens %v, got %v and %v",
+					tt.maxTokens, tt.maxComplete, body["max_tokens"], body["max_completion_tokens"])
+			}
+		})
+	}
+
+	config.TranslateMaxTokens = false
+	client = NewClientWithConfig(config)
+	_, err := client.CreateChatCompletion(context.Background(),
+		ChatCompletionRequest{Model: O1, MaxTokens: 100, Messages: messages})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if body["max_tokens"] != float64(100) || body["max_completion_tokens"] != nil {
+		t.Errorf("expected the request to be sent as is without TranslateMaxTokens, got %v", body)
+	}
+}
+
+func TestMaxResponseTokensMaxCompletionTokens(t *testing.T) {
+	request := ChatCompletionRequest{
+		Model:               GPT4o,
+		Messages:            []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		MaxCompletionTokens: 100,
+	}
+	remaining, err := MaxResponseTokens(request, GPT4o)
+	checks.NoError(t, err, "MaxResponseTokens error")
+	if remaining != 100 {
+		t.Errorf("expected MaxCompletionTokens to cap the response, got %d", remaining)
+	}
+}
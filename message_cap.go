@@ -0,0 +1,68 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TruncationMarker is appended to message contents cut by
+// EnforceMessageTokenCap.
+const TruncationMarker = " [truncated]"
+
+var ErrMessageTokenCapTooSmall = errors.New("message token cap does not leave room for the truncation marker")
+
+// EnforceMessageTokenCap returns a copy of messages in which the content of
+// each message longer than capPerMessage tokens of model is cut on a token
+// boundary and ends with TruncationMarker, within the cap. It also returns
+// the indexes of the truncated messages. Token boundaries are exact if the
+// tokenizer registered for model is a TokenEncoder, and otherwise the longest
+// prefix that the tokenizer counts within the cap is kept. Only Content is
+// truncated; the text of MultiContent parts is left as is.
+func EnforceMessageTokenCap(
+	messages []ChatCompletionMessage,
+	model string,
+	capPerMessage int,
+) ([]ChatCompletionMessage, []int, error) {
+	tokenizer := tokenizerForModel(model)
+	budget := capPerMessage - tokenizer.CountTokens(TruncationMarker)
+	if budget < 1 {
+		return nil, nil, fmt.Errorf("%w: cap of %d tokens", ErrMessageTokenCapTooSmall, capPerMessage)
+	}
+
+	capped := make([]ChatCompletionMessage, len(messages))
+	copy(capped, messages)
+	var truncated []int
+	for i, msg := range capped {
+		if tokenizer.CountTokens(msg.Content) <= capPerMessage {
+			continue
+		}
+		capped[i].Content = truncateToTokens(tokenizer, msg.Content, budget) + TruncationMarker
+		truncated = append(truncated, i)
+	}
+	return capped, truncated, nil
+}
+
+// truncateToTokens returns the longest prefix of text of at most n tokens.
+func truncateToTokens(tokenizer Tokenizer, text string, n int) string {
+	if encoder, ok := tokenizer.(TokenEncoder); ok {
+		tokens := encoder.Encode(text)
+		if len(tokens) <= n {
+			return text
+		}
+		return encoder.Decode(tokens[:n])
+	}
+
+	// Token counts grow with the prefix, so the longest prefix within n
+	// tokens is found by binary search over its length in runes.
+	runes := []rune(text)
+	lo, hi := 0, len(runes)
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if tokenizer.CountTokens(string(runes[:mid])) <= n {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return string(runes[:lo])
+}
@@ -0,0 +1,94 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// wordEncoder treats every space-separated word as a token, with IDs
+// assigned in order of appearance.
+type wordEncoder struct {
+	ids   map[string]int
+	words []string
+}
+
+func newWordEncoder() *wordEncoder {
+	return &wordEncoder{ids: make(map[string]int)}
+}
+
+func (e *wordEncoder) CountTokens(text string) int {
+	return len(e.Encode(text))
+}
+
+// wordPattern matches a word with its leading whitespace, as in BPE
+// vocabularies.
+var wordPattern = regexp.MustCompile(`\s*\S+`)
+
+func (e *wordEncoder) Encode(text string) []int {
+	var tokens []int
+	for _, word := range wordPattern.FindAllString(text, -1) {
+		id, ok := e.ids[word]
+		if !ok {
+			id = len(e.words)
+			e.ids[word] = id
+			e.words = append(e.words, word)
+		}
+		tokens = append(tokens, id)
+	}
+	return tokens
+}
+
+func (e *wordEncoder) Decode(tokens []int) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString(e.words[token])
+	}
+	return sb.String()
+}
+
+func TestEnforceMessageTokenCap(t *testing.T) {
+	const model = "message-cap-test-model"
+	RegisterTokenizer(model, newWordEncoder())
+
+	messages := []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "Answer from the context."},
+		{Role: ChatMessageRoleUser, Content: "one two three four five six seven eight"},
+	}
+	capped, truncated, err := EnforceMessageTokenCap(messages, model, 6)
+	checks.NoError(t, err, "EnforceMessageTokenCap error")
+	if len(truncated) != 1 || truncated[0] != 1 {
+		t.Errorf("expected message 1 to be truncated, got %v", truncated)
+	}
+	if capped[0].Content != messages[0].Content {
+		t.Errorf("short message was changed: %q", capped[0].Content)
+	}
+	// The marker takes up one of the six tokens.
+	if expected := "one two three four five" + TruncationMarker; capped[1].Content != expected {
+		t.Errorf("expected %q, got %q", expected, capped[1].Content)
+	}
+	if messages[1].Content != "one two three four five six seven eight" {
+		t.Error("the input messages were modified")
+	}
+
+	_, _, err = EnforceMessageTokenCap(messages, model, 1)
+	checks.ErrorIs(t, err, ErrMessageTokenCapTooSmall, "cap without room for the marker accepted")
+}
+
+func TestEnforceMessageTokenCapApprox(t *testing.T) {
+	messages := []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: strings.Repeat("日本語", 20)}}
+	capped, truncated, err := EnforceMessageTokenCap(messages, "unregistered-model", 10)
+	checks.NoError(t, err, "EnforceMessageTokenCap error")
+	if len(truncated) != 1 {
+		t.Fatalf("expected the message to be truncated, got %v", truncated)
+	}
+	if n := CountTokens("unregistered-model", capped[0].Content); n > 10 {
+		t.Errorf("truncated content has %d tokens: %q", n, capped[0].Content)
+	}
+	if !strings.HasPrefix(capped[0].Content, "日本") || !strings.HasSuffix(capped[0].Content, TruncationMarker) {
+		t.Errorf("unexpected truncated content %q", capped[0].Content)
+	}
+}
@@ -0,0 +1,43 @@
+package openai
+
+import "sync"
+
+var (
+	modelAliasesMu sync.RWMutex
+
+	// modelAliases maps model aliases to the snapshots they currently point
+	// to.
+	modelAliases = map[string]string{
+		O1:               "o1-2024-12-17",
+		O1Mini:           "o1-mini-2024-09-12",
+		GPT4o:            "gpt-4o-2024-08-06",
+		GPT4oMini:        "gpt-4o-mini-2024-07-18",
+		GPT4Turbo:        "gpt-4-turbo-2024-04-09",
+		GPT4:             GPT40613,
+		GPT432K:          GPT432K0613,
+		GPT3Dot5Turbo:    "gpt-3.5-turbo-0125",
+		GPT3Dot5Turbo16K: GPT3Dot5Turbo16K0613,
+	}
+)
+
+// ResolveModel returns the dated snapshot that the model alias points to.
+// Unknown aliases, including snapshots, are returned unchanged with false.
+func ResolveModel(alias string) (string, bool) {
+	modelAliasesMu.RLock()
+	defer modelAliasesMu.RUnlock()
+
+	model, ok := modelAliases[alias]
+	if !ok {
+		return alias, false
+	}
+	return model, true
+}
+
+// RegisterModelAlias registers or replaces the snapshot that alias resolves
+// to, e.g. when a provider moves an alias to a newer snapshot.
+func RegisterModelAlias(alias, model string) {
+	modelAliasesMu.Lock()
+	defer modelAliasesMu.Unlock()
+
+	modelAliases[alias] = model
+}
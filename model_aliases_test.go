@@ -0,0 +1,55 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestResolveModel(t *testing.T) {
+	if model, ok := ResolveModel(GPT4); !ok || model != GPT40613 {
+		t.Errorf("unexpected resolution of %s: %q, %v", GPT4, model, ok)
+	}
+	if model, ok := ResolveModel("my-fine-tune"); ok || model != "my-fine-tune" {
+		t.Errorf("unknown alias should pass through unchanged, got %q, %v", model, ok)
+	}
+
+	RegisterModelAlias("my-alias", "my-model-2024-01-01")
+	if model, _ := ResolveModel("my-alias"); model != "my-model-2024-01-01" {
+		t.Errorf("registered alias was not resolved: %q", model)
+	}
+}
+
+func TestResolveModelAliases(t *testing.T) {
+	server := test.NewTestServer()
+	var model string
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		model = req.Model
+		resBytes, _ := json.Marshal(ChatCompletionResponse{Model: req.Model})
+		_, _ = w.Write(resBytes)
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.ResolveModelAliases = true
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if model != GPT40613 {
+		t.Errorf("expected the request for %s, got %q", GPT40613, model)
+	}
+}
@@ -0,0 +1,72 @@
+package openai
+
+import "sync"
+
+// ModelCapabilities describes the limits and features of a chat model.
+type ModelCapabilities struct {
+	// ContextWindow is the maximum number of tokens of prompt and
+	// completion combined.
+	ContextWindow int
+	// MaxOutputTokens is the maximum number of tokens the model generates
+	// in a single completion.
+	MaxOutputTokens int
+	// SupportsFunctions reports whether the model accepts functions and tools.
+	SupportsFunctions bool
+	// MaxN is the largest number of choices a single request may ask for,
+	// or zero if the model has no known limit.
+	MaxN int
+	// RequiresMaxCompletionTokens reports whether the model rejects
+	// max_tokens and only accepts max_completion_tokens.
+	RequiresMaxCompletionTokens bool
+}
+
+var (
+	modelCapabilitiesMu sync.RWMutex
+
+	modelCapabilities = map[string]ModelCapabilities{
+		O1: {
+			ContextWindow: 200000, MaxOutputTokens: 100000, SupportsFunctions: true, RequiresMaxCompletionTokens: true,
+		},
+		O1Mini:               {ContextWindow: 128000, MaxOutputTokens: 65536, RequiresMaxCompletionTokens: true},
+		GPT4o:                {ContextWindow: 128000, MaxOutputTokens: 16384, SupportsFunctions: true},
+		GPT4oMini:            {ContextWindow: 128000, MaxOutputTokens: 16384, SupportsFunctions: true},
+		GPT4Turbo:            {ContextWindow: 128000, MaxOutputTokens: 4096, SupportsFunctions: true},
+		GPT4:                 {ContextWindow: 8192, MaxOutputTokens: 8192, SupportsFunctions: true},
+		GPT40314:             {ContextWindow: 8192, MaxOutputTokens: 8192},
+		GPT40613:             {ContextWindow: 8192, MaxOutputTokens: 8192, SupportsFunctions: true},
+		GPT432K:              {ContextWindow: 32768, MaxOutputTokens: 32768, SupportsFunctions: true},
+		GPT432K0314:          {ContextWindow: 32768, MaxOutputTokens: 32768},
+		GPT432K0613:          {ContextWindow: 32768, MaxOutputTokens: 32768, SupportsFunctions: true},
+		GPT35Turbo:           {ContextWindow: 4096, MaxOutputTokens: 4096, SupportsFunctions: true},
+		GPT3Dot5Turbo0301:    {ContextWindow: 4096, MaxOutputTokens: 4096},
+		GPT3Dot5Turbo0613:    {ContextWindow: 4096, MaxOutputTokens: 4096, SupportsFunctions: true},
+		GPT3Dot5Turbo16K:     {ContextWindow: 16384, MaxOutputTokens: 16384, SupportsFunctions: true},
+		GPT3Dot5Turbo16K0613: {ContextWindow: 16384, MaxOutputTokens: 16384, SupportsFunctions: true},
+	}
+)
+
+// GetModelCapabilities returns the capabilities registered for model.
+func GetModelCapabilities(model string) (ModelCapabilities, bool) {
+	modelCapabilitiesMu.RLock()
+	defer modelCapabilitiesMu.RUnlock()
+
+	capabilities, ok := modelCapabilities[model]
+	return capabilities, ok
+}
+
+// RegisterModelCapabilities registers or replaces the capabilities of model,
+// e.g. for fine-tuned models or models served by compatible providers.
+func RegisterModelCapabilities(model string, capabilities ModelCapabilities) {
+	modelCapabilitiesMu.Lock()
+	defer modelCapabilitiesMu.Unlock()
+
+	modelCapabilities[model] = capabilities
+}
+
+// UnregisterModelCapabilities removes the capabilities registered for model.
+func UnregisterModelCapabilities(model string) {
+	modelCapabilitiesMu.Lock()
+	defer modelCapabilitiesMu.Unlock()
+
+	delete(modelCapabilities, model)
+}
@@ -0,0 +1,60 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"testing"
+)
+
+func TestGetModelCapabilities(t *testing.T) {
+	capabilities, ok := GetModelCapabilities(GPT4o)
+	if !ok {
+		t.Fatalf("no capabilities registered for %s", GPT4o)
+	}
+	if capabilities.ContextWindow != 128000 || !capabilities.SupportsFunctions {
+		t.Errorf("unexpected capabilities for %s: %+v", GPT4o, capabilities)
+	}
+
+	if _, ok = GetModelCapabilities("unknown-model"); ok {
+		t.Fatal("unknown model should not have capabilities")
+	}
+	RegisterModelCapabilities("my-custom-model", ModelCapabilities{ContextWindow: 1024, SupportsFunctions: true})
+	t.Cleanup(func() { UnregisterModelCapabilities("my-custom-model") })
+	capabilities, ok = GetModelCapabilities("my-custom-model")
+	if !ok || capabilities.ContextWindow != 1024 {
+		t.Errorf("registered capabilities were not returned: %+v", capabilities)
+	}
+
+	UnregisterModelCapabilities("my-custom-model")
+	if _, ok = GetModelCapabilities("my-custom-model"); ok {
+		t.Error("unregistered model should not have capabilities")
+	}
+}
+
+func TestChatCompletionFunctionsRequireCapableModel(t *testing.T) {
+	config := DefaultConfig("whatever")
+	config.BaseURL = "http://localhost/v1"
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:     GPT40314,
+		Messages:  []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		Functions: []Functions{{Name: "test"}},
+	})
+	checks.ErrorIs(t, err, ErrModelNotSupportedWithPlugins, "expected ErrModelNotSupportedWithPlugins")
+}
+
+func TestChatCompletionFunctionsUnknownModel(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", handleChatCompletionEndpoint)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:     "my-fine-tuned-model",
+		Messages:  []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		Functions: []Functions{{Name: "test"}},
+	})
+	checks.NoError(t, err, "models without registered capabilities should accept functions")
+}
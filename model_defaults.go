@@ -0,0 +1,53 @@
+package openai
+
+import (
+	"reflect"
+	"sync"
+)
+
+type modelDefaults struct {
+	mu       sync.RWMutex
+	requests map[string]ChatCompletionRequest
+}
+
+// SetModelDefaults registers default parameters for chat completion requests
+// for model, e.g. its Temperature and MaxTokens, replacing any defaults set
+// before. Fields that a request leaves at their zero value, such as a nil
+// Temperature, are filled in from defaults before the request is sent;
+// explicitly set fields always win. The Model, Messages and Stream fields of
+// defaults are ignored. Defaults are looked up by the model as requested,
+// before any alias is resolved.
+func (c *Client) SetModelDefaults(model string, defaults ChatCompletionRequest) {
+	c.defaults.mu.Lock()
+	defer c.defaults.mu.Unlock()
+
+	if c.defaults.requests == nil {
+		c.defaults.requests = make(map[string]ChatCompletionRequest)
+	}
+	c.defaults.requests[model] = defaults
+}
+
+// apply returns request with its zero fields filled in from the defaults of
+// its model.
+func (d *modelDefaults) apply(request ChatCompletionRequest) ChatCompletionRequest {
+	d.mu.RLock()
+	defaults, ok := d.requests[request.Model]
+	d.mu.RUnlock()
+	if !ok {
+		return request
+	}
+
+	target := reflect.ValueOf(&request).Elem()
+	source := reflect.ValueOf(defaults)
+	for i := 0; i < target.NumField(); i++ {
+		switch target.Type().Field(i).Name {
+		case "Model", "Messages", "Stream":
+			continue
+		}
+		field := target.Field(i)
+		if field.CanSet() && field.IsZero() {
+			field.Set(source.Field(i))
+		}
+	}
+	return request
+}
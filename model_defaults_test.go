@@ -0,0 +1,81 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestSetModelDefaults(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var received ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		received, err = getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		if received.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(`{"choices":[]}`))
+	})
+
+	client.SetModelDefaults(GPT4o, ChatCompletionRequest{
+		Model:       GPT4oMini,
+		Messages:    []ChatCompletionMessage{{Role: ChatMessageRoleSystem, Content: "ignored"}},
+		Temperature: Float32(0.2),
+		MaxTokens:   256,
+		Stop:        []string{"END"},
+	})
+	messages := []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}}
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: messages,
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if received.Model != GPT4o || len(received.Messages) != 1 {
+		t.Errorf("the model and messages must not be defaulted, got %s with %d messages",
+			received.Model, len(received.Messages))
+	}
+	if received.Temperature == nil || *received.Temperature != 0.2 || received.MaxTokens != 256 ||
+		len(received.Stop) != 1 {
+		t.Errorf("defaults were not applied: %+v", received)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:       GPT4o,
+		Messages:    messages,
+		Temperature: Float32(0),
+		MaxTokens:   10,
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if received.Temperature == nil || *received.Temperature != 0 || received.MaxTokens != 10 {
+		t.Errorf("explicitly set fields must win: %+v", received)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: messages,
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+	if received.MaxTokens != 256 {
+		t.Errorf("defaults were not applied to the stream: %+v", received)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: messages,
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if received.Temperature != nil || received.MaxTokens != 0 {
+		t.Errorf("defaults of another model were applied: %+v", received)
+	}
+}
@@ -2,6 +2,8 @@ package openai
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/http"
 )
 
@@ -71,3 +73,51 @@ func (c *Client) Moderations(ctx context.Context, request ModerationRequest) (re
 	err = c.sendRequest(req, &response)
 	return
 }
+
+// ErrContentFlagged is matched by the error returned when
+// ClientConfig.ModerationGuard is enabled and a user message is flagged.
+var ErrContentFlagged = errors.New("content flagged by moderation")
+
+// ContentFlaggedError is returned when ClientConfig.ModerationGuard is
+// enabled and the moderation endpoint flags a user message. It matches
+// ErrContentFlagged with errors.Is.
+type ContentFlaggedError struct {
+	// MessageIndex is the index of the flagged message in the request.
+	MessageIndex int
+	// Result holds the flagged categories and their scores.
+	Result Result
+}
+
+func (e *ContentFlaggedError) Error() string {
+	return fmt.Sprintf("%s: message %d", ErrContentFlagged, e.MessageIndex)
+}
+
+func (e *ContentFlaggedError) Is(target error) bool {
+	return target == ErrContentFlagged
+}
+
+// moderateMessages runs the content of every user message through the
+// moderation endpoint and returns a *ContentFlaggedError for the first one
+// that is flagged.
+func (c *Client) moderateMessages(ctx context.Context, messages []ChatCompletionMessage) error {
+	for i, msg := range messages {
+		if msg.Role != ChatMessageRoleUser {
+			continue
+		}
+		input := msg.TextContent()
+		if input == "" {
+			continue
+		}
+
+		response, err := c.Moderations(ctx, ModerationRequest{Input: input})
+		if err != nil {
+			return fmt.Errorf("moderation: %w", err)
+		}
+		for _, result := range response.Results {
+			if result.Flagged {
+				return &ContentFlaggedError{MessageIndex: i, Result: result}
+			}
+		}
+	}
+	return nil
+}
@@ -2,10 +2,12 @@ package openai_test
 
 import (
 	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
 	"github.com/sashabaranov/go-openai/internal/test/checks"
 
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -85,3 +87,61 @@ func getModerationBody(r *http.Request) (ModerationRequest, error) {
 	}
 	return moderation, nil
 }
+
+func TestModerationGuard(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	server.RegisterHandler("/v1/moderations", func(w http.ResponseWriter, r *http.Request) {
+		moderationReq, err := getModerationBody(r)
+		checks.NoError(t, err, "could not read request")
+		result := Result{}
+		if strings.Contains(moderationReq.Input, "kill") {
+			result = Result{
+				Categories:     ResultCategories{Violence: true},
+				CategoryScores: ResultCategoryScores{Violence: 0.9},
+				Flagged:        true,
+			}
+		}
+		resBytes, _ := json.Marshal(ModerationResponse{Results: []Result{result}})
+		_, _ = w.Write(resBytes)
+	})
+	var chatRequests int
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		chatRequests++
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.ModerationGuard = true
+	client := NewClientWithConfig(config)
+
+	request := ChatCompletionRequest{
+		Model: GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{
+			{Role: ChatMessageRoleSystem, Content: "You may mention kill switches."},
+			{Role: ChatMessageRoleUser, Content: "Hello!"},
+		},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "unflagged request should be sent")
+
+	request.Messages = append(request.Messages, ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "I want to kill them."})
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	checks.ErrorIs(t, err, ErrContentFlagged, "flagged request should fail")
+
+	var flaggedErr *ContentFlaggedError
+	if !errors.As(err, &flaggedErr) {
+		t.Fatalf("expected *ContentFlaggedError, got %T", err)
+	}
+	if flaggedErr.MessageIndex != 2 || flaggedErr.Result.CategoryScores.Violence != 0.9 {
+		t.Errorf("unexpected flagged error: %+v", flaggedErr)
+	}
+	if chatRequests != 1 {
+		t.Errorf("flagged request was sent to the chat endpoint")
+	}
+}
@@ -0,0 +1,87 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// KeyedStreamEvent is an event of one of the streams of MultiStream.
+type KeyedStreamEvent struct {
+	// Key is the key of the request whose stream the event belongs to.
+	Key string
+	// Response is a frame of the stream, unless Err is set or Done is true.
+	Response ChatCompletionStreamResponse
+	// Err is the error that ended the stream, including a failure to create
+	// it.
+	Err error
+	// Done reports that the stream ended successfully.
+	Done bool
+}
+
+// MultiStream streams the requests concurrently and delivers their frames on
+// a single channel, tagged with the key of their request, e.g. to compare
+// models side by side. Every stream ends with one event that has Done or Err
+// set; an error ends only its own stream. The channel is closed once all
+// streams have ended. Cancel ctx to stop reading early; the channel is then
+// closed after at most one further event per stream, one that was already
+// being sent when ctx was canceled.
+func (c *Client) MultiStream(ctx context.Context, requests map[string]ChatCompletionRequest) <-chan KeyedStreamEvent {
+	events := make(chan KeyedStreamEvent)
+	var wg sync.WaitGroup
+	for key, request := range requests {
+		wg.Add(1)
+		go func(key string, request ChatCompletionRequest) {
+			defer wg.Done()
+			c.forwardStream(ctx, key, request, events)
+		}(key, request)
+	}
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+	return events
+}
+
+func (c *Client) forwardStream(
+	ctx context.Context,
+	key string,
+	request ChatCompletionRequest,
+	events chan<- KeyedStreamEvent,
+) {
+	send := func(event KeyedStreamEvent) bool {
+		// select picks at random if ctx is done and the event can be sent.
+		if ctx.Err() != nil {
+			return false
+		}
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	stream, err := c.CreateChatCompletionStream(ctx, request)
+	if err != nil {
+		send(KeyedStreamEvent{Key: key, Err: err})
+		return
+	}
+	defer stream.Close()
+
+	for {
+		response, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			send(KeyedStreamEvent{Key: key, Done: true})
+			return
+		}
+		if err != nil {
+			send(KeyedStreamEvent{Key: key, Err: err})
+			return
+		}
+		if !send(KeyedStreamEvent{Key: key, Response: response}) {
+			return
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestMultiStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		if req.Model == "broken-model" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"unknown model","type":"invalid_request_error"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"Hello", " from ", req.Model} {
+			_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	messages := []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}}
+	events := client.MultiStream(context.Background(), map[string]ChatCompletionRequest{
+		"gpt-4o":      {Model: GPT4o, Messages: messages},
+		"gpt-4o-mini": {Model: GPT4oMini, Messages: messages},
+		"broken":      {Model: "broken-model", Messages: messages},
+	})
+
+	contents := make(map[string]string)
+	done := make(map[string]bool)
+	errs := make(map[string]error)
+	for event := range events {
+		switch {
+		case event.Err != nil:
+			errs[event.Key] = event.Err
+		case event.Done:
+			done[event.Key] = true
+		default:
+			contents[event.Key] += event.Response.Choices[0].Delta.Content
+		}
+	}
+
+	if contents["gpt-4o"] != "Hello from gpt-4o" || contents["gpt-4o-mini"] != "Hello from gpt-4o-mini" {
+		t.Errorf("unexpected contents: %v", contents)
+	}
+	if !done["gpt-4o"] || !done["gpt-4o-mini"] || done["broken"] {
+		t.Errorf("unexpected finished streams: %v", done)
+	}
+	if len(errs) != 1 || errs["broken"] == nil {
+		t.Errorf("expected an error for the broken stream only, got %v", errs)
+	}
+}
+
+func TestMultiStreamCancel(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		for r.Context().Err() == nil {
+			_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"more"}}]}` + "\n\n"))
+			flusher.Flush()
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	messages := []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}}
+	events := client.MultiStream(ctx, map[string]ChatCompletionRequest{
+		"a": {Model: GPT4o, Messages: messages},
+		"b": {Model: GPT4oMini, Messages: messages},
+	})
+
+	<-events
+	cancel()
+	late := make(map[string]int)
+	for event := range events {
+		late[event.Key]++
+	}
+	if late["a"] > 1 || late["b"] > 1 {
+		t.Errorf("expected at most one event per stream after canceling, got %v", late)
+	}
+}
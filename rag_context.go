@@ -0,0 +1,76 @@
+package openai
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContextInstructions introduces the documents of a ContextMessage.
+const ContextInstructions = "Answer using the documents below. Cite the source of every document you use."
+
+// Document is a retrieved chunk of text to include in a ContextMessage.
+type Document struct {
+	// Source identifies the document for citations, e.g. a file name or URL.
+	Source  string
+	Content string
+}
+
+// ContextOption configures ContextMessage.
+type ContextOption func(*contextOptions)
+
+type contextOptions struct {
+	role      string
+	model     string
+	maxTokens int
+}
+
+// WithContextRole sets the role of the message ContextMessage returns. It
+// defaults to ChatMessageRoleSystem.
+func WithContextRole(role string) ContextOption {
+	return func(o *contextOptions) {
+		o.role = role
+	}
+}
+
+// WithContextTokenLimit caps the content of the message at maxTokens tokens
+// of model, see CountTokens, by dropping the lowest-ranked documents.
+func WithContextTokenLimit(model string, maxTokens int) ContextOption {
+	return func(o *contextOptions) {
+		o.model = model
+		o.maxTokens = maxTokens
+	}
+}
+
+// ContextMessage formats retrieved documents, ordered from the highest to
+// the lowest rank, into a single message for retrieval-augmented generation.
+// Every document is enclosed in <document> tags carrying its number and
+// source so that the model can tell them apart and cite them, preceded by
+// ContextInstructions. With WithContextTokenLimit, documents are dropped from
+// the end until the message fits.
+func ContextMessage(chunks []Document, opts ...ContextOption) ChatCompletionMessage {
+	options := &contextOptions{role: ChatMessageRoleSystem}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	content := formatDocuments(chunks)
+	if options.maxTokens > 0 {
+		tokenizer := tokenizerForModel(options.model)
+		for len(chunks) > 0 && tokenizer.CountTokens(content) > options.maxTokens {
+			chunks = chunks[:len(chunks)-1]
+			content = formatDocuments(chunks)
+		}
+	}
+	return ChatCompletionMessage{Role: options.role, Content: content}
+}
+
+func formatDocuments(chunks []Document) string {
+	var sb strings.Builder
+	sb.WriteString(ContextInstructions)
+	for i, chunk := range chunks {
+		fmt.Fprintf(&sb, "\n\n<document id=\"%d\" source=%s>\n%s\n</document>",
+			i+1, strconv.Quote(chunk.Source), strings.TrimSpace(chunk.Content))
+	}
+	return sb.String()
+}
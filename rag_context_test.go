@@ -0,0 +1,41 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"strings"
+	"testing"
+)
+
+func TestContextMessage(t *testing.T) {
+	chunks := []Document{
+		{Source: "handbook.pdf", Content: "Refunds are granted within 30 days.\n"},
+		{Source: `faq "v2"`, Content: "Shipping takes 3 days."},
+	}
+
+	msg := ContextMessage(chunks)
+	expected := ContextInstructions +
+		"\n\n<document id=\"1\" source=\"handbook.pdf\">\nRefunds are granted within 30 days.\n</document>" +
+		"\n\n<document id=\"2\" source=\"faq \\\"v2\\\"\">\nShipping takes 3 days.\n</document>"
+	if msg.Role != ChatMessageRoleSystem || msg.Content != expected {
+		t.Errorf("unexpected message %q: %q", msg.Role, msg.Content)
+	}
+
+	if msg = ContextMessage(chunks, WithContextRole(ChatMessageRoleUser)); msg.Role != ChatMessageRoleUser {
+		t.Errorf("unexpected role %q", msg.Role)
+	}
+
+	limit := CountTokens(GPT4o, ContextMessage(chunks[:1]).Content)
+	msg = ContextMessage(chunks, WithContextTokenLimit(GPT4o, limit))
+	if !strings.Contains(msg.Content, "handbook.pdf") || strings.Contains(msg.Content, "faq") {
+		t.Errorf("expected the lowest-ranked document to be dropped, got %q", msg.Content)
+	}
+	if CountTokens(GPT4o, msg.Content) > limit {
+		t.Errorf("message exceeds the token limit: %q", msg.Content)
+	}
+
+	msg = ContextMessage(chunks, WithContextTokenLimit(GPT4o, 1))
+	if msg.Content != ContextInstructions {
+		t.Errorf("expected every document to be dropped, got %q", msg.Content)
+	}
+}
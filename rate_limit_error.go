@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrRateLimited is matched by errors returned when the API responds with
+// 429 Too Many Requests.
+var ErrRateLimited = errors.New("rate limited")
+
+// RateLimitError is returned when the API responds with 429 Too Many
+// Requests. It matches ErrRateLimited with errors.Is and unwraps to the
+// underlying *APIError, or *RequestError if the response had no error body,
+// whose message is included in the error string.
+type RateLimitError struct {
+	// RetryAfter is the time to wait before retrying, parsed from the
+	// Retry-After header, or zero if the header is missing or invalid.
+	RetryAfter time.Duration
+
+	Err error
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// newRateLimitError wraps err in a *RateLimitError if resp has status 429,
+// and returns err unchanged otherwise.
+func newRateLimitError(resp *http.Response, err error) error {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return err
+	}
+	return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()), Err: err}
+}
+
+// parseRetryAfter parses a Retry-After header value in either its
+// delta-seconds or its HTTP-date form. A date is converted into the
+// duration from now; dates in the past yield zero.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	date, err := http.ParseTime(value)
+	if err != nil || !date.After(now) {
+		return 0
+	}
+	return date.Sub(now)
+}
@@ -0,0 +1,65 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRateLimitError(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	retryAfter := "20"
+	body := `{"error":{"message":"You are sending requests too quickly.","code":"rate_limit_reached"}}`
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(body))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	_, err := client.CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrRateLimited, "expected ErrRateLimited")
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 20*time.Second {
+		t.Fatalf("expected a retry after 20s, got %v", err)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.HTTPStatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected the error to wrap the APIError, got %v", err)
+	}
+	if err.Error() != apiErr.Error() {
+		t.Errorf("expected the message of the APIError, got %q", err.Error())
+	}
+
+	retryAfter = time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	_, err = client.CreateChatCompletionStream(context.Background(), req)
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter < 59*time.Minute || rateErr.RetryAfter > time.Hour {
+		t.Errorf("expected a retry after about an hour from the stream, got %v", err)
+	}
+
+	retryAfter = time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	_, err = client.CreateChatCompletion(context.Background(), req)
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 0 {
+		t.Errorf("expected no wait for a date in the past, got %v", err)
+	}
+
+	retryAfter, body = "", "slow down"
+	_, err = client.CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrRateLimited, "expected ErrRateLimited without an error body")
+	var reqErr *RequestError
+	if !errors.As(err, &rateErr) || rateErr.RetryAfter != 0 || !errors.As(err, &reqErr) {
+		t.Errorf("expected a RequestError without retry delay, got %v", err)
+	}
+}
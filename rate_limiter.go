@@ -0,0 +1,139 @@
+package openai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimiter throttles requests on the client side. Wait blocks until the
+// next request may be sent or ctx is done.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// TokenRateLimiter is a RateLimiter that also limits tokens. CreateChatCompletion
+// and CreateChatCompletionStream call WaitTokens with the estimate of
+// CountRequestTokens instead of Wait.
+type TokenRateLimiter interface {
+	RateLimiter
+	WaitTokens(ctx context.Context, tokens int) error
+}
+
+// TokenBucketLimiter limits requests per minute and tokens per minute with
+// two continuously refilled token buckets. A zero limit disables the
+// corresponding bucket.
+type TokenBucketLimiter struct {
+	mu       sync.Mutex
+	requests bucket
+	tokens   bucket
+	now      func() time.Time
+}
+
+type bucket struct {
+	capacity  float64
+	available float64
+	updatedAt time.Time
+}
+
+// NewTokenBucketLimiter creates a limiter allowing requestsPerMinute requests
+// and tokensPerMinute tokens per minute, starting with full buckets.
+func NewTokenBucketLimiter(requestsPerMinute, tokensPerMinute int) *TokenBucketLimiter {
+	now := time.Now()
+	return &TokenBucketLimiter{
+		requests: bucket{capacity: float64(requestsPerMinute), available: float64(requestsPerMinute), updatedAt: now},
+		tokens:   bucket{capacity: float64(tokensPerMinute), available: float64(tokensPerMinute), updatedAt: now},
+		now:      time.Now,
+	}
+}
+
+// Wait blocks until a request may be sent.
+func (l *TokenBucketLimiter) Wait(ctx context.Context) error {
+	return l.WaitTokens(ctx, 0)
+}
+
+// WaitTokens blocks until a request consuming tokens may be sent. Requests
+// estimated above the tokens per minute limit wait for a full bucket.
+func (l *TokenBucketLimiter) WaitTokens(ctx context.Context, tokens int) error {
+	for {
+		delay := l.reserve(float64(tokens))
+		if delay == 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes a request and tokens from the buckets if both suffice and
+// returns zero, or returns how long to wait before trying again.
+func (l *TokenBucketLimiter) reserve(tokens float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.requests.refill(now)
+	l.tokens.refill(now)
+
+	if l.tokens.capacity > 0 && tokens > l.tokens.capacity {
+		tokens = l.tokens.capacity
+	}
+	delay := l.requests.delay(1)
+	if tokenDelay := l.tokens.delay(tokens); tokenDelay > delay {
+		delay = tokenDelay
+	}
+	if delay > 0 {
+		return delay
+	}
+
+	l.requests.take(1)
+	l.tokens.take(tokens)
+	return 0
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.capacity == 0 || !now.After(b.updatedAt) {
+		return
+	}
+	b.available += now.Sub(b.updatedAt).Minutes() * b.capacity
+	if b.available > b.capacity {
+		b.available = b.capacity
+	}
+	b.updatedAt = now
+}
+
+func (b *bucket) delay(n float64) time.Duration {
+	if b.capacity == 0 || b.available >= n {
+		return 0
+	}
+	missing := n - b.available
+	delay := time.Duration(missing / b.capacity * float64(time.Minute))
+	if delay <= 0 {
+		delay = time.Millisecond
+	}
+	return delay
+}
+
+func (b *bucket) take(n float64) {
+	if b.capacity == 0 {
+		return
+	}
+	b.available -= n
+}
+
+func (c *Client) waitRateLimit(ctx context.Context, request ChatCompletionRequest) error {
+	switch limiter := c.config.RateLimiter.(type) {
+	case nil:
+		return nil
+	case TokenRateLimiter:
+		return limiter.WaitTokens(ctx, CountRequestTokens(request))
+	default:
+		return limiter.Wait(ctx)
+	}
+}
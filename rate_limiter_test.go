@@ -0,0 +1,101 @@
+package openai //nolint:testpackage // testing private field
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketLimiterReserve(t *testing.T) {
+	limiter := NewTokenBucketLimiter(2, 1000)
+	now := limiter.requests.updatedAt
+	limiter.now = func() time.Time { return now }
+
+	if delay := limiter.reserve(400); delay != 0 {
+		t.Fatalf("first request should not wait, got %s", delay)
+	}
+	if delay := limiter.reserve(400); delay != 0 {
+		t.Fatalf("second request should not wait, got %s", delay)
+	}
+	// Both requests per minute are used up: the next one has to wait half a minute.
+	if delay := limiter.reserve(100); delay != 30*time.Second {
+		t.Fatalf("expected a 30s delay for the request bucket, got %s", delay)
+	}
+
+	now = now.Add(30 * time.Second)
+	// 200 tokens left plus 500 refilled.
+	if delay := limiter.reserve(800); delay != 6*time.Second {
+		t.Fatalf("expected a 6s delay for the token bucket, got %s", delay)
+	}
+	if delay := limiter.reserve(700); delay != 0 {
+		t.Fatalf("request within the limits should not wait, got %s", delay)
+	}
+
+	// Requests above the token limit wait for a full bucket instead of forever.
+	now = now.Add(time.Minute)
+	if delay := limiter.reserve(5000); delay != 0 {
+		t.Fatalf("oversized request should pass with a full bucket, got %s", delay)
+	}
+}
+
+func TestTokenBucketLimiterUnlimited(t *testing.T) {
+	limiter := NewTokenBucketLimiter(0, 0)
+	for i := 0; i < 100; i++ {
+		if delay := limiter.reserve(1_000_000); delay != 0 {
+			t.Fatalf("unlimited limiter should never wait, got %s", delay)
+		}
+	}
+}
+
+func TestTokenBucketLimiterWaitRespectsContext(t *testing.T) {
+	limiter := NewTokenBucketLimiter(1, 0)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("first Wait failed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := limiter.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+type recordingTokenLimiter struct {
+	tokens []int
+}
+
+func (l *recordingTokenLimiter) Wait(context.Context) error {
+	return l.WaitTokens(context.Background(), 0)
+}
+
+func (l *recordingTokenLimiter) WaitTokens(_ context.Context, tokens int) error {
+	l.tokens = append(l.tokens, tokens)
+	return errTestRequestBuilderFailed
+}
+
+func TestClientWaitsOnRateLimiter(t *testing.T) {
+	limiter := &recordingTokenLimiter{}
+	config := DefaultConfig("token")
+	config.RateLimiter = limiter
+	client := NewClientWithConfig(config)
+
+	request := ChatCompletionRequest{
+		Model:     GPT3Dot5Turbo,
+		MaxTokens: 10,
+		Messages:  []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	if !errors.Is(err, errTestRequestBuilderFailed) {
+		t.Fatalf("expected the limiter error, got %v", err)
+	}
+	_, err = client.CreateChatCompletionStream(context.Background(), request)
+	if !errors.Is(err, errTestRequestBuilderFailed) {
+		t.Fatalf("expected the limiter error, got %v", err)
+	}
+
+	expected := CountRequestTokens(request)
+	if len(limiter.tokens) != 2 || limiter.tokens[0] != expected || limiter.tokens[1] != expected {
+		t.Errorf("expected two waits for %d tokens, got %v", expected, limiter.tokens)
+	}
+}
@@ -0,0 +1,272 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrReplayExhausted = errors.New("no recorded interaction left to replay")
+	ErrReplayMismatch  = errors.New("request does not match the recorded interaction")
+)
+
+// Interaction is a recorded HTTP request and its response.
+type Interaction struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	RequestBody string `json:"request_body,omitempty"`
+
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	// Chunks holds the response body in the pieces it was read in, which
+	// for streams are usually single server-sent events.
+	Chunks []RecordedChunk `json:"chunks"`
+}
+
+// RecordedChunk is a piece of a recorded response body.
+type RecordedChunk struct {
+	Data string `json:"data"`
+	// Delay is the time between the previous chunk, or the response headers,
+	// and this chunk.
+	Delay time.Duration `json:"delay,omitempty"`
+}
+
+// Body returns the complete recorded response body.
+func (i Interaction) Body() string {
+	var sb strings.Builder
+	for _, chunk := range i.Chunks {
+		sb.WriteString(chunk.Data)
+	}
+	return sb.String()
+}
+
+// RecordingTransport is an http.RoundTripper that records the interactions
+// it passes to the underlying transport, for later replay with
+// ReplayTransport. Use it as the Transport of ClientConfig.HTTPClient.
+//
+// Request headers are not recorded, so fixtures never contain API keys.
+type RecordingTransport struct {
+	// Transport sends the requests. It defaults to http.DefaultTransport.
+	Transport http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []*Interaction
+}
+
+// NewRecordingTransport returns a RecordingTransport sending requests with
+// transport.
+func NewRecordingTransport(transport http.RoundTripper) *RecordingTransport {
+	return &RecordingTransport{Transport: transport}
+}
+
+func (t *RecordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	interaction := &Interaction{Method: req.Method, URL: req.URL.String()}
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		requestBody, err := io.ReadAll(body)
+		body.Close()
+		if err != nil {
+			return nil, err
+		}
+		interaction.RequestBody = string(requestBody)
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	interaction.StatusCode = resp.StatusCode
+	interaction.Header = resp.Header.Clone()
+
+	t.mu.Lock()
+	t.interactions = append(t.interactions, interaction)
+	t.mu.Unlock()
+
+	resp.Body = &recordingBody{
+		ReadCloser:  resp.Body,
+		transport:   t,
+		interaction: interaction,
+		last:        time.Now(),
+	}
+	return resp, nil
+}
+
+// Interactions returns copies of the interactions recorded so far.
+func (t *RecordingTransport) Interactions() []Interaction {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	interactions := make([]Interaction, len(t.interactions))
+	for i, interaction := range t.interactions {
+		interactions[i] = *interaction
+		interactions[i].Chunks = append([]RecordedChunk(nil), interaction.Chunks...)
+	}
+	return interactions
+}
+
+// Save writes the interactions recorded so far to a JSON file at path.
+func (t *RecordingTransport) Save(path string) error {
+	data, err := json.MarshalIndent(t.Interactions(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644) //nolint:gosec // fixtures are not secret
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	transport   *RecordingTransport
+	interaction *Interaction
+	last        time.Time
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if n > 0 {
+		now := time.Now()
+		b.transport.mu.Lock()
+		b.interaction.Chunks = append(b.interaction.Chunks, RecordedChunk{
+			Data:  string(p[:n]),
+			Delay: now.Sub(b.last),
+		})
+		b.transport.mu.Unlock()
+		b.last = now
+	}
+	return n, err
+}
+
+// ReplayTransport is an http.RoundTripper that serves recorded interactions
+// back in the order they were recorded, without network access. Use it as the
+// Transport of ClientConfig.HTTPClient in tests.
+type ReplayTransport struct {
+	// Timing replays the delays between the recorded chunks.
+	Timing bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	next         int
+}
+
+// NewReplayTransport returns a ReplayTransport serving interactions.
+func NewReplayTransport(interactions []Interaction) *ReplayTransport {
+	return &ReplayTransport{interactions: interactions}
+}
+
+// LoadReplayTransport returns a ReplayTransport serving the interactions
+// saved to path by RecordingTransport.Save.
+func LoadReplayTransport(path string) (*ReplayTransport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var interactions []Interaction
+	if err = json.Unmarshal(data, &interactions); err != nil {
+		return nil, err
+	}
+	return NewReplayTransport(interactions), nil
+}
+
+// RoundTrip serves the next recorded interaction. It fails with
+// ErrReplayMismatch if the method or URL path of req differs from the
+// recording, and with ErrReplayExhausted once every interaction was served.
+func (t *ReplayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must close the request body, even on errors.
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	t.mu.Lock()
+	if t.next >= len(t.interactions) {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s", ErrReplayExhausted, req.Method, req.URL)
+	}
+	interaction := t.interactions[t.next]
+	t.next++
+	t.mu.Unlock()
+
+	if err := interaction.matches(req); err != nil {
+		return nil, err
+	}
+
+	var body io.ReadCloser
+	if t.Timing {
+		body = &replayBody{done: req.Context().Done(), chunks: interaction.Chunks}
+	} else {
+		body = io.NopCloser(strings.NewReader(interaction.Body()))
+	}
+	header := interaction.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:     fmt.Sprintf("%d %s", interaction.StatusCode, http.StatusText(interaction.StatusCode)),
+		StatusCode: interaction.StatusCode,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Body:       body,
+		Request:    req,
+	}, nil
+}
+
+func (i Interaction) matches(req *http.Request) error {
+	recorded, err := http.NewRequest(i.Method, i.URL, nil)
+	if err != nil {
+		return err
+	}
+	if req.Method != recorded.Method || req.URL.Path != recorded.URL.Path {
+		return fmt.Errorf("%w: got %s %s, recorded %s %s",
+			ErrReplayMismatch, req.Method, req.URL.Path, recorded.Method, recorded.URL.Path)
+	}
+	return nil
+}
+
+// replayBody serves recorded chunks after their recorded delay.
+type replayBody struct {
+	done    <-chan struct{}
+	chunks  []RecordedChunk
+	pending bytes.Reader
+}
+
+func (b *replayBody) Read(p []byte) (int, error) {
+	for b.pending.Len() == 0 {
+		if len(b.chunks) == 0 {
+			return 0, io.EOF
+		}
+		chunk := b.chunks[0]
+		b.chunks = b.chunks[1:]
+
+		timer := time.NewTimer(chunk.Delay)
+		select {
+		case <-timer.C:
+		case <-b.done:
+			timer.Stop()
+			return 0, context.Canceled
+		}
+		b.pending.Reset([]byte(chunk.Data))
+	}
+	return b.pending.Read(p)
+}
+
+func (b *replayBody) Close() error {
+	b.chunks = nil
+	return nil
+}
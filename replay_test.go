@@ -0,0 +1,137 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		if !req.Stream {
+			resBytes, _ := json.Marshal(ChatCompletionResponse{
+				ID:      "recorded",
+				Choices: []ChatCompletionChoice{{Message: ChatCompletionMessage{Content: "Hi"}}},
+			})
+			_, _ = w.Write(resBytes)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		for _, content := range []string{"Hello", " world"} {
+			_, _ = w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"))
+			flusher.Flush()
+			time.Sleep(10 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	recorder := NewRecordingTransport(nil)
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.HTTPClient = &http.Client{Transport: recorder}
+	runChatAndStream(t, NewClientWithConfig(config))
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	checks.NoError(t, recorder.Save(path), "Save error")
+
+	replay, err := LoadReplayTransport(path)
+	checks.NoError(t, err, "LoadReplayTransport error")
+	replay.Timing = true
+	config.BaseURL = "http://replay.invalid/v1"
+	config.HTTPClient = &http.Client{Transport: replay}
+	client := NewClientWithConfig(config)
+	runChatAndStream(t, client)
+
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.ErrorIs(t, err, ErrReplayExhausted, "expected ErrReplayExhausted")
+}
+
+func runChatAndStream(t *testing.T, client *Client) {
+	t.Helper()
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	response, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if response.ID != "recorded" || response.Choices[0].Message.Content != "Hi" {
+		t.Errorf("unexpected response: %+v", response)
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	for {
+		_, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "stream.Recv() failed")
+	}
+	msg, _ := stream.Accumulator().Message(0)
+	if msg.Content != "Hello world" {
+		t.Errorf("unexpected streamed content: %q", msg.Content)
+	}
+}
+
+func TestReplayTransportMismatch(t *testing.T) {
+	replay := NewReplayTransport([]Interaction{{Method: http.MethodGet, URL: "http://replay.invalid/v1/models"}})
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = "http://replay.invalid/v1"
+	config.HTTPClient = &http.Client{Transport: replay}
+	client := NewClientWithConfig(config)
+
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.ErrorIs(t, err, ErrReplayMismatch, "expected ErrReplayMismatch")
+}
+
+// closeRecorder records whether the request body was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestReplayTransportClosesRequestBody(t *testing.T) {
+	replay := NewReplayTransport([]Interaction{{Method: http.MethodGet, URL: "http://replay.invalid/v1/models"}})
+	for _, expected := range []error{ErrReplayMismatch, ErrReplayExhausted} {
+		body := &closeRecorder{Reader: strings.NewReader("{}")}
+		req, err := http.NewRequest(http.MethodPost, "http://replay.invalid/v1/chat/completions", body)
+		checks.NoError(t, err, "NewRequest error")
+		_, err = replay.RoundTrip(req)
+		checks.ErrorIs(t, err, expected, "unexpected RoundTrip error")
+		if !body.closed {
+			t.Errorf("request body was not closed on %v", expected)
+		}
+	}
+}
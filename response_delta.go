@@ -0,0 +1,105 @@
+package openai
+
+import "sort"
+
+// ApplyDelta merges a stream frame into the response in place, so that a
+// single response can be rendered incrementally while a stream arrives. The
+// content, refusal and reasoning deltas are appended to the message of the
+// choice with the same index, which is created when the index first appears;
+// roles and finish reasons are set, and tool call fragments are merged by
+// their index, which is kept in ToolCall.Index so that the response can be
+// stored and decoded between frames. Choices are kept ordered by index. The ID, model, creation
+// time and usage are taken from the frame when it carries them.
+//
+// ChatCompletionAccumulator reassembles streams without a response to
+// update, see ChatCompletionStream.Accumulator.
+func (r *ChatCompletionResponse) ApplyDelta(frame ChatCompletionStreamResponse) {
+	if frame.ID != "" {
+		r.ID = frame.ID
+	}
+	if r.Object == "" {
+		r.Object = "chat.completion"
+	}
+	if frame.Model != "" {
+		r.Model = frame.Model
+	}
+	if frame.Created != 0 {
+		r.Created = frame.Created
+	}
+	if frame.Usage != nil {
+		r.Usage = *frame.Usage
+	}
+
+	for _, streamed := range frame.Choices {
+		choice := r.choice(streamed.Index)
+		msg := &choice.Message
+		delta := streamed.Delta
+		if delta.Role != "" {
+			msg.Role = delta.Role
+		} else if msg.Role == "" {
+			msg.Role = ChatMessageRoleAssistant
+		}
+		msg.Content += delta.Content
+		msg.Refusal += delta.Refusal
+		msg.ReasoningContent += delta.ReasoningContent
+		msg.FunctionCall.Name += delta.FunctionCall.Name
+		msg.FunctionCall.Arguments += delta.FunctionCall.Arguments
+		for _, toolCall := range delta.ToolCalls {
+			mergeToolCallDelta(msg, toolCall)
+		}
+		if streamed.FinishReason != "" {
+			choice.FinishReason = streamed.FinishReason
+		}
+	}
+}
+
+// choice returns the choice with the given index, inserting it if needed.
+func (r *ChatCompletionResponse) choice(index int) *ChatCompletionChoice {
+	pos := sort.Search(len(r.Choices), func(i int) bool { return r.Choices[i].Index >= index })
+	if pos == len(r.Choices) || r.Choices[pos].Index != index {
+		r.Choices = append(r.Choices, ChatCompletionChoice{})
+		copy(r.Choices[pos+1:], r.Choices[pos:])
+		r.Choices[pos] = ChatCompletionChoice{Index: index}
+	}
+	return &r.Choices[pos]
+}
+
+// mergeToolCallDelta merges delta into the tool calls of msg. Indexed
+// fragments belong to the tool call with the same Index; fragments without
+// one continue the last tool call unless they carry a new ID.
+func mergeToolCallDelta(msg *ChatCompletionMessage, delta ToolCallDelta) {
+	pos := len(msg.ToolCalls) - 1
+	switch {
+	case delta.Index != nil:
+		pos = toolCallWithIndex(msg.ToolCalls, *delta.Index)
+		if pos < 0 {
+			index := *delta.Index
+			msg.ToolCalls = append(msg.ToolCalls, ToolCall{Index: &index})
+			pos = len(msg.ToolCalls) - 1
+		}
+	case pos < 0 || (delta.ID != "" && delta.ID != msg.ToolCalls[pos].ID):
+		msg.ToolCalls = append(msg.ToolCalls, ToolCall{})
+		pos = len(msg.ToolCalls) - 1
+	}
+
+	toolCall := &msg.ToolCalls[pos]
+	if delta.ID != "" {
+		toolCall.ID = delta.ID
+	}
+	if delta.Type != "" {
+		toolCall.Type = delta.Type
+	}
+	toolCall.Function.Name += delta.Function.Name
+	toolCall.Function.Arguments += delta.Function.Arguments
+}
+
+// toolCallWithIndex returns the position of the tool call streamed with
+// index, or -1.
+func toolCallWithIndex(toolCalls []ToolCall, index int) int {
+	for i, toolCall := range toolCalls {
+		if toolCall.Index != nil && *toolCall.Index == index {
+			return i
+		}
+	}
+	return -1
+}
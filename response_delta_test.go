@@ -0,0 +1,120 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestChatCompletionResponseApplyDelta(t *testing.T) {
+	frames := []ChatCompletionStreamResponse{
+		{ID: "chatcmpl-1", Model: GPT4o, Created: 1, Choices: []ChatCompletionStreamChoice{
+			{Index: 1, Delta: ChatCompletionStreamChoiceDelta{Role: ChatMessageRoleAssistant, Content: "Hi"}},
+		}},
+		{ID: "chatcmpl-1", Choices: []ChatCompletionStreamChoice{
+			{Index: 0, Delta: ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCallDelta{
+				{Index: intPtr(0), ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather"}},
+				{Index: intPtr(1), ID: "call_b", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_time"}},
+			}}},
+			{Index: 1, Delta: ChatCompletionStreamChoiceDelta{Content: " there"}},
+		}},
+		{Choices: []ChatCompletionStreamChoice{
+			{Index: 0, Delta: ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCallDelta{
+				{Index: intPtr(1), Function: FunctionCall{Arguments: "{}"}},
+				{Index: intPtr(0), Function: FunctionCall{Arguments: `{"city":`}},
+			}}},
+		}},
+		{Choices: []ChatCompletionStreamChoice{
+			{Index: 0, Delta: ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCallDelta{
+				{Index: intPtr(0), Function: FunctionCall{Arguments: `"Paris"}`}},
+			}}, FinishReason: FinishReasonToolCalls},
+			{Index: 1, FinishReason: FinishReasonStop},
+		}},
+		{Usage: &Usage{PromptTokens: 5, CompletionTokens: 7, TotalTokens: 12}},
+	}
+
+	var response ChatCompletionResponse
+	for i, frame := range frames {
+		response.ApplyDelta(frame)
+		if i == 0 && (len(response.Choices) != 1 || response.Choices[0].Index != 1) {
+			t.Fatalf("expected a lazily created choice 1, got %+v", response.Choices)
+		}
+	}
+
+	if response.ID != "chatcmpl-1" || response.Model != GPT4o || response.Created != 1 ||
+		response.Usage.TotalTokens != 12 {
+		t.Errorf("unexpected response fields %+v", response)
+	}
+	if len(response.Choices) != 2 || response.Choices[0].Index != 0 || response.Choices[1].Index != 1 {
+		t.Fatalf("expected choices ordered by index, got %+v", response.Choices)
+	}
+
+	tools := response.Choices[0]
+	if tools.FinishReason != FinishReasonToolCalls || tools.Message.Role != ChatMessageRoleAssistant ||
+		len(tools.Message.ToolCalls) != 2 {
+		t.Fatalf("unexpected tool call choice %+v", tools)
+	}
+	if call := tools.Message.ToolCalls[0]; call.ID != "call_a" || call.Function.Name != "get_weather" ||
+		call.Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("unexpected first tool call %+v", call)
+	}
+	if call := tools.Message.ToolCalls[1]; call.ID != "call_b" || call.Function.Arguments != "{}" {
+		t.Errorf("unexpected second tool call %+v", call)
+	}
+
+	text := response.Choices[1]
+	if text.Message.Content != "Hi there" || text.FinishReason != FinishReasonStop {
+		t.Errorf("unexpected text choice %+v", text)
+	}
+}
+
+func TestChatCompletionResponseApplyDeltaSparseToolCallIndex(t *testing.T) {
+	var response ChatCompletionResponse
+	for _, delta := range []ToolCallDelta{
+		{Index: intPtr(1 << 20), ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather"}},
+		{Index: intPtr(7), ID: "call_b", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_time"}},
+		{Index: intPtr(1 << 20), Function: FunctionCall{Arguments: "{}"}},
+	} {
+		response.ApplyDelta(ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{
+			{Delta: ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCallDelta{delta}}},
+		}})
+	}
+
+	calls := response.Choices[0].Message.ToolCalls
+	if len(calls) != 2 || calls[0].ID != "call_a" || calls[0].Function.Arguments != "{}" || calls[1].ID != "call_b" {
+		t.Errorf("expected one tool call per streamed index in order of appearance, got %+v", calls)
+	}
+}
+
+func TestChatCompletionResponseApplyDeltaAfterRoundTrip(t *testing.T) {
+	toolCallFrame := func(delta ToolCallDelta) ChatCompletionStreamResponse {
+		return ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{
+			{Delta: ChatCompletionStreamChoiceDelta{ToolCalls: []ToolCallDelta{delta}}},
+		}}
+	}
+
+	var response ChatCompletionResponse
+	response.ApplyDelta(toolCallFrame(ToolCallDelta{
+		Index: intPtr(3), ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather"},
+	}))
+
+	data, err := json.Marshal(response)
+	checks.NoError(t, err, "Marshal error")
+	var decoded ChatCompletionResponse
+	checks.NoError(t, json.Unmarshal(data, &decoded), "Unmarshal error")
+	if !reflect.DeepEqual(decoded, response) {
+		t.Fatalf("decoded response differs:\n%+v\nexpected:\n%+v", decoded, response)
+	}
+
+	decoded.ApplyDelta(toolCallFrame(ToolCallDelta{Index: intPtr(3), Function: FunctionCall{Arguments: "{}"}}))
+	calls := decoded.Choices[0].Message.ToolCalls
+	if len(calls) != 1 || calls[0].ID != "call_a" || calls[0].Function.Arguments != "{}" {
+		t.Errorf("fragment was not merged into the decoded tool call: %+v", calls)
+	}
+	if len(response.Choices[0].Message.ToolCalls[0].Function.Arguments) != 0 {
+		t.Error("merging into the decoded response modified the original")
+	}
+}
@@ -0,0 +1,158 @@
+package openai
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ResponseDiff describes how a chat completion response b differs from a.
+type ResponseDiff struct {
+	// Choices holds the choices that differ, ordered by index.
+	Choices []ChoiceDiff
+	// Usage is the usage of b minus the usage of a.
+	Usage UsageDelta
+}
+
+// ChoiceDiff describes how a choice of response b differs from the choice
+// with the same index of response a.
+type ChoiceDiff struct {
+	Index int
+	// MissingInA and MissingInB report that only one response has the choice.
+	MissingInA bool
+	MissingInB bool
+
+	ContentChanged bool
+	ContentA       string
+	ContentB       string
+
+	FinishReasonChanged bool
+	FinishReasonA       FinishReason
+	FinishReasonB       FinishReason
+
+	// ToolCallsChanged reports different tool calls, compared by function
+	// name and arguments.
+	ToolCallsChanged bool
+}
+
+// UsageDelta is the difference of token counts between two responses.
+type UsageDelta struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Equal reports whether the choices of both responses are the same.
+// Differences in usage alone don't count.
+func (d ResponseDiff) Equal() bool {
+	return len(d.Choices) == 0
+}
+
+// String renders the differences in a form suited for test failures.
+func (d ResponseDiff) String() string {
+	var sb strings.Builder
+	for _, c := range d.Choices {
+		switch {
+		case c.MissingInA:
+			fmt.Fprintf(&sb, "choice %d: only in b\n", c.Index)
+			continue
+		case c.MissingInB:
+			fmt.Fprintf(&sb, "choice %d: only in a\n", c.Index)
+			continue
+		}
+		if c.ContentChanged {
+			fmt.Fprintf(&sb, "choice %d: content %q -> %q\n", c.Index, c.ContentA, c.ContentB)
+		}
+		if c.FinishReasonChanged {
+			fmt.Fprintf(&sb, "choice %d: finish reason %q -> %q\n", c.Index, c.FinishReasonA, c.FinishReasonB)
+		}
+		if c.ToolCallsChanged {
+			fmt.Fprintf(&sb, "choice %d: tool calls differ\n", c.Index)
+		}
+	}
+	if d.Usage != (UsageDelta{}) {
+		fmt.Fprintf(&sb, "usage: prompt %+d, completion %+d, total %+d\n",
+			d.Usage.PromptTokens, d.Usage.CompletionTokens, d.Usage.TotalTokens)
+	}
+	return sb.String()
+}
+
+// DiffOption configures DiffResponses.
+type DiffOption func(*diffOptions)
+
+type diffOptions struct {
+	normalizeWhitespace bool
+}
+
+// WithNormalizedWhitespace compares contents with leading and trailing
+// whitespace removed and inner runs of whitespace collapsed to one space.
+func WithNormalizedWhitespace() DiffOption {
+	return func(o *diffOptions) {
+		o.normalizeWhitespace = true
+	}
+}
+
+// DiffResponses compares the choices of two responses by index, e.g. for
+// prompt regression tests, and reports content, finish reason and tool call
+// changes along with the usage delta.
+func DiffResponses(a, b ChatCompletionResponse, opts ...DiffOption) ResponseDiff {
+	options := &diffOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	diff := ResponseDiff{Usage: UsageDelta{
+		PromptTokens:     b.Usage.PromptTokens - a.Usage.PromptTokens,
+		CompletionTokens: b.Usage.CompletionTokens - a.Usage.CompletionTokens,
+		TotalTokens:      b.Usage.TotalTokens - a.Usage.TotalTokens,
+	}}
+
+	choicesA, choicesB := sortedChoices(a.Choices), sortedChoices(b.Choices)
+	i, j := 0, 0
+	for i < len(choicesA) || j < len(choicesB) {
+		switch {
+		case j >= len(choicesB) || (i < len(choicesA) && choicesA[i].Index < choicesB[j].Index):
+			diff.Choices = append(diff.Choices, ChoiceDiff{Index: choicesA[i].Index, MissingInB: true})
+			i++
+		case i >= len(choicesA) || choicesB[j].Index < choicesA[i].Index:
+			diff.Choices = append(diff.Choices, ChoiceDiff{Index: choicesB[j].Index, MissingInA: true})
+			j++
+		default:
+			if c, changed := diffChoice(choicesA[i], choicesB[j], options); changed {
+				diff.Choices = append(diff.Choices, c)
+			}
+			i++
+			j++
+		}
+	}
+	return diff
+}
+
+func diffChoice(a, b ChatCompletionChoice, options *diffOptions) (ChoiceDiff, bool) {
+	contentA, contentB := a.Message.Content, b.Message.Content
+	if options.normalizeWhitespace {
+		contentA, contentB = strings.Join(strings.Fields(contentA), " "), strings.Join(strings.Fields(contentB), " ")
+	}
+
+	c := ChoiceDiff{
+		Index:               a.Index,
+		ContentChanged:      contentA != contentB,
+		ContentA:            a.Message.Content,
+		ContentB:            b.Message.Content,
+		FinishReasonChanged: a.FinishReason != b.FinishReason,
+		FinishReasonA:       a.FinishReason,
+		FinishReasonB:       b.FinishReason,
+	}
+	c.ToolCallsChanged = !reflect.DeepEqual(toolCallFunctions(a.Message.ToolCalls), toolCallFunctions(b.Message.ToolCalls))
+	return c, c.ContentChanged || c.FinishReasonChanged || c.ToolCallsChanged
+}
+
+// toolCallFunctions returns the functions of calls, leaving out their IDs,
+// which differ between responses.
+func toolCallFunctions(calls []ToolCall) []FunctionCall {
+	functions := make([]FunctionCall, len(calls))
+	for i, call := range calls {
+		functions[i] = call.Function
+	}
+	return functions
+}
@@ -0,0 +1,63 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"strings"
+	"testing"
+)
+
+func TestDiffResponses(t *testing.T) {
+	a := ChatCompletionResponse{
+		Choices: []ChatCompletionChoice{
+			{Index: 0, Message: ChatCompletionMessage{Content: "Paris is the capital."}, FinishReason: FinishReasonStop},
+			{Index: 1, Message: ChatCompletionMessage{Content: "It is Paris."}, FinishReason: FinishReasonStop},
+			{Index: 2, Message: ChatCompletionMessage{ToolCalls: []ToolCall{
+				{ID: "call_a", Function: FunctionCall{Name: "lookup", Arguments: `{"q":"france"}`}},
+			}}, FinishReason: FinishReasonToolCalls},
+		},
+		Usage: Usage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30},
+	}
+	b := ChatCompletionResponse{
+		Choices: []ChatCompletionChoice{
+			{Index: 2, Message: ChatCompletionMessage{ToolCalls: []ToolCall{
+				{ID: "call_b", Function: FunctionCall{Name: "lookup", Arguments: `{"q":"france"}`}},
+			}}, FinishReason: FinishReasonToolCalls},
+			{Index: 0, Message: ChatCompletionMessage{Content: " Paris  is the\ncapital. "}, FinishReason: FinishReasonStop},
+			{Index: 1, Message: ChatCompletionMessage{Content: "It is"}, FinishReason: FinishReasonLength},
+			{Index: 3, Message: ChatCompletionMessage{Content: "Paris."}, FinishReason: FinishReasonStop},
+		},
+		Usage: Usage{PromptTokens: 10, CompletionTokens: 25, TotalTokens: 35},
+	}
+
+	diff := DiffResponses(a, b)
+	if diff.Equal() || len(diff.Choices) != 3 {
+		t.Fatalf("expected 3 differing choices, got %+v", diff.Choices)
+	}
+	if c := diff.Choices[0]; c.Index != 0 || !c.ContentChanged || c.FinishReasonChanged {
+		t.Errorf("unexpected diff of choice 0: %+v", c)
+	}
+	if c := diff.Choices[1]; c.Index != 1 || !c.ContentChanged || !c.FinishReasonChanged ||
+		c.FinishReasonB != FinishReasonLength {
+		t.Errorf("unexpected diff of choice 1: %+v", c)
+	}
+	if c := diff.Choices[2]; c.Index != 3 || !c.MissingInA {
+		t.Errorf("unexpected diff of choice 3: %+v", c)
+	}
+	if diff.Usage != (UsageDelta{CompletionTokens: 5, TotalTokens: 5}) {
+		t.Errorf("unexpected usage delta: %+v", diff.Usage)
+	}
+	if s := diff.String(); !strings.Contains(s, `finish reason "stop" -> "length"`) ||
+		!strings.Contains(s, "completion +5") {
+		t.Errorf("unexpected rendering:\n%s", s)
+	}
+
+	diff = DiffResponses(a, b, WithNormalizedWhitespace())
+	if len(diff.Choices) != 2 || diff.Choices[0].Index != 1 {
+		t.Errorf("whitespace differences should be ignored, got %+v", diff.Choices)
+	}
+
+	if diff = DiffResponses(a, a); !diff.Equal() || diff.String() != "" {
+		t.Errorf("identical responses differ: %s", diff)
+	}
+}
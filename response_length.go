@@ -0,0 +1,69 @@
+package openai
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+)
+
+// responseLengthSample is an ordinary English paragraph whose token count
+// gives the average number of tokens per word and per character of a
+// tokenizer.
+const responseLengthSample = "The quick brown fox jumps over the lazy dog. " +
+	"Most people think about the length of a text in words or characters, " +
+	"while language models count tokens, which are often parts of words. " +
+	"A short answer might have a few sentences, and a detailed explanation " +
+	"could take several paragraphs covering background, examples and caveats."
+
+type responseLength struct {
+	n     int
+	words bool
+}
+
+// WithApproxWords sets MaxTokens to roughly the number of tokens that n words
+// of English text take with the tokenizer registered for the request's model,
+// see RegisterTokenizer. The estimate is approximate: it uses the tokenizer's
+// average tokens per word, so code, other languages or unusual formatting can
+// need more or fewer tokens, and the model is not asked to write n words. The
+// result is clamped to the tokens left in the model's context window, see
+// MaxResponseTokens, and overrides MaxTokens and MaxCompletionTokens of the
+// request.
+func WithApproxWords(n int) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.responseLength = responseLength{n: n, words: true}
+	}
+}
+
+// WithApproxChars is like WithApproxWords, estimating the tokens of n
+// characters.
+func WithApproxChars(n int) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.responseLength = responseLength{n: n}
+	}
+}
+
+// apply sets the token limit of request to the estimated tokens of the
+// response length.
+func (l responseLength) apply(request ChatCompletionRequest) ChatCompletionRequest {
+	if l.n <= 0 {
+		return request
+	}
+
+	tokens := float64(CountTokens(request.Model, responseLengthSample))
+	if l.words {
+		tokens /= float64(len(strings.Fields(responseLengthSample)))
+	} else {
+		tokens /= float64(utf8.RuneCountInString(responseLengthSample))
+	}
+	maxTokens := int(math.Ceil(tokens * float64(l.n)))
+	if maxTokens < 1 {
+		maxTokens = 1
+	}
+
+	request.MaxTokens, request.MaxCompletionTokens = 0, 0
+	if remaining, err := MaxResponseTokens(request, request.Model); err == nil && remaining < maxTokens {
+		maxTokens = remaining
+	}
+	request.MaxTokens = maxTokens
+	return request
+}
@@ -0,0 +1,58 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestApproxResponseLength(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var received ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		received, err = getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	// The word tokenizer counts one token per word.
+	RegisterTokenizer("approx-length-model", wordTokenizer{})
+	messages := []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Tell me a story."}}
+	request := ChatCompletionRequest{Model: "approx-length-model", Messages: messages, MaxCompletionTokens: 5}
+
+	_, err := client.CreateChatCompletion(context.Background(), request, WithApproxWords(200))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if received.MaxTokens != 200 || received.MaxCompletionTokens != 0 {
+		t.Errorf("expected 200 words to take 200 tokens, got max_tokens %d and max_completion_tokens %d",
+			received.MaxTokens, received.MaxCompletionTokens)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), request, WithApproxChars(1000))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if received.MaxTokens < 100 || received.MaxTokens > 250 {
+		t.Errorf("expected about 1000/6 tokens for 1000 characters, got %d", received.MaxTokens)
+	}
+
+	prompt := CountMessageTokens("approx-length-model", messages)
+	RegisterModelCapabilities("approx-length-model", ModelCapabilities{ContextWindow: prompt + 50})
+	_, err = client.CreateChatCompletion(context.Background(), request, WithApproxWords(200))
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if received.MaxTokens != 50 {
+		t.Errorf("expected the estimate to be clamped to the 50 remaining tokens, got %d", received.MaxTokens)
+	}
+
+	request.MaxTokens = 7
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if received.MaxTokens != 7 {
+		t.Errorf("expected MaxTokens to be kept without the options, got %d", received.MaxTokens)
+	}
+}
@@ -0,0 +1,46 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrResponseTooLarge is matched by errors returned when a response body
+// exceeds ClientConfig.MaxResponseBytes.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// limitResponseBody returns body limited to ClientConfig.MaxResponseBytes, or
+// body itself if no limit is configured.
+func (c *Client) limitResponseBody(body io.ReadCloser) io.ReadCloser {
+	if c.config.MaxResponseBytes <= 0 {
+		return body
+	}
+	return &limitedBody{ReadCloser: body, limit: c.config.MaxResponseBytes, remaining: c.config.MaxResponseBytes}
+}
+
+// limitedBody fails with ErrResponseTooLarge once more than limit bytes are
+// read from it.
+type limitedBody struct {
+	io.ReadCloser
+	limit     int64
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		// Only fail if the body actually continues past the limit.
+		var probe [1]byte
+		n, err := b.ReadCloser.Read(probe[:])
+		if n > 0 {
+			return 0, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, b.limit)
+		}
+		return 0, err
+	}
+	if int64(len(p)) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	return n, err
+}
@@ -0,0 +1,74 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMaxResponseBytes(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	body := `{"choices":[{"index":0,"message":{"role":"assistant","content":"` + strings.Repeat("a", 1000) + `"}}]}`
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			for i := 0; i < 100; i++ {
+				_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"aaaaaaaaaa"}}]}` + "\n\n"))
+			}
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	newClient := func(limit int64) *Client {
+		config := DefaultConfig(test.GetTestToken())
+		config.BaseURL = ts.URL + "/v1"
+		config.MaxResponseBytes = limit
+		return NewClientWithConfig(config)
+	}
+
+	_, err := newClient(100).CreateChatCompletion(context.Background(), req)
+	checks.ErrorIs(t, err, ErrResponseTooLarge, "expected ErrResponseTooLarge")
+
+	resp, err := newClient(int64(len(body))).CreateChatCompletion(context.Background(), req)
+	checks.NoError(t, err, "a body of exactly the limit should be read")
+	if len(resp.Choices) != 1 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+
+	_, err = newClient(0).CreateChatCompletion(context.Background(), req)
+	checks.NoError(t, err, "zero should mean no limit")
+
+	stream, err := newClient(1000).CreateChatCompletionStream(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	defer stream.Close()
+	frames := 0
+	for {
+		_, err = stream.Recv()
+		if err != nil {
+			break
+		}
+		frames++
+	}
+	if errors.Is(err, io.EOF) || !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("expected the stream to fail with ErrResponseTooLarge, got %v", err)
+	}
+	if frames == 0 || frames >= 100 {
+		t.Errorf("expected the frames within the limit to be read, got %d", frames)
+	}
+}
@@ -0,0 +1,229 @@
+package openai
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrSchemaViolation = errors.New("content does not match the JSON schema")
+	ErrInvalidSchema   = errors.New("invalid JSON schema")
+)
+
+// SchemaViolationError describes where content violates a JSON schema. It
+// matches ErrSchemaViolation with errors.Is.
+type SchemaViolationError struct {
+	// Path locates the violating value, e.g. "$.items[1].name".
+	Path    string
+	Message string
+}
+
+func (e *SchemaViolationError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrSchemaViolation, e.Path, e.Message)
+}
+
+func (e *SchemaViolationError) Is(target error) bool {
+	return target == ErrSchemaViolation
+}
+
+// ValidateAgainstSchema checks that content, e.g. the message of a response
+// requested with a JSON schema but without strict mode, is JSON matching
+// schema. It supports the type, enum, required, properties and items
+// keywords and ignores all others. The first violation is returned as a
+// *SchemaViolationError; properties are checked in alphabetical order.
+func ValidateAgainstSchema(content string, schema json.RawMessage) error {
+	var s validationSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	value, err := decodeJSONValue([]byte(content))
+	if err != nil {
+		return &SchemaViolationError{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+	return s.validate("$", value)
+}
+
+// validationSchema is the subset of JSON schema ValidateAgainstSchema
+// understands.
+type validationSchema struct {
+	Type       schemaTypes                  `json:"type"`
+	Enum       []json.RawMessage            `json:"enum"`
+	Required   []string                     `json:"required"`
+	Properties map[string]*validationSchema `json:"properties"`
+	Items      *validationSchema            `json:"items"`
+}
+
+// schemaTypes is the type keyword, which is either a type or a list of them.
+type schemaTypes []JSONSchemaType
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		return json.Unmarshal(data, (*[]JSONSchemaType)(t))
+	}
+	var single JSONSchemaType
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*t = schemaTypes{single}
+	return nil
+}
+
+func (s *validationSchema) validate(path string, value any) error {
+	if len(s.Type) > 0 && !s.Type.match(value) {
+		return &SchemaViolationError{Path: path, Message: fmt.Sprintf("expected %s, got %s",
+			s.Type, jsonTypeOf(value))}
+	}
+	if len(s.Enum) > 0 {
+		if err := s.validateEnum(path, value); err != nil {
+			return err
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &SchemaViolationError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := v[name]
+			if !ok || s.Properties[name] == nil {
+				continue
+			}
+			if err := s.Properties[name].validate(path+"."+name, property); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if s.Items == nil {
+			return nil
+		}
+		for i, item := range v {
+			if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *validationSchema) validateEnum(path string, value any) error {
+	allowed := make([]string, len(s.Enum))
+	for i, raw := range s.Enum {
+		option, err := decodeJSONValue(raw)
+		if err != nil {
+			return fmt.Errorf("%w: enum at %s: %v", ErrInvalidSchema, path, err)
+		}
+		if jsonValuesEqual(option, value) {
+			return nil
+		}
+		allowed[i] = string(raw)
+	}
+	return &SchemaViolationError{Path: path, Message: fmt.Sprintf("value is not one of %s",
+		strings.Join(allowed, ", "))}
+}
+
+func (t schemaTypes) match(value any) bool {
+	for _, typ := range t {
+		got := jsonTypeOf(value)
+		if got == typ {
+			return true
+		}
+		if n, ok := value.(json.Number); ok && typ == JSONSchemaTypeInteger {
+			if f, ok := new(big.Float).SetString(n.String()); ok && f.IsInt() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t schemaTypes) String() string {
+	names := make([]string, len(t))
+	for i, typ := range t {
+		names[i] = string(typ)
+	}
+	return strings.Join(names, " or ")
+}
+
+func jsonTypeOf(value any) JSONSchemaType {
+	switch value.(type) {
+	case nil:
+		return JSONSchemaTypeNull
+	case bool:
+		return JSONSchemaTypeBoolean
+	case json.Number:
+		return JSONSchemaTypeNumber
+	case string:
+		return JSONSchemaTypeString
+	case []any:
+		return JSONSchemaTypeArray
+	default:
+		return JSONSchemaTypeObject
+	}
+}
+
+// jsonValuesEqual compares decoded JSON values, treating numbers as equal if
+// their values are, e.g. 1 and 1.0.
+func jsonValuesEqual(a, b any) bool {
+	switch a := a.(type) {
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		x, okA := new(big.Float).SetString(a.String())
+		y, okB := new(big.Float).SetString(b.String())
+		return okA && okB && x.Cmp(y) == 0
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonValuesEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for name, value := range a {
+			other, ok := b[name]
+			if !ok || !jsonValuesEqual(value, other) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func decodeJSONValue(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
@@ -0,0 +1,71 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestValidateAgainstSchema(t *testing.T) {
+	schema := json.RawMessage(`{
+		"type": "object",
+		"required": ["name", "unit"],
+		"properties": {
+			"name": {"type": "string"},
+			"unit": {"type": "string", "enum": ["celsius", "fahrenheit"]},
+			"days": {"type": "integer"},
+			"note": {"type": ["string", "null"]},
+			"readings": {
+				"type": "array",
+				"items": {"type": "object", "required": ["value"], "properties": {"value": {"type": "number"}}}
+			}
+		}
+	}`)
+
+	tests := []struct {
+		name    string
+		content string
+		path    string
+	}{
+		{"valid", `{"name":"Paris","unit":"celsius","days":3.0,"note":null,"readings":[{"value":1.5}]}`, ""},
+		{"extra properties", `{"name":"Paris","unit":"celsius","extra":true}`, ""},
+		{"not an object", `[]`, "$"},
+		{"invalid JSON", `{"name":`, "$"},
+		{"trailing data", `{"name":"Paris","unit":"celsius"}}`, "$"},
+		{"trailing value", `{"name":"Paris","unit":"celsius"} {}`, "$"},
+		{"missing required", `{"name":"Paris"}`, "$"},
+		{"wrong type", `{"name":1,"unit":"celsius"}`, "$.name"},
+		{"not in enum", `{"name":"Paris","unit":"kelvin"}`, "$.unit"},
+		{"not an integer", `{"name":"Paris","unit":"celsius","days":1.5}`, "$.days"},
+		{"wrong item", `{"name":"Paris","unit":"celsius","readings":[{"value":1},{"value":"2"}]}`, "$.readings[1].value"},
+		{"first in alphabetical order", `{"name":1,"unit":"kelvin","days":"x"}`, "$.days"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAgainstSchema(tt.content, schema)
+			if tt.path == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrSchemaViolation) {
+				t.Fatalf("expected ErrSchemaViolation, got %v", err)
+			}
+			var violation *SchemaViolationError
+			if !errors.As(err, &violation) || violation.Path != tt.path {
+				t.Errorf("expected violation at %s, got %v", tt.path, err)
+			}
+		})
+	}
+}
+
+func TestValidateAgainstSchemaInvalidSchema(t *testing.T) {
+	err := ValidateAgainstSchema(`{}`, json.RawMessage(`{"type": 1}`))
+	if !errors.Is(err, ErrInvalidSchema) {
+		t.Errorf("expected ErrInvalidSchema, got %v", err)
+	}
+}
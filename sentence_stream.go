@@ -0,0 +1,133 @@
+package openai
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SentenceStream wraps a ChatCompletionStream and emits the content of the
+// first choice in complete sentences instead of raw deltas, e.g. to feed a
+// text-to-speech pipeline with fewer, more natural requests.
+//
+// A sentence ends at a newline, or at ".", "!" or "?" followed by whitespace,
+// including any closing quotes or brackets. Full-width terminators used in
+// CJK text end a sentence immediately. A period after a single letter or a
+// common abbreviation such as "Dr." does not end a sentence.
+type SentenceStream struct {
+	stream    *ChatCompletionStream
+	buffer    string
+	sentences []string
+	finished  bool
+}
+
+// NewSentenceStream returns a SentenceStream reading from stream.
+func NewSentenceStream(stream *ChatCompletionStream) *SentenceStream {
+	return &SentenceStream{stream: stream}
+}
+
+// Recv returns the next complete sentence. When the underlying stream ends,
+// the remaining partial sentence, if any, is returned before io.EOF.
+func (s *SentenceStream) Recv() (string, error) {
+	for len(s.sentences) == 0 {
+		if s.finished {
+			return "", io.EOF
+		}
+
+		response, err := s.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			s.finished = true
+			if rest := strings.TrimSpace(s.buffer); rest != "" {
+				s.sentences = append(s.sentences, rest)
+			}
+			s.buffer = ""
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+
+		for _, choice := range response.Choices {
+			if choice.Index == 0 {
+				s.buffer += choice.Delta.Content
+			}
+		}
+		s.splitSentences()
+	}
+
+	sentence := s.sentences[0]
+	s.sentences = s.sentences[1:]
+	return sentence, nil
+}
+
+// Close closes the underlying stream and returns the text received but not
+// yet returned by Recv.
+func (s *SentenceStream) Close() string {
+	s.stream.Close()
+	rest := strings.Join(append(s.sentences, strings.TrimSpace(s.buffer)), " ")
+	s.sentences = nil
+	s.buffer = ""
+	s.finished = true
+	return strings.TrimSpace(rest)
+}
+
+func (s *SentenceStream) splitSentences() {
+	for {
+		end := sentenceEnd(s.buffer)
+		if end < 0 {
+			return
+		}
+		if sentence := strings.TrimSpace(s.buffer[:end]); sentence != "" {
+			s.sentences = append(s.sentences, sentence)
+		}
+		s.buffer = s.buffer[end:]
+	}
+}
+
+// sentenceEnd returns the index just past the first complete sentence in
+// text, or -1 if text holds no complete sentence yet.
+func sentenceEnd(text string) int {
+	for i, r := range text {
+		switch r {
+		case '\n', '。', '！', '？':
+			return i + utf8.RuneLen(r)
+		case '.', '!', '?':
+			end := i + 1
+			next, size := utf8.DecodeRuneInString(text[end:])
+			for strings.ContainsRune(`"')]”’»`, next) {
+				end += size
+				next, size = utf8.DecodeRuneInString(text[end:])
+			}
+			if end >= len(text) {
+				// Whether the sentence ends depends on the next delta.
+				return -1
+			}
+			if !unicode.IsSpace(next) {
+				continue
+			}
+			if r == '.' && isAbbreviation(text[:i]) {
+				continue
+			}
+			return end
+		}
+	}
+	return -1
+}
+
+var sentenceAbbreviations = map[string]bool{
+	"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true, "sr": true, "jr": true,
+	"st": true, "vs": true, "e.g": true, "i.e": true,
+}
+
+// isAbbreviation reports whether text ends with a word that is usually
+// followed by a period without ending the sentence.
+func isAbbreviation(text string) bool {
+	word := text[strings.LastIndexFunc(text, unicode.IsSpace)+1:]
+	if utf8.RuneCountInString(word) == 1 {
+		// Initials such as "J. R. R. Tolkien".
+		return unicode.IsLetter([]rune(word)[0])
+	}
+	return sentenceAbbreviations[strings.ToLower(word)]
+}
@@ -0,0 +1,65 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSentenceStream(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		deltas := []string{
+			"Hello there", "! Dr. Smith paid $3", ".50 for J. R. R. Tolkien", "'s book.",
+			"\nIs it \"good?", "\" Yes", "今日は。晴れ", "です", " and the end",
+		}
+		for _, delta := range deltas {
+			frame, _ := json.Marshal(ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{
+				{Delta: ChatCompletionStreamChoiceDelta{Content: delta}},
+			}})
+			_, err := w.Write([]byte("data: " + string(frame) + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	sentences := NewSentenceStream(stream)
+	defer sentences.Close()
+
+	var got []string
+	for {
+		var sentence string
+		sentence, err = sentences.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "Recv failed")
+		got = append(got, sentence)
+	}
+
+	expected := []string{
+		"Hello there!",
+		"Dr. Smith paid $3.50 for J. R. R. Tolkien's book.",
+		`Is it "good?"`,
+		"Yes今日は。",
+		"晴れです and the end",
+	}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected sentences:\n%q\nexpected:\n%q", got, expected)
+	}
+}
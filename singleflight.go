@@ -0,0 +1,101 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+)
+
+// errFlightPanicked is returned to the callers waiting for a shared call
+// that panicked.
+var errFlightPanicked = errors.New("shared chat completion call panicked")
+
+// flightGroup lets concurrent calls with the same key share the result of a
+// single call.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done     chan struct{}
+	response ChatCompletionResponse
+	err      error
+}
+
+// do calls fn unless a call with key is in flight, in which case it waits for
+// that call and returns its result. Waiting ends early when ctx is done, but
+// the result of the shared call, including its cancellation, is that of the
+// first caller's context.
+func (g *flightGroup) do(
+	ctx context.Context,
+	key string,
+	fn func() (ChatCompletionResponse, error),
+) (ChatCompletionResponse, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return ChatCompletionResponse{}, ctx.Err()
+		}
+		return call.result()
+	}
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call := &flightCall{done: make(chan struct{}), err: errFlightPanicked}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	call.response, call.err = fn()
+	return call.result()
+}
+
+// result returns the result of the call with a copy of its choices, since
+// callers may modify the choices of their response.
+func (c *flightCall) result() (ChatCompletionResponse, error) {
+	return withCopiedChoices(c.response), c.err
+}
+
+// flightKey identifies a prepared chat completion request, the headers of its
+// options and the fields they omit. Unlike the default CacheKey, it includes
+// the user.
+func flightKey(request ChatCompletionRequest, options *chatCompletionOptions) string {
+	return request.CacheKey(WithCacheKeyExcludedFields()) + optionsKey(options)
+}
+
+// optionsKey identifies the headers of options and the fields they omit,
+// which change the response to a request.
+func optionsKey(options *chatCompletionOptions) string {
+	var key string
+	if len(options.header) > 0 {
+		// Maps are encoded with sorted keys.
+		header, _ := json.Marshal(options.header)
+		key += string(header)
+	}
+	if len(options.omitFields) > 0 {
+		fields := append([]string(nil), options.omitFields...)
+		sort.Strings(fields)
+		omitted, _ := json.Marshal(fields)
+		key += string(omitted)
+	}
+	return key
+}
+
+// withoutDeduplication sends the call even if an identical one is in flight,
+// for fan-outs whose concurrent requests are identical on purpose.
+func withoutDeduplication() ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.skipDeduplication = true
+	}
+}
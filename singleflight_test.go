@@ -0,0 +1,217 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDeduplicateRequests(t *testing.T) {
+	for _, fail := range []bool{false, true} {
+		server := test.NewTestServer()
+		var requests int32
+		release := make(chan struct{})
+		server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			<-release
+			if fail {
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"error":{"message":"boom","type":"server_error"}}`))
+				return
+			}
+			resBytes, _ := json.Marshal(ChatCompletionResponse{
+				ID:      "chatcmpl-1",
+				Choices: []ChatCompletionChoice{{Message: ChatCompletionMessage{Content: "Hi!"}}},
+			})
+			_, _ = w.Write(resBytes)
+		})
+		ts := server.OpenAITestServer()
+		ts.Start()
+
+		config := DefaultConfig(test.GetTestToken())
+		config.BaseURL = ts.URL + "/v1"
+		config.DeduplicateRequests = true
+		client := NewClientWithConfig(config)
+
+		request := ChatCompletionRequest{
+			Model:    GPT4o,
+			Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		}
+		const callers = 10
+		responses := make([]ChatCompletionResponse, callers)
+		errs := make([]error, callers)
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i], errs[i] = client.CreateChatCompletion(context.Background(), request)
+			}(i)
+		}
+		// Give the callers time to queue up behind the first request.
+		time.Sleep(100 * time.Millisecond)
+		close(release)
+		wg.Wait()
+		ts.Close()
+
+		if n := atomic.LoadInt32(&requests); n != 1 {
+			t.Errorf("expected 1 upstream request, got %d", n)
+		}
+		for i := 0; i < callers; i++ {
+			if fail {
+				var apiErr *APIError
+				if !errors.As(errs[i], &apiErr) || apiErr.HTTPStatusCode != http.StatusInternalServerError {
+					t.Errorf("caller %d: expected the shared API error, got %v", i, errs[i])
+				}
+				continue
+			}
+			checks.NoError(t, errs[i], "CreateChatCompletion error")
+			if responses[i].ID != "chatcmpl-1" {
+				t.Errorf("caller %d: unexpected response %+v", i, responses[i])
+			}
+		}
+	}
+}
+
+func TestDeduplicateRequestsFanOut(t *testing.T) {
+	server := test.NewTestServer()
+	var requests int32
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		n := atomic.AddInt32(&requests, 1)
+		// Keep the requests in flight together.
+		time.Sleep(50 * time.Millisecond)
+		resBytes, _ := json.Marshal(ChatCompletionResponse{
+			Choices: []ChatCompletionChoice{{Message: ChatCompletionMessage{Content: strconv.Itoa(int(n))}}},
+		})
+		_, _ = w.Write(resBytes)
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.DeduplicateRequests = true
+	client := NewClientWithConfig(config)
+
+	request := ChatCompletionRequest{
+		Model:    "dedup-fan-out-model",
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	_, err := client.CreateChatCompletionBest(context.Background(), request, 4,
+		func(ChatCompletionMessage) float64 { return 0 })
+	checks.NoError(t, err, "CreateChatCompletionBest error")
+	if n := atomic.SwapInt32(&requests, 0); n != 4 {
+		t.Errorf("expected every sample to be requested, got %d requests", n)
+	}
+
+	RegisterModelCapabilities("dedup-fan-out-model", ModelCapabilities{ContextWindow: 4096, MaxN: 1})
+	t.Cleanup(func() { UnregisterModelCapabilities("dedup-fan-out-model") })
+	request.N = 3
+	response, err := client.CreateChatCompletionSplitN(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletionSplitN error")
+	if n := atomic.LoadInt32(&requests); n != 3 || len(response.Choices) != 3 {
+		t.Errorf("expected every batch to be requested, got %d requests and %d choices", n, len(response.Choices))
+	}
+}
+
+func TestDeduplicateRequestsKey(t *testing.T) {
+	server := test.NewTestServer()
+	var requests int32
+	release := make(chan struct{})
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		<-release
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.DeduplicateRequests = true
+	client := NewClientWithConfig(config)
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	var wg sync.WaitGroup
+	for _, opts := range [][]ChatCompletionOption{nil, {OmitFields("user")}} {
+		wg.Add(1)
+		go func(opts []ChatCompletionOption) {
+			defer wg.Done()
+			_, err := client.CreateChatCompletion(context.Background(), request, opts...)
+			checks.NoError(t, err, "CreateChatCompletion error")
+		}(opts)
+	}
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("expected calls omitting different fields not to be shared, got %d requests", n)
+	}
+}
+
+func TestDeduplicateRequestsPanic(t *testing.T) {
+	queued := make(chan struct{})
+	config := DefaultConfig(test.GetTestToken())
+	config.DeduplicateRequests = true
+	config.TokenProvider = func(context.Context) (string, error) {
+		<-queued
+		panic("token provider failed")
+	}
+	client := NewClientWithConfig(config)
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	type outcome struct {
+		recovered any
+		err       error
+	}
+	outcomes := make(chan outcome, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			var o outcome
+			defer func() {
+				o.recovered = recover()
+				outcomes <- o
+			}()
+			_, o.err = client.CreateChatCompletion(context.Background(), request)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(queued)
+
+	panics, errs := 0, 0
+	for i := 0; i < 2; i++ {
+		select {
+		case o := <-outcomes:
+			if o.recovered != nil {
+				panics++
+			} else if o.err != nil {
+				errs++
+			}
+		case <-time.After(time.Second):
+			t.Fatal("waiter blocked after the shared call panicked")
+		}
+	}
+	if panics != 1 || errs != 1 {
+		t.Errorf("expected the caller running the call to panic and the waiter to fail, got %d panics, %d errors",
+			panics, errs)
+	}
+}
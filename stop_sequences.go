@@ -0,0 +1,171 @@
+package openai
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxStopSequences is the number of stop sequences the API accepts.
+const maxStopSequences = 4
+
+var stopSequenceEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// escapeStopSequences replaces line breaks and tabs in stop sequences with
+// their backslash escapes, see ClientConfig.EscapeStopSequences.
+func escapeStopSequences(stop []string) []string {
+	escaped := make([]string, len(stop))
+	for i, s := range stop {
+		escaped[i] = stopSequenceEscaper.Replace(s)
+	}
+	return escaped
+}
+
+// TrimStopSequence removes a stop sequence the provider leaked at the end of
+// the content of each choice. Choices that finished because of a stop
+// sequence also lose a trailing partial stop sequence, i.e. content ending
+// with the beginning of one of the sequences.
+func (r *ChatCompletionResponse) TrimStopSequence(stop ...string) {
+	for i := range r.Choices {
+		choice := &r.Choices[i]
+		choice.Message.Content = trimStopSequence(choice.Message.Content, stop,
+			choice.FinishReason == FinishReasonStop)
+	}
+}
+
+func trimStopSequence(content string, stop []string, trimPartial bool) string {
+	// Prefer the longest match, so "\n\n" wins over "\n".
+	longest := 0
+	for _, s := range stop {
+		if s == "" {
+			continue
+		}
+		if strings.HasSuffix(content, s) && len(s) > longest {
+			longest = len(s)
+			continue
+		}
+		if !trimPartial {
+			continue
+		}
+		for n := len(s) - 1; n > longest; n-- {
+			if strings.HasSuffix(content, s[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return content[:len(content)-longest]
+}
+
+// streamStopper enforces stop sequences on the client while streaming, see
+// WithClientSideStop.
+type streamStopper struct {
+	stop []string
+	// choices is the number of choices that must stop before the stream is
+	// finished.
+	choices int
+	states  map[int]*streamStopState
+	stopped int
+}
+
+type streamStopState struct {
+	// pending holds content that may be the beginning of a stop sequence and
+	// is withheld until the next delta decides it.
+	pending string
+	stopped bool
+}
+
+func newStreamStopper(stop []string, n int) *streamStopper {
+	if n < 1 {
+		n = 1
+	}
+	return &streamStopper{stop: stop, choices: n, states: make(map[int]*streamStopState)}
+}
+
+// apply trims the content deltas of response at the first stop sequence and
+// withholds content that may be the start of one. It reports whether every
+// choice has stopped.
+func (s *streamStopper) apply(response *ChatCompletionStreamResponse) bool {
+	choices := response.Choices[:0]
+	for _, choice := range response.Choices {
+		state, ok := s.states[choice.Index]
+		if !ok {
+			state = &streamStopState{}
+			s.states[choice.Index] = state
+		}
+		if state.stopped {
+			continue
+		}
+
+		content := state.pending + choice.Delta.Content
+		state.pending = ""
+		switch index := indexStopSequence(content, s.stop); {
+		case index >= 0:
+			content = content[:index]
+			choice.FinishReason = FinishReasonStop
+			state.stopped = true
+			s.stopped++
+		case choice.FinishReason == "":
+			hold := partialStopSuffix(content, s.stop)
+			state.pending = content[len(content)-hold:]
+			content = content[:len(content)-hold]
+		}
+		choice.Delta.Content = content
+		choices = append(choices, choice)
+	}
+	response.Choices = choices
+	return s.stopped >= s.choices
+}
+
+// flush returns a frame with the content still withheld from choices that
+// did not stop, for streams that end without a finish reason, and whether
+// there was any.
+func (s *streamStopper) flush() (ChatCompletionStreamResponse, bool) {
+	indexes := make([]int, 0, len(s.states))
+	for index, state := range s.states {
+		if state.pending != "" {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	var response ChatCompletionStreamResponse
+	for _, index := range indexes {
+		state := s.states[index]
+		response.Choices = append(response.Choices, ChatCompletionStreamChoice{
+			Index: index,
+			Delta: ChatCompletionStreamChoiceDelta{Content: state.pending},
+		})
+		state.pending = ""
+	}
+	return response, len(response.Choices) > 0
+}
+
+// indexStopSequence returns the index of the earliest stop sequence in
+// content, or -1.
+func indexStopSequence(content string, stop []string) int {
+	first := -1
+	for _, s := range stop {
+		if s == "" {
+			continue
+		}
+		if i := strings.Index(content, s); i >= 0 && (first < 0 || i < first) {
+			first = i
+		}
+	}
+	return first
+}
+
+// partialStopSuffix returns the length of the longest suffix of content that
+// is the beginning of a stop sequence.
+func partialStopSuffix(content string, stop []string) int {
+	longest := 0
+	for _, s := range stop {
+		for n := len(s) - 1; n > longest; n-- {
+			if strings.HasSuffix(content, s[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
@@ -0,0 +1,174 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestChatCompletionRequestValidateStop(t *testing.T) {
+	checks.NoError(t, ChatCompletionRequest{Stop: []string{"a", "b", "c", "d"}}.Validate(), "valid stop sequences")
+
+	err := ChatCompletionRequest{Stop: []string{"a", "b", "c", "d", "e"}}.Validate()
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "too many stop sequences should be rejected")
+
+	err = ChatCompletionRequest{Stop: []string{"a", ""}}.Validate()
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "empty stop sequence should be rejected")
+
+	client := NewClient("token")
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model: GPT3Dot5Turbo,
+		Stop:  []string{""},
+	})
+	checks.ErrorIs(t, err, ErrInvalidChatCompletionRequest, "CreateChatCompletion should validate the request")
+}
+
+func TestTrimStopSequence(t *testing.T) {
+	resp := ChatCompletionResponse{Choices: []ChatCompletionChoice{
+		{Message: ChatCompletionMessage{Content: "Answer.\n\nEND"}, FinishReason: FinishReasonStop},
+		{Message: ChatCompletionMessage{Content: "Answer.\n\nEN"}, FinishReason: FinishReasonStop},
+		{Message: ChatCompletionMessage{Content: "Answer.\n\nEN"}, FinishReason: FinishReasonLength},
+		{Message: ChatCompletionMessage{Content: "Answer."}, FinishReason: FinishReasonStop},
+	}}
+	resp.TrimStopSequence("\n\nEND", "###")
+
+	expected := []string{"Answer.", "Answer.", "Answer.\n\nEN", "Answer."}
+	for i, content := range expected {
+		if got := resp.Choices[i].Message.Content; got != content {
+			t.Errorf("choice %d: expected %q, got %q", i, content, got)
+		}
+	}
+}
+
+func TestEscapeStopSequences(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var stop []string
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		stop = req.Stop
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.EscapeStopSequences = true
+	client := NewClientWithConfig(config)
+
+	original := []string{"\n\n", "a\tb"}
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model: GPT3Dot5Turbo,
+		Stop:  original,
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(stop) != 2 || stop[0] != `\n\n` || stop[1] != `a\tb` {
+		t.Errorf("stop sequences were not escaped: %q", stop)
+	}
+	if original[0] != "\n\n" {
+		t.Error("caller's stop sequences were modified")
+	}
+}
+
+func TestCreateChatCompletionStreamClientSideStop(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"The answer", " is 4.\n", "\nEN", "D more text", " never sent"} {
+			_, err := w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"` +
+				strings.ReplaceAll(content, "\n", `\n`) + `"}}]}` + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		Stop:     []string{"\n\nEND"},
+	}, WithClientSideStop())
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	var deltas []string
+	var finishReason FinishReason
+	for {
+		var response ChatCompletionStreamResponse
+		response, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "stream.Recv() failed")
+		deltas = append(deltas, response.Choices[0].Delta.Content)
+		finishReason = response.Choices[0].FinishReason
+	}
+
+	expected := []string{"The answer", " is 4.", "", ""}
+	if strings.Join(deltas, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected deltas: %q", deltas)
+	}
+	if finishReason != FinishReasonStop {
+		t.Errorf("expected finish reason stop, got %q", finishReason)
+	}
+	msg, _ := stream.Accumulator().Message(0)
+	if msg.Content != "The answer is 4." {
+		t.Errorf("unexpected accumulated content: %q", msg.Content)
+	}
+}
+
+func TestCreateChatCompletionStreamClientSideStopFlushesOnEOF(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"The answer", " is 4.\n"} {
+			_, err := w.Write([]byte(`data: {"id":"1","choices":[{"index":0,"delta":{"content":"` +
+				strings.ReplaceAll(content, "\n", `\n`) + `"}}]}` + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+		Stop:     []string{"\n\nEND"},
+	}, WithClientSideStop())
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	var deltas []string
+	for {
+		var response ChatCompletionStreamResponse
+		response, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "stream.Recv() failed")
+		deltas = append(deltas, response.Choices[0].Delta.Content)
+	}
+
+	expected := []string{"The answer", " is 4.", "\n"}
+	if strings.Join(deltas, "|") != strings.Join(expected, "|") {
+		t.Errorf("unexpected deltas: %q", deltas)
+	}
+	msg, _ := stream.Accumulator().Message(0)
+	if msg.Content != "The answer is 4.\n" {
+		t.Errorf("withheld content was not flushed at the end of the stream: %q", msg.Content)
+	}
+}
@@ -4,12 +4,76 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
 
 var (
 	ErrTooManyEmptyStreamMessages = errors.New("stream has sent too many empty messages")
+	// ErrStreamEmptyLimitReached is matched by the *StreamEmptyLimitError a
+	// stream returns once ClientConfig.EmptyMessagesLimit is exceeded.
+	ErrStreamEmptyLimitReached = errors.New("stream empty message limit reached")
+)
+
+// StreamEmptyLimitError is returned by Recv when a stream sent more lines
+// without data than ClientConfig.EmptyMessagesLimit allows, which usually
+// points at a misbehaving proxy rather than a finished completion. It matches
+// both ErrStreamEmptyLimitReached and ErrTooManyEmptyStreamMessages.
+type StreamEmptyLimitError struct {
+	// EmptyMessages is the number of empty messages seen.
+	EmptyMessages uint
+}
+
+func (e *StreamEmptyLimitError) Error() string {
+	return fmt.Sprintf("%s: %d empty messages", ErrStreamEmptyLimitReached, e.EmptyMessages)
+}
+
+func (e *StreamEmptyLimitError) Is(target error) bool {
+	return target == ErrStreamEmptyLimitReached || target == ErrTooManyEmptyStreamMessages
+}
+
+// ErrMalformedStreamFrame is matched by the *StreamFrameError returned with
+// ClientConfig.StrictStreamParsing when a frame is not valid JSON.
+var ErrMalformedStreamFrame = errors.New("malformed stream frame")
+
+// StreamFrameError reports a stream frame that could not be decoded. It is
+// only returned with ClientConfig.StrictStreamParsing or InvalidUTF8Error and
+// matches ErrMalformedStreamFrame.
+type StreamFrameError struct {
+	// Line is the raw line of the frame.
+	Line string
+	Err  error
+}
+
+func (e *StreamFrameError) Error() string {
+	return fmt.Sprintf("%s: %v: %q", ErrMalformedStreamFrame, e.Err, e.Line)
+}
+
+func (e *StreamFrameError) Unwrap() error {
+	return e.Err
+}
+
+func (e *StreamFrameError) Is(target error) bool {
+	return target == ErrMalformedStreamFrame
+}
+
+// ErrInvalidUTF8 is matched by the *StreamFrameError returned with
+// InvalidUTF8Error when a stream frame is not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("invalid UTF-8")
+
+// InvalidUTF8Handling selects how streams treat frames that are not valid
+// UTF-8, e.g. because a proxy corrupted them.
+type InvalidUTF8Handling int
+
+const (
+	// InvalidUTF8Unchecked passes frames to the unmarshaler as they are. The
+	// JSON unmarshaler replaces invalid byte sequences in strings with
+	// U+FFFD.
+	InvalidUTF8Unchecked InvalidUTF8Handling = iota
+	// InvalidUTF8Error fails Recv with a *StreamFrameError wrapping
+	// ErrInvalidUTF8.
+	InvalidUTF8Error
 )
 
 type CompletionStream struct {
@@ -41,10 +105,11 @@ func (c *Client) CreateCompletionStream(
 		return
 	}
 
-	resp, err := c.config.HTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	resp, err := c.streamHTTPClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
 	if err != nil {
 		return
 	}
+	resp.Body = c.limitResponseBody(resp.Body)
 	if isFailureStatusCode(resp) {
 		return nil, c.handleErrorResp(resp)
 	}
@@ -56,6 +121,9 @@ func (c *Client) CreateCompletionStream(
 			response:           resp,
 			errAccumulator:     utils.NewErrorAccumulator(),
 			unmarshaler:        &utils.JSONUnmarshaler{},
+			parser:             c.streamParser(),
+			strict:             c.config.StrictStreamParsing,
+			invalidUTF8:        c.config.InvalidUTF8,
 		},
 	}
 	return
@@ -0,0 +1,47 @@
+package openai
+
+import (
+	"io"
+	"strings"
+)
+
+// ContentReader returns an io.Reader yielding the content of the first
+// choice as it streams, e.g. to feed a streamed completion into a parser.
+// Read blocks until more content arrives and returns io.EOF once the stream
+// ends; with WithTruncationError, a stream ending abruptly fails the read
+// with a *StreamTruncatedError instead. Any other error of the stream is
+// returned by Read as is. The read frames are also merged into the stream's
+// accumulator.
+func (stream *ChatCompletionStream) ContentReader() io.Reader {
+	return &streamContentReader{stream: stream}
+}
+
+type streamContentReader struct {
+	stream  *ChatCompletionStream
+	pending strings.Reader
+	err     error
+}
+
+func (r *streamContentReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for r.pending.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		response, err := r.stream.Recv()
+		if err != nil {
+			r.err = err
+			continue
+		}
+		var content strings.Builder
+		for _, choice := range response.Choices {
+			if choice.Index == 0 {
+				content.WriteString(choice.Delta.Content)
+			}
+		}
+		r.pending.Reset(content.String())
+	}
+	return r.pending.Read(p)
+}
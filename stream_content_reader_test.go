@@ -0,0 +1,68 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestCreateChatCompletionStreamContentReader(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	fail := false
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{`{\"name\":`, `\"Paris\",`, ``, `\"population\":2100000}`} {
+			_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"))
+		}
+		if fail {
+			_, _ = w.Write([]byte(`data: {"choices":[` + "\n\n"))
+			return
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+	req := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Describe Paris as JSON"}},
+	}
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	var city struct {
+		Name       string `json:"name"`
+		Population int    `json:"population"`
+	}
+	err = json.NewDecoder(stream.ContentReader()).Decode(&city)
+	stream.Close()
+	checks.NoError(t, err, "could not decode the streamed content")
+	if city.Name != "Paris" || city.Population != 2100000 {
+		t.Errorf("unexpected decoded content %+v", city)
+	}
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	content, err := io.ReadAll(stream.ContentReader())
+	stream.Close()
+	checks.NoError(t, err, "ReadAll error")
+	if string(content) != `{"name":"Paris","population":2100000}` {
+		t.Errorf("unexpected content %q", content)
+	}
+
+	fail = true
+	stream, err = client.CreateChatCompletionStream(context.Background(), req)
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+	content, err = io.ReadAll(stream.ContentReader())
+	if err == nil {
+		t.Error("expected a mid-stream error to surface from Read")
+	}
+	if string(content) != `{"name":"Paris","population":2100000}` {
+		t.Errorf("expected the content before the error, got %q", content)
+	}
+}
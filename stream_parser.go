@@ -0,0 +1,57 @@
+package openai
+
+import "bytes"
+
+// StreamLineType classifies a line of a server-sent event stream.
+type StreamLineType int
+
+const (
+	// StreamLineUnknown is a line that is not part of an event. It is kept
+	// in case the stream turns out to be an error response, and counts
+	// towards ClientConfig.EmptyMessagesLimit.
+	StreamLineUnknown StreamLineType = iota
+	// StreamLineData carries the JSON payload of a frame.
+	StreamLineData
+	// StreamLineDone marks the end of the stream.
+	StreamLineDone
+	// StreamLineSkip is a line that is part of the event framing but carries
+	// no payload, such as an event name. It is ignored.
+	StreamLineSkip
+)
+
+// StreamParser splits the lines of a streamed response into frames. Set
+// ClientConfig.StreamParser to adapt to OpenAI-compatible providers that
+// format their server-sent events differently.
+type StreamParser interface {
+	// ParseLine classifies a line, with surrounding whitespace removed, and
+	// returns the frame payload for StreamLineData lines.
+	ParseLine(line []byte) (data []byte, lineType StreamLineType)
+}
+
+// OpenAIStreamParser implements the OpenAI convention of "data: " prefixed
+// frames terminated by "data: [DONE]". It is used when no StreamParser is
+// configured.
+type OpenAIStreamParser struct{}
+
+var (
+	streamDataPrefix = []byte("data: ")
+	streamDoneData   = []byte("[DONE]")
+)
+
+func (OpenAIStreamParser) ParseLine(line []byte) ([]byte, StreamLineType) {
+	if !bytes.HasPrefix(line, streamDataPrefix) {
+		return nil, StreamLineUnknown
+	}
+	data := bytes.TrimPrefix(line, streamDataPrefix)
+	if bytes.Equal(data, streamDoneData) {
+		return nil, StreamLineDone
+	}
+	return data, StreamLineData
+}
+
+func (c *Client) streamParser() StreamParser {
+	if c.config.StreamParser != nil {
+		return c.config.StreamParser
+	}
+	return OpenAIStreamParser{}
+}
@@ -0,0 +1,90 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+// eventStreamParser is an example adapter for a provider that names its
+// events and ends the stream with "data: [END]" instead of "data: [DONE]".
+type eventStreamParser struct {
+	OpenAIStreamParser
+}
+
+func (p eventStreamParser) ParseLine(line []byte) ([]byte, StreamLineType) {
+	if bytes.HasPrefix(line, []byte("event:")) {
+		return nil, StreamLineSkip
+	}
+	if bytes.Equal(line, []byte("data: [END]")) {
+		return nil, StreamLineDone
+	}
+	return p.OpenAIStreamParser.ParseLine(line)
+}
+
+func TestOpenAIStreamParser(t *testing.T) {
+	var parser OpenAIStreamParser
+	data, lineType := parser.ParseLine([]byte(`data: {"id":"1"}`))
+	if lineType != StreamLineData || string(data) != `{"id":"1"}` {
+		t.Errorf("unexpected data line result: %q, %v", data, lineType)
+	}
+	if _, lineType = parser.ParseLine([]byte("data: [DONE]")); lineType != StreamLineDone {
+		t.Errorf("expected StreamLineDone, got %v", lineType)
+	}
+	if _, lineType = parser.ParseLine([]byte("")); lineType != StreamLineUnknown {
+		t.Errorf("expected StreamLineUnknown, got %v", lineType)
+	}
+}
+
+func TestCustomStreamParser(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, frame := range []string{
+			`{"id":"1","choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"content":" world"}}]}`,
+		} {
+			_, err := w.Write([]byte("event: message\ndata: " + frame + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [END]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.StreamParser = eventStreamParser{}
+	// Only the blank lines between events count as empty messages.
+	config.EmptyMessagesLimit = 1
+	client := NewClientWithConfig(config)
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	for {
+		_, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "stream.Recv() failed")
+	}
+	msg, _ := stream.Accumulator().Message(0)
+	if msg.Content != "Hello world" {
+		t.Errorf("unexpected content: %q", msg.Content)
+	}
+}
@@ -3,9 +3,11 @@ package openai
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"unicode/utf8"
 
 	utils "github.com/sashabaranov/go-openai/internal"
 )
@@ -22,6 +24,18 @@ type streamReader[T streamable] struct {
 	response       *http.Response
 	errAccumulator utils.ErrorAccumulator
 	unmarshaler    utils.Unmarshaler
+	parser         StreamParser
+	strict         bool
+	invalidUTF8    InvalidUTF8Handling
+}
+
+var errUnrecognizedDataLine = errors.New("data line not recognized by the stream parser")
+
+func (stream *streamReader[T]) lineParser() StreamParser {
+	if stream.parser == nil {
+		return OpenAIStreamParser{}
+	}
+	return stream.parser
 }
 
 func (stream *streamReader[T]) Recv() (response T, err error) {
@@ -47,30 +61,41 @@ func (stream *streamReader[T]) processLines() (T, error) {
 			return *new(T), readErr
 		}
 
-		var headerData = []byte("data: ")
 		noSpaceLine := bytes.TrimSpace(rawLine)
-		if !bytes.HasPrefix(noSpaceLine, headerData) {
+		data, lineType := stream.lineParser().ParseLine(noSpaceLine)
+		switch lineType {
+		case StreamLineData:
+		case StreamLineDone:
+			stream.isFinished = true
+			return *new(T), io.EOF
+		case StreamLineSkip:
+			continue
+		default:
+			if stream.strict && bytes.HasPrefix(noSpaceLine, []byte("data:")) {
+				return *new(T), &StreamFrameError{Line: string(noSpaceLine), Err: errUnrecognizedDataLine}
+			}
 			writeErr := stream.errAccumulator.Write(noSpaceLine)
 			if writeErr != nil {
 				return *new(T), writeErr
 			}
 			emptyMessagesCount++
 			if emptyMessagesCount > stream.emptyMessagesLimit {
-				return *new(T), ErrTooManyEmptyStreamMessages
+				return *new(T), &StreamEmptyLimitError{EmptyMessages: emptyMessagesCount}
 			}
 
 			continue
 		}
 
-		noPrefixLine := bytes.TrimPrefix(noSpaceLine, headerData)
-		if string(noPrefixLine) == "[DONE]" {
-			stream.isFinished = true
-			return *new(T), io.EOF
+		if stream.invalidUTF8 == InvalidUTF8Error && !utf8.Valid(data) {
+			return *new(T), &StreamFrameError{Line: string(noSpaceLine), Err: ErrInvalidUTF8}
 		}
 
 		var response T
-		unmarshalErr := stream.unmarshaler.Unmarshal(noPrefixLine, &response)
+		unmarshalErr := stream.unmarshaler.Unmarshal(data, &response)
 		if unmarshalErr != nil {
+			if stream.strict {
+				return *new(T), &StreamFrameError{Line: string(noSpaceLine), Err: unmarshalErr}
+			}
 			return *new(T), unmarshalErr
 		}
 
@@ -63,3 +63,93 @@ func TestStreamReaderReturnsErrTestErrorAccumulatorWriteFailed(t *testing.T) {
 	_, err := stream.Recv()
 	checks.ErrorIs(t, err, test.ErrTestErrorAccumulatorWriteFailed, "Did not return error when write failed", err.Error())
 }
+
+func TestStreamReaderReturnsErrStreamEmptyLimitReached(t *testing.T) {
+	stream := &streamReader[ChatCompletionStreamResponse]{
+		emptyMessagesLimit: 2,
+		reader:             bufio.NewReader(bytes.NewReader([]byte("\n\n\n\n"))),
+		errAccumulator:     utils.NewErrorAccumulator(),
+		unmarshaler:        &utils.JSONUnmarshaler{},
+	}
+	_, err := stream.Recv()
+	checks.ErrorIs(t, err, ErrStreamEmptyLimitReached, "Did not return ErrStreamEmptyLimitReached")
+
+	var limitErr *StreamEmptyLimitError
+	if !errors.As(err, &limitErr) {
+		t.Fatalf("Did not return StreamEmptyLimitError: %v", err)
+	}
+	if limitErr.EmptyMessages != 3 {
+		t.Errorf("Expected 3 empty messages, got %d", limitErr.EmptyMessages)
+	}
+
+	// A stream that ends cleanly is not reported as hitting the limit.
+	stream = &streamReader[ChatCompletionStreamResponse]{
+		emptyMessagesLimit: 2,
+		reader:             bufio.NewReader(bytes.NewReader([]byte("\ndata: [DONE]\n"))),
+		errAccumulator:     utils.NewErrorAccumulator(),
+		unmarshaler:        &utils.JSONUnmarshaler{},
+	}
+	_, err = stream.Recv()
+	checks.ErrorIsNot(t, err, ErrStreamEmptyLimitReached, "Clean end reported as empty limit")
+}
+
+func TestStreamReaderStrictParsing(t *testing.T) {
+	newStream := func(body string, strict bool) *streamReader[ChatCompletionStreamResponse] {
+		return &streamReader[ChatCompletionStreamResponse]{
+			emptyMessagesLimit: 300,
+			reader:             bufio.NewReader(bytes.NewReader([]byte(body))),
+			errAccumulator:     utils.NewErrorAccumulator(),
+			unmarshaler:        &utils.JSONUnmarshaler{},
+			strict:             strict,
+		}
+	}
+
+	for _, body := range []string{
+		"data: {\"id\": \n",
+		"data:{\"id\":\"1\"}\n\ndata: [DONE]\n",
+	} {
+		_, err := newStream(body, true).Recv()
+		checks.ErrorIs(t, err, ErrMalformedStreamFrame, "Strict parsing accepted malformed frame", body)
+
+		var frameErr *StreamFrameError
+		if !errors.As(err, &frameErr) {
+			t.Fatalf("Did not return StreamFrameError: %v", err)
+		}
+		if !bytes.HasPrefix([]byte(body), []byte(frameErr.Line)) {
+			t.Errorf("Expected raw line of %q, got %q", body, frameErr.Line)
+		}
+	}
+
+	_, err := newStream("data:{\"id\":\"1\"}\n\ndata: [DONE]\n", false).Recv()
+	checks.ErrorIsNot(t, err, ErrMalformedStreamFrame, "Lenient parsing rejected unknown line")
+
+	response, err := newStream("data: {\"id\":\"1\"}\n", true).Recv()
+	checks.NoError(t, err, "Strict parsing rejected valid frame")
+	if response.ID != "1" {
+		t.Errorf("Expected ID 1, got %q", response.ID)
+	}
+}
+
+func TestStreamReaderInvalidUTF8(t *testing.T) {
+	// "caf\xc3" is "café" with the second byte of "é" missing, and \xff
+	// never occurs in UTF-8.
+	body := "data: {\"choices\":[{\"delta\":{\"content\":\"caf\xc3 \xff!\"}}]}\n"
+	newStream := func(handling InvalidUTF8Handling) *streamReader[ChatCompletionStreamResponse] {
+		return &streamReader[ChatCompletionStreamResponse]{
+			reader:         bufio.NewReader(bytes.NewReader([]byte(body))),
+			errAccumulator: utils.NewErrorAccumulator(),
+			unmarshaler:    &utils.JSONUnmarshaler{},
+			invalidUTF8:    handling,
+		}
+	}
+
+	response, err := newStream(InvalidUTF8Unchecked).Recv()
+	checks.NoError(t, err, "Recv error")
+	if content := response.Choices[0].Delta.Content; content != "caf� �!" {
+		t.Errorf("Unexpected content %q", content)
+	}
+
+	_, err = newStream(InvalidUTF8Error).Recv()
+	checks.ErrorIs(t, err, ErrInvalidUTF8, "Did not return ErrInvalidUTF8")
+	checks.ErrorIs(t, err, ErrMalformedStreamFrame, "Did not return StreamFrameError")
+}
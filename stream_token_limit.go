@@ -0,0 +1,44 @@
+package openai
+
+// WithStreamTokenLimit closes a stream once the content of all its choices
+// together reaches maxTokens tokens, counted with the tokenizer registered
+// for the request's model, see RegisterTokenizer, for providers that ignore
+// MaxTokens. The frame reaching the limit is returned in full, with
+// FinishReasonLength set on the choices that had not finished, and Recv then
+// returns io.EOF; the generated content stays available from CollectAll,
+// Finish or the stream's Accumulator. The content of each frame is counted
+// as it arrives, so the total can differ slightly from the count of the
+// content as a whole. It has no effect on non-streaming calls.
+func WithStreamTokenLimit(maxTokens int) ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.streamTokenLimit = maxTokens
+	}
+}
+
+// enforceTokenLimit adds the tokens of response's content to the stream's
+// count and stops the stream once the count reached the token limit, marking
+// the unfinished choices of the accumulator and of response as cut off by the
+// length limit.
+func (stream *ChatCompletionStream) enforceTokenLimit(response *ChatCompletionStreamResponse) {
+	for _, choice := range response.Choices {
+		if choice.Delta.Content != "" {
+			stream.tokens += stream.tokenizer.CountTokens(choice.Delta.Content)
+		}
+	}
+	if stream.tokens < stream.tokenLimit {
+		return
+	}
+
+	for _, acc := range stream.accumulator.choices {
+		if acc.finishReason == "" {
+			acc.finishReason = FinishReasonLength
+		}
+	}
+	for i := range response.Choices {
+		if response.Choices[i].FinishReason == "" {
+			response.Choices[i].FinishReason = FinishReasonLength
+		}
+	}
+	stream.stopped = true
+	stream.Close()
+}
@@ -0,0 +1,101 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateChatCompletionStreamTokenLimit(t *testing.T) {
+	const model = "over-generating-model"
+	RegisterTokenizer(model, newWordEncoder())
+
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	aborted := make(chan struct{})
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher, _ := w.(http.Flusher)
+		for _, content := range []string{"one two", " three", " four five", " six"} {
+			_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"))
+			flusher.Flush()
+		}
+		select {
+		case <-r.Context().Done():
+			close(aborted)
+		case <-time.After(5 * time.Second):
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+		}
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    model,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Count"}},
+	}, WithStreamTokenLimit(4))
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	var frames []ChatCompletionStreamResponse
+	for {
+		response, recvErr := stream.Recv()
+		if errors.Is(recvErr, io.EOF) {
+			break
+		}
+		checks.NoError(t, recvErr, "stream.Recv() failed")
+		frames = append(frames, response)
+	}
+	if len(frames) != 3 || frames[2].Choices[0].FinishReason != FinishReasonLength {
+		t.Errorf("expected the stream to stop with the frame reaching the limit, got %+v", frames)
+	}
+
+	msg, reason, err := stream.Finish()
+	checks.NoError(t, err, "Finish error")
+	if msg.Content != "one two three four five" || reason != FinishReasonLength {
+		t.Errorf("unexpected result %q (%s)", msg.Content, reason)
+	}
+
+	select {
+	case <-aborted:
+	case <-time.After(time.Second):
+		t.Error("the request was not aborted")
+	}
+}
+
+func TestCreateChatCompletionStreamTokenLimitCountsDeltas(t *testing.T) {
+	const model = "delta-counting-model"
+	tokenizer := &countingTokenizer{}
+	RegisterTokenizer(model, tokenizer)
+
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"one two", " three", " four"} {
+			_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    model,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Count"}},
+	}, WithStreamTokenLimit(10))
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	response, err := stream.CollectAll()
+	checks.NoError(t, err, "CollectAll error")
+	if content := response.Choices[0].Message.Content; content != "one two three four" {
+		t.Errorf("unexpected content %q", content)
+	}
+	if strings.Join(tokenizer.texts, "|") != "one two| three| four" {
+		t.Errorf("expected only the deltas to be tokenized, got %q", tokenizer.texts)
+	}
+}
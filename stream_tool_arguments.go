@@ -0,0 +1,75 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var ErrToolCallIncomplete = errors.New("stream ended before the tool call arguments were complete")
+
+// StreamToolArguments reads stream in the background until the arguments of
+// the tool call at position index of the first choice form a complete JSON
+// value, decodes them into v and then delivers nil on the returned channel.
+// The arguments are re-checked as fragments arrive, so callers don't have to
+// deal with partial JSON. If the stream fails or ends first, the error is
+// delivered instead, ErrToolCallIncomplete if the stream ended cleanly. The
+// channel is closed after the single value.
+//
+// The stream must not be read by anyone else until the channel delivers; it
+// can be read on afterwards, e.g. for further tool calls. The returned
+// function stops reading and closes the stream; v is not touched after it
+// returns.
+func StreamToolArguments(stream *ChatCompletionStream, index int, v any) (<-chan error, func()) {
+	done := make(chan error, 1)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		done <- readToolArguments(stream, index, v, stop)
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			close(stop)
+			stream.Close()
+		})
+		wg.Wait()
+	}
+	return done, cancel
+}
+
+func readToolArguments(stream *ChatCompletionStream, index int, v any, stop <-chan struct{}) error {
+	for {
+		select {
+		case <-stop:
+			return context.Canceled
+		default:
+		}
+
+		_, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: tool call %d", ErrToolCallIncomplete, index)
+		}
+		if err != nil {
+			return err
+		}
+
+		msg, ok := stream.accumulator.Message(0)
+		if !ok || index >= len(msg.ToolCalls) {
+			continue
+		}
+		arguments := []byte(msg.ToolCalls[index].Function.Arguments)
+		if !json.Valid(arguments) {
+			continue
+		}
+		return json.Unmarshal(arguments, v)
+	}
+}
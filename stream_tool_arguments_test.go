@@ -0,0 +1,65 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"net/http"
+	"testing"
+)
+
+func TestStreamToolArguments(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		//nolint:lll
+		frames := []string{
+			`{"id":"1","choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_a","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":\"Par"}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"is\",\"days\":3}"}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{"tool_calls":[{"index":1,"id":"call_b","type":"function","function":{"name":"get_time","arguments":"{}"}}]}}]}`,
+			`{"id":"1","choices":[{"index":0,"delta":{},"finish_reason":"tool_calls"}]}`,
+		}
+		for _, frame := range frames {
+			_, err := w.Write([]byte("data: " + frame + "\n\n"))
+			checks.NoError(t, err, "Write error")
+		}
+		_, err := w.Write([]byte("data: [DONE]\n\n"))
+		checks.NoError(t, err, "Write error")
+	})
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Weather in Paris?"}},
+	}
+	stream, err := client.CreateChatCompletionStream(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	var args struct {
+		City string `json:"city"`
+		Days int    `json:"days"`
+	}
+	done, stop := StreamToolArguments(stream, 0, &args)
+	defer stop()
+	checks.NoError(t, <-done, "StreamToolArguments failed")
+	if args.City != "Paris" || args.Days != 3 {
+		t.Errorf("unexpected arguments: %+v", args)
+	}
+
+	// The stream can be read on once the arguments are complete.
+	response, err := stream.CollectAll()
+	checks.NoError(t, err, "CollectAll failed")
+	if calls := response.Choices[0].Message.ToolCalls; len(calls) != 2 {
+		t.Errorf("expected 2 tool calls, got %+v", calls)
+	}
+
+	stream, err = client.CreateChatCompletionStream(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+	done, stop = StreamToolArguments(stream, 2, &args)
+	defer stop()
+	checks.ErrorIs(t, <-done, ErrToolCallIncomplete, "expected ErrToolCallIncomplete for a missing tool call")
+}
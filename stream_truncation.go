@@ -0,0 +1,72 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrStreamTruncated is matched by the *StreamTruncatedError describing a
+// stream that ended without a finish reason for every choice.
+var ErrStreamTruncated = errors.New("stream ended without a finish reason")
+
+// StreamTruncatedError describes a chat completion stream that ended
+// abruptly, e.g. because the server or a proxy closed the connection, so that
+// its content may be cut off. It is returned by TruncationError and, with
+// WithTruncationError, by Recv instead of io.EOF. It matches
+// ErrStreamTruncated.
+type StreamTruncatedError struct {
+	// ReceivedDone reports whether the stream was terminated with [DONE]
+	// rather than by the connection closing.
+	ReceivedDone bool
+	// Unfinished holds the indexes of the choices that received no finish
+	// reason. It is empty if no choice was received at all.
+	Unfinished []int
+}
+
+func (e *StreamTruncatedError) Error() string {
+	if !e.ReceivedDone {
+		return fmt.Sprintf("%s: connection closed before [DONE], unfinished choices %v",
+			ErrStreamTruncated, e.Unfinished)
+	}
+	return fmt.Sprintf("%s: unfinished choices %v", ErrStreamTruncated, e.Unfinished)
+}
+
+func (e *StreamTruncatedError) Is(target error) bool {
+	return target == ErrStreamTruncated
+}
+
+// EndedCleanly reports whether the stream has ended with [DONE] after a
+// finish reason such as FinishReasonStop or FinishReasonLength was received
+// for every choice. It returns false while the stream is still being read and
+// for streams closed by the client, including those stopped by WithStopWhen.
+// Use it after Recv returned io.EOF to decide whether to retry or continue a
+// response.
+func (stream *ChatCompletionStream) EndedCleanly() bool {
+	return stream.ended && stream.truncation() == nil
+}
+
+// TruncationError returns a *StreamTruncatedError if the stream has ended
+// without ending cleanly, see EndedCleanly, and nil otherwise.
+func (stream *ChatCompletionStream) TruncationError() error {
+	if !stream.ended {
+		return nil
+	}
+	if err := stream.truncation(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (stream *ChatCompletionStream) truncation() *StreamTruncatedError {
+	indexes := stream.accumulator.indexes()
+	unfinished := make([]int, 0, len(indexes))
+	for _, index := range indexes {
+		if stream.accumulator.FinishReason(index) == "" {
+			unfinished = append(unfinished, index)
+		}
+	}
+	if stream.streamReader.isFinished && len(indexes) > 0 && len(unfinished) == 0 {
+		return nil
+	}
+	return &StreamTruncatedError{ReceivedDone: stream.streamReader.isFinished, Unfinished: unfinished}
+}
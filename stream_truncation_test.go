@@ -0,0 +1,124 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestChatCompletionStreamEndedCleanly(t *testing.T) {
+	tests := []struct {
+		name         string
+		frames       []string
+		clean        bool
+		receivedDone bool
+		unfinished   []int
+	}{
+		{
+			name: "finish reason and done",
+			frames: []string{
+				`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+				`data: {"choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+				`data: [DONE]`,
+			},
+			clean: true,
+		},
+		{
+			name: "length",
+			frames: []string{
+				`data: {"choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":"length"}]}`,
+				`data: [DONE]`,
+			},
+			clean: true,
+		},
+		{
+			name: "connection closed",
+			frames: []string{
+				`data: {"choices":[{"index":0,"delta":{"content":"Hello"}}]}`,
+			},
+			unfinished: []int{0},
+		},
+		{
+			name: "connection closed after finish reason",
+			frames: []string{
+				`data: {"choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":"stop"}]}`,
+			},
+			unfinished: []int{},
+		},
+		{
+			name: "done without finish reason",
+			frames: []string{
+				`data: {"choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":"stop"}]}`,
+				`data: {"choices":[{"index":1,"delta":{"content":"Hi"}}]}`,
+				`data: [DONE]`,
+			},
+			receivedDone: true,
+			unfinished:   []int{1},
+		},
+		{
+			name:         "no choices",
+			frames:       []string{`data: [DONE]`},
+			receivedDone: true,
+			unfinished:   []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, withError := range []bool{false, true} {
+				client, server, teardown := setupOpenAITestServer()
+				server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+					w.Header().Set("Content-Type", "text/event-stream")
+					for _, frame := range tt.frames {
+						_, _ = w.Write([]byte(frame + "\n\n"))
+					}
+				})
+
+				var opts []ChatCompletionOption
+				if withError {
+					opts = append(opts, WithTruncationError())
+				}
+				stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+					Model:    GPT4o,
+					Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+				}, opts...)
+				checks.NoError(t, err, "CreateChatCompletionStream returned error")
+				if stream.EndedCleanly() || stream.TruncationError() != nil {
+					t.Error("a stream that is still being read must not report how it ended")
+				}
+
+				_, err = stream.CollectAll()
+				stream.Close()
+				teardown()
+
+				if stream.EndedCleanly() != tt.clean {
+					t.Errorf("expected EndedCleanly %v", tt.clean)
+				}
+				truncationErr := stream.TruncationError()
+				if tt.clean {
+					checks.NoError(t, truncationErr, "unexpected truncation error")
+					checks.NoError(t, err, "CollectAll error")
+					continue
+				}
+
+				checks.ErrorIs(t, truncationErr, ErrStreamTruncated, "expected ErrStreamTruncated")
+				var truncated *StreamTruncatedError
+				if !errors.As(truncationErr, &truncated) {
+					t.Fatalf("expected a StreamTruncatedError, got %v", truncationErr)
+				}
+				if truncated.ReceivedDone != tt.receivedDone || len(truncated.Unfinished) != len(tt.unfinished) {
+					t.Errorf("unexpected truncation error %+v", truncated)
+				}
+				if withError {
+					checks.ErrorIs(t, err, ErrStreamTruncated, "expected Recv to return the truncation error")
+				} else {
+					checks.NoError(t, err, "CollectAll error")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package openai
+
+import "strings"
+
+// SystemPromptBuilder assembles a system prompt from reusable parts, so that
+// prompts composed across a code base share the same structure: the persona
+// first, followed by a "Guardrails" section listing every guardrail and an
+// "Output format" section. Empty parts and sections are left out. The zero
+// value is ready to use.
+type SystemPromptBuilder struct {
+	persona      string
+	guardrails   []string
+	outputFormat string
+}
+
+// NewSystemPromptBuilder returns an empty SystemPromptBuilder.
+func NewSystemPromptBuilder() *SystemPromptBuilder {
+	return &SystemPromptBuilder{}
+}
+
+// Persona sets the opening text describing who the assistant is, replacing
+// any previous persona.
+func (b *SystemPromptBuilder) Persona(text string) *SystemPromptBuilder {
+	b.persona = strings.TrimSpace(text)
+	return b
+}
+
+// Guardrail adds a rule the assistant must follow. Guardrails are listed in
+// the order they were added.
+func (b *SystemPromptBuilder) Guardrail(text string) *SystemPromptBuilder {
+	if text = strings.TrimSpace(text); text != "" {
+		b.guardrails = append(b.guardrails, text)
+	}
+	return b
+}
+
+// OutputFormat sets the description of how answers are formatted, replacing
+// any previous one.
+func (b *SystemPromptBuilder) OutputFormat(text string) *SystemPromptBuilder {
+	b.outputFormat = strings.TrimSpace(text)
+	return b
+}
+
+// String returns the assembled prompt.
+func (b *SystemPromptBuilder) String() string {
+	var sections []string
+	if b.persona != "" {
+		sections = append(sections, b.persona)
+	}
+	if len(b.guardrails) > 0 {
+		lines := make([]string, 0, len(b.guardrails)+1)
+		lines = append(lines, "## Guardrails")
+		for _, guardrail := range b.guardrails {
+			lines = append(lines, "- "+guardrail)
+		}
+		sections = append(sections, strings.Join(lines, "\n"))
+	}
+	if b.outputFormat != "" {
+		sections = append(sections, "## Output format\n"+b.outputFormat)
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// Build returns the assembled prompt as a system message.
+func (b *SystemPromptBuilder) Build() ChatCompletionMessage {
+	return ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: b.String()}
+}
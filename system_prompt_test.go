@@ -0,0 +1,33 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"testing"
+)
+
+func TestSystemPromptBuilder(t *testing.T) {
+	msg := NewSystemPromptBuilder().
+		Persona("You are a support agent for Acme. ").
+		Guardrail("Never share internal URLs.").
+		Guardrail("").
+		Guardrail("Escalate refund requests.").
+		OutputFormat("Answer in at most three sentences.").
+		Build()
+
+	expected := "You are a support agent for Acme.\n\n" +
+		"## Guardrails\n- Never share internal URLs.\n- Escalate refund requests.\n\n" +
+		"## Output format\nAnswer in at most three sentences."
+	if msg.Role != ChatMessageRoleSystem || msg.Content != expected {
+		t.Errorf("unexpected message %q: %q", msg.Role, msg.Content)
+	}
+
+	var b SystemPromptBuilder
+	b.Persona("first").Persona("second").OutputFormat("JSON")
+	if got := b.String(); got != "second\n\n## Output format\nJSON" {
+		t.Errorf("unexpected prompt without guardrails: %q", got)
+	}
+	if got := new(SystemPromptBuilder).Build().Content; got != "" {
+		t.Errorf("expected an empty prompt, got %q", got)
+	}
+}
@@ -0,0 +1,64 @@
+package openai
+
+import "sync"
+
+// TailBuffer keeps the most recent runes written to it in a ring of fixed
+// size, e.g. to run a detector over the latest output of a stream without
+// retaining the whole response. It is safe for concurrent use.
+type TailBuffer struct {
+	mu    sync.Mutex
+	runes []rune
+	// start is the position of the oldest rune, and full reports whether
+	// the ring has wrapped around.
+	start int
+	full  bool
+}
+
+// NewTailBuffer returns a TailBuffer keeping the last n runes.
+func NewTailBuffer(n int) *TailBuffer {
+	if n < 0 {
+		n = 0
+	}
+	return &TailBuffer{runes: make([]rune, 0, n)}
+}
+
+// WriteString appends the runes of s, dropping the oldest runes beyond the
+// buffer's size. Invalid UTF-8 is written as U+FFFD.
+func (b *TailBuffer) WriteString(s string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	size := cap(b.runes)
+	if size == 0 {
+		return
+	}
+	for _, r := range s {
+		if !b.full {
+			b.runes = append(b.runes, r)
+			b.full = len(b.runes) == size
+			continue
+		}
+		b.runes[b.start] = r
+		b.start = (b.start + 1) % size
+	}
+}
+
+// String returns the buffered runes, oldest first.
+func (b *TailBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	tail := make([]rune, 0, len(b.runes))
+	tail = append(tail, b.runes[b.start:]...)
+	tail = append(tail, b.runes[:b.start]...)
+	return string(tail)
+}
+
+// TailBuffer returns a TailBuffer of the last n runes of content of the
+// first choice, which Recv keeps up to date from the frames it receives
+// after the call.
+func (stream *ChatCompletionStream) TailBuffer(n int) *TailBuffer {
+	tail := NewTailBuffer(n)
+	stream.tails = append(stream.tails, tail)
+	return tail
+}
@@ -0,0 +1,65 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestTailBuffer(t *testing.T) {
+	tail := NewTailBuffer(4)
+	if tail.String() != "" {
+		t.Errorf("expected an empty buffer, got %q", tail.String())
+	}
+	tail.WriteString("ab")
+	if tail.String() != "ab" {
+		t.Errorf("expected %q, got %q", "ab", tail.String())
+	}
+	tail.WriteString("cdéf")
+	if tail.String() != "cdéf" {
+		t.Errorf("expected %q, got %q", "cdéf", tail.String())
+	}
+	tail.WriteString("日本語")
+	if tail.String() != "f日本語" {
+		t.Errorf("expected %q, got %q", "f日本語", tail.String())
+	}
+
+	NewTailBuffer(0).WriteString("ignored")
+}
+
+func TestChatCompletionStreamTailBuffer(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, content := range []string{"Grüße", " aus", " Köln"} {
+			_, _ = w.Write([]byte(`data: {"choices":[{"index":0,"delta":{"content":"` + content + `"}}]}` + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	stream, err := client.CreateChatCompletionStream(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletionStream returned error")
+	defer stream.Close()
+
+	tail := stream.TailBuffer(7)
+	expected := []string{"Grüße", "üße aus", "us Köln"}
+	for i := 0; ; i++ {
+		_, err = stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		checks.NoError(t, err, "Recv error")
+		if tail.String() != expected[i] {
+			t.Errorf("frame %d: expected tail %q, got %q", i, expected[i], tail.String())
+		}
+	}
+}
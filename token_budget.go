@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrBudgetExceeded = errors.New("token budget exceeded")
+
+// TokenBudget is a running budget of tokens shared by every request of a
+// client, see ClientConfig.TokenBudget. It is safe for concurrent use.
+type TokenBudget struct {
+	mu    sync.Mutex
+	limit int
+	spent int
+}
+
+// NewTokenBudget returns a budget of limit tokens.
+func NewTokenBudget(limit int) *TokenBudget {
+	return &TokenBudget{limit: limit}
+}
+
+// Spend deducts tokens from the budget.
+func (b *TokenBudget) Spend(tokens int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.spent += tokens
+}
+
+// Spent returns the number of tokens spent so far.
+func (b *TokenBudget) Spent() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.spent
+}
+
+// Remaining returns the number of tokens left, which is negative once the
+// last response overdrew the budget.
+func (b *TokenBudget) Remaining() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.limit - b.spent
+}
+
+// Check returns an error matching ErrBudgetExceeded if the budget is
+// exhausted.
+func (b *TokenBudget) Check() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.spent >= b.limit {
+		return fmt.Errorf("%w: %d of %d tokens spent", ErrBudgetExceeded, b.spent, b.limit)
+	}
+	return nil
+}
+
+// Reset sets the spent tokens back to zero.
+func (b *TokenBudget) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.spent = 0
+}
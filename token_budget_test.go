@@ -0,0 +1,69 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestTokenBudgetConcurrentSpend(t *testing.T) {
+	budget := NewTokenBudget(1000)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			budget.Spend(10)
+		}()
+	}
+	wg.Wait()
+	if budget.Spent() != 100 || budget.Remaining() != 900 {
+		t.Errorf("unexpected budget state: spent %d, remaining %d", budget.Spent(), budget.Remaining())
+	}
+	checks.NoError(t, budget.Check(), "budget should not be exhausted")
+
+	budget.Spend(900)
+	checks.ErrorIs(t, budget.Check(), ErrBudgetExceeded, "budget should be exhausted")
+	budget.Reset()
+	checks.NoError(t, budget.Check(), "budget should be reset")
+}
+
+func TestClientTokenBudget(t *testing.T) {
+	server := test.NewTestServer()
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	var requests int
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		resBytes, _ := json.Marshal(ChatCompletionResponse{Usage: Usage{TotalTokens: 60}})
+		_, _ = w.Write(resBytes)
+	})
+
+	budget := NewTokenBudget(100)
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.TokenBudget = budget
+	client := NewClientWithConfig(config)
+
+	request := ChatCompletionRequest{
+		Model:    GPT3Dot5Turbo,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	for i := 0; i < 2; i++ {
+		_, err := client.CreateChatCompletion(context.Background(), request)
+		checks.NoError(t, err, "request within budget should succeed")
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.ErrorIs(t, err, ErrBudgetExceeded, "request after the budget is exhausted should fail")
+	if requests != 2 || budget.Spent() != 120 {
+		t.Errorf("unexpected requests %d and spent tokens %d", requests, budget.Spent())
+	}
+}
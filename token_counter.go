@@ -0,0 +1,92 @@
+package openai
+
+// TokenCounter counts the prompt tokens of a growing conversation
+// incrementally. It caches the count of every message it has seen and only
+// tokenizes messages that were appended or changed since the previous call,
+// which keeps token accounting cheap for long sessions.
+//
+// A TokenCounter is not safe for concurrent use.
+type TokenCounter struct {
+	tokenizer Tokenizer
+	cached    []countedMessage
+	total     int
+}
+
+type countedMessage struct {
+	message ChatCompletionMessage
+	tokens  int
+}
+
+// NewTokenCounter returns a TokenCounter using the tokenizer registered for
+// model.
+func NewTokenCounter(model string) *TokenCounter {
+	return &TokenCounter{tokenizer: tokenizerForModel(model)}
+}
+
+// Count returns the prompt tokens of messages, like CountMessageTokens.
+// Messages matching the ones passed to the previous call are not tokenized
+// again; from the first message that was edited, removed or added on, the
+// counts are recomputed.
+func (c *TokenCounter) Count(messages []ChatCompletionMessage) int {
+	keep := 0
+	for keep < len(c.cached) && keep < len(messages) && sameMessage(c.cached[keep].message, messages[keep]) {
+		keep++
+	}
+
+	for _, counted := range c.cached[keep:] {
+		c.total -= counted.tokens
+	}
+	c.cached = c.cached[:keep]
+
+	for _, msg := range messages[keep:] {
+		tokens := countMessageTokens(c.tokenizer, msg)
+		c.cached = append(c.cached, countedMessage{message: copyMessage(msg), tokens: tokens})
+		c.total += tokens
+	}
+	return c.total + tokensPerReply
+}
+
+// Reset drops all cached counts.
+func (c *TokenCounter) Reset() {
+	c.cached = nil
+	c.total = 0
+}
+
+// copyMessage copies the slices of msg so that later edits to them in place
+// are detected.
+func copyMessage(msg ChatCompletionMessage) ChatCompletionMessage {
+	if msg.MultiContent != nil {
+		msg.MultiContent = append([]ChatMessagePart(nil), msg.MultiContent...)
+	}
+	if msg.ToolCalls != nil {
+		msg.ToolCalls = append([]ToolCall(nil), msg.ToolCalls...)
+	}
+	return msg
+}
+
+func sameMessage(a, b ChatCompletionMessage) bool {
+	if a.Role != b.Role || a.Content != b.Content || a.Refusal != b.Refusal ||
+		a.FunctionCall != b.FunctionCall || a.ReasoningContent != b.ReasoningContent ||
+		a.ToolCallID != b.ToolCallID || a.Name != b.Name ||
+		len(a.MultiContent) != len(b.MultiContent) || len(a.ToolCalls) != len(b.ToolCalls) {
+		return false
+	}
+	for i := range a.MultiContent {
+		if !sameMessagePart(a.MultiContent[i], b.MultiContent[i]) {
+			return false
+		}
+	}
+	for i := range a.ToolCalls {
+		if a.ToolCalls[i] != b.ToolCalls[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func sameMessagePart(a, b ChatMessagePart) bool {
+	if a.Type != b.Type || a.Text != b.Text || (a.ImageURL == nil) != (b.ImageURL == nil) {
+		return false
+	}
+	return a.ImageURL == nil || *a.ImageURL == *b.ImageURL
+}
@@ -0,0 +1,54 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"strings"
+	"testing"
+)
+
+type countingTokenizer struct {
+	calls int
+	texts []string
+}
+
+func (t *countingTokenizer) CountTokens(text string) int {
+	t.calls++
+	t.texts = append(t.texts, text)
+	return len(strings.Fields(text))
+}
+
+func TestTokenCounter(t *testing.T) {
+	tokenizer := &countingTokenizer{}
+	RegisterTokenizer("counting-model", tokenizer)
+	counter := NewTokenCounter("counting-model")
+
+	messages := []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "be brief"},
+		{Role: ChatMessageRoleUser, Content: "hi there"},
+	}
+	if got, expected := counter.Count(messages), CountMessageTokens("counting-model", messages); got != expected {
+		t.Fatalf("expected %d tokens, got %d", expected, got)
+	}
+
+	messages = append(messages, ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "hello"})
+	calls := tokenizer.calls
+	got := counter.Count(messages)
+	// Role, content and the empty function call fields of the new message.
+	if tokenizer.calls-calls != 4 {
+		t.Errorf("expected only the appended message to be tokenized, got %d calls", tokenizer.calls-calls)
+	}
+	if expected := CountMessageTokens("counting-model", messages); got != expected {
+		t.Errorf("expected %d tokens after appending, got %d", expected, got)
+	}
+
+	messages[0].Content = "be very brief please"
+	if got, expected := counter.Count(messages), CountMessageTokens("counting-model", messages); got != expected {
+		t.Errorf("expected %d tokens after editing, got %d", expected, got)
+	}
+
+	messages = messages[:1]
+	if got, expected := counter.Count(messages), CountMessageTokens("counting-model", messages); got != expected {
+		t.Errorf("expected %d tokens after removing messages, got %d", expected, got)
+	}
+}
@@ -0,0 +1,163 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+var (
+	ErrModelCapabilitiesUnknown   = errors.New("no capabilities registered for model")
+	ErrPromptExceedsContextWindow = errors.New("prompt does not fit into the context window")
+)
+
+// Tokenizer counts the tokens of a text for a model.
+type Tokenizer interface {
+	CountTokens(text string) int
+}
+
+// TokenEncoder is a Tokenizer that also converts between texts and token
+// IDs. Helpers that need token boundaries or IDs use it when the tokenizer
+// registered for a model implements it.
+type TokenEncoder interface {
+	Tokenizer
+	Encode(text string) []int
+	Decode(tokens []int) string
+}
+
+// ApproxTokenizer estimates token counts without a vocabulary, using the rule
+// of thumb that one token corresponds to about four bytes of English text.
+// Register an exact tokenizer with RegisterTokenizer where precision matters.
+type ApproxTokenizer struct{}
+
+const approxBytesPerToken = 4
+
+func (ApproxTokenizer) CountTokens(text string) int {
+	return (len(text) + approxBytesPerToken - 1) / approxBytesPerToken
+}
+
+// Per-message overheads of the chat format, see
+// https://github.com/openai/openai-cookbook/blob/main/examples/How_to_count_tokens_with_tiktoken.ipynb
+const (
+	tokensPerMessage = 3
+	tokensPerName    = 1
+	tokensPerReply   = 3
+)
+
+var (
+	tokenizersMu sync.RWMutex
+	tokenizers   = map[string]Tokenizer{}
+)
+
+// RegisterTokenizer registers the tokenizer used to count tokens for model.
+// Models without a registered tokenizer fall back to ApproxTokenizer.
+func RegisterTokenizer(model string, tokenizer Tokenizer) {
+	tokenizersMu.Lock()
+	defer tokenizersMu.Unlock()
+
+	tokenizers[model] = tokenizer
+}
+
+func tokenizerForModel(model string) Tokenizer {
+	tokenizersMu.RLock()
+	defer tokenizersMu.RUnlock()
+
+	if tokenizer, ok := tokenizers[model]; ok {
+		return tokenizer
+	}
+	return ApproxTokenizer{}
+}
+
+// CountTokens counts the tokens of text for model.
+func CountTokens(model, text string) int {
+	return tokenizerForModel(model).CountTokens(text)
+}
+
+// CountMessageTokens counts the prompt tokens of messages for model,
+// including the overhead the chat format adds per message and for priming
+// the reply.
+func CountMessageTokens(model string, messages []ChatCompletionMessage) int {
+	tokenizer := tokenizerForModel(model)
+	total := tokensPerReply
+	for _, msg := range messages {
+		total += countMessageTokens(tokenizer, msg)
+	}
+	return total
+}
+
+func countMessageTokens(tokenizer Tokenizer, msg ChatCompletionMessage) int {
+	count := tokensPerMessage + tokenizer.CountTokens(msg.Role) + tokenizer.CountTokens(msg.TextContent())
+	if msg.Name != "" {
+		count += tokensPerName + tokenizer.CountTokens(msg.Name)
+	}
+	count += tokenizer.CountTokens(msg.FunctionCall.Name) + tokenizer.CountTokens(string(msg.FunctionCall.Arguments))
+	for _, call := range msg.ToolCalls {
+		count += tokenizer.CountTokens(call.Function.Name) + tokenizer.CountTokens(string(call.Function.Arguments))
+	}
+	return count
+}
+
+// CountRequestTokens estimates the tokens a request consumes towards
+// token rate limits: the prompt tokens of its messages plus its token limit,
+// MaxCompletionTokens or MaxTokens.
+func CountRequestTokens(request ChatCompletionRequest) int {
+	return CountMessageTokens(request.Model, request.Messages) + request.completionTokenLimit()
+}
+
+// MaxResponseTokens returns how many tokens are left for the response to
+// request in the context window of model, capped by the model's maximum
+// output and by the token limit of request when set. The result can be used as
+// MaxTokens to let the response use as much of the window as fits.
+func MaxResponseTokens(request ChatCompletionRequest, model string) (int, error) {
+	capabilities, ok := GetModelCapabilities(model)
+	if !ok || capabilities.ContextWindow == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrModelCapabilitiesUnknown, model)
+	}
+
+	prompt := CountMessageTokens(model, request.Messages)
+	if prompt >= capabilities.ContextWindow {
+		return 0, fmt.Errorf("%w: %d prompt tokens, %d token context window",
+			ErrPromptExceedsContextWindow, prompt, capabilities.ContextWindow)
+	}
+
+	remaining := capabilities.ContextWindow - prompt
+	if capabilities.MaxOutputTokens > 0 && capabilities.MaxOutputTokens < remaining {
+		remaining = capabilities.MaxOutputTokens
+	}
+	if limit := request.completionTokenLimit(); limit > 0 && limit < remaining {
+		remaining = limit
+	}
+	return remaining, nil
+}
+
+// Image token costs of the vision models, see
+// https://platform.openai.com/docs/guides/vision/calculating-costs
+const (
+	imageBaseTokens    = 85
+	imageTileTokens    = 170
+	imageTileSize      = 512
+	imageMaxSide       = 2048
+	imageShortSideGoal = 768
+)
+
+// EstimateImageTokens estimates the prompt tokens of an image with the given
+// dimensions in pixels. Low detail images cost a flat amount. High detail
+// images are scaled to fit 2048x2048, then to 768 pixels on the short side,
+// and cost per 512 pixel tile. Auto or unset detail is estimated like high
+// detail, which is what the API picks for all but small images.
+func EstimateImageTokens(width, height int, detail ImageURLDetail) int {
+	if detail == ImageURLDetailLow || width <= 0 || height <= 0 {
+		return imageBaseTokens
+	}
+
+	w, h := float64(width), float64(height)
+	if longSide := math.Max(w, h); longSide > imageMaxSide {
+		w, h = w*imageMaxSide/longSide, h*imageMaxSide/longSide
+	}
+	if shortSide := math.Min(w, h); shortSide > imageShortSideGoal {
+		w, h = w*imageShortSideGoal/shortSide, h*imageShortSideGoal/shortSide
+	}
+	tiles := int(math.Ceil(w/imageTileSize)) * int(math.Ceil(h/imageTileSize))
+	return imageBaseTokens + tiles*imageTileTokens
+}
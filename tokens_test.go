@@ -0,0 +1,98 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"strings"
+	"testing"
+)
+
+type wordTokenizer struct{}
+
+func (wordTokenizer) CountTokens(text string) int {
+	return len(strings.Fields(text))
+}
+
+func TestCountTokens(t *testing.T) {
+	if got := CountTokens("unregistered-model", "12345678"); got != 2 {
+		t.Errorf("approximate tokenizer counted %d tokens, expected 2", got)
+	}
+
+	RegisterTokenizer("word-model", wordTokenizer{})
+	if got := CountTokens("word-model", "one two three"); got != 3 {
+		t.Errorf("registered tokenizer counted %d tokens, expected 3", got)
+	}
+
+	messages := []ChatCompletionMessage{
+		{Role: "system", Content: "be brief"},
+		{Role: "user", Content: "hi there", Name: "bob"},
+	}
+	// 3 for the reply, 3 per message, 1 for the name, plus the words.
+	if got := CountMessageTokens("word-model", messages); got != 3+(3+1+2)+(3+1+2+1+1) {
+		t.Errorf("unexpected message token count %d", got)
+	}
+
+	request := ChatCompletionRequest{Model: "word-model", Messages: messages, MaxTokens: 100}
+	if got := CountRequestTokens(request); got != CountMessageTokens("word-model", messages)+100 {
+		t.Errorf("unexpected request token count %d", got)
+	}
+}
+
+func TestMaxResponseTokens(t *testing.T) {
+	RegisterTokenizer("word-model", wordTokenizer{})
+	RegisterModelCapabilities("word-model", ModelCapabilities{ContextWindow: 100, MaxOutputTokens: 50})
+
+	messages := []ChatCompletionMessage{{Role: "user", Content: "one two three"}}
+	prompt := CountMessageTokens("word-model", messages)
+
+	request := ChatCompletionRequest{Messages: messages}
+	got, err := MaxResponseTokens(request, "word-model")
+	checks.NoError(t, err, "MaxResponseTokens error")
+	if got != 50 {
+		t.Errorf("expected the max output tokens to cap the result, got %d", got)
+	}
+
+	RegisterModelCapabilities("word-model", ModelCapabilities{ContextWindow: 100})
+	got, err = MaxResponseTokens(request, "word-model")
+	checks.NoError(t, err, "MaxResponseTokens error")
+	if got != 100-prompt {
+		t.Errorf("expected %d remaining tokens, got %d", 100-prompt, got)
+	}
+
+	request.MaxTokens = 10
+	got, err = MaxResponseTokens(request, "word-model")
+	checks.NoError(t, err, "MaxResponseTokens error")
+	if got != 10 {
+		t.Errorf("expected MaxTokens to cap the result, got %d", got)
+	}
+
+	RegisterModelCapabilities("word-model", ModelCapabilities{ContextWindow: prompt})
+	_, err = MaxResponseTokens(request, "word-model")
+	checks.ErrorIs(t, err, ErrPromptExceedsContextWindow, "prompt filling the window should be rejected")
+
+	_, err = MaxResponseTokens(request, "unregistered-model")
+	checks.ErrorIs(t, err, ErrModelCapabilitiesUnknown, "unknown model should be rejected")
+}
+
+func TestEstimateImageTokens(t *testing.T) {
+	tests := []struct {
+		width, height int
+		detail        ImageURLDetail
+		expected      int
+	}{
+		{4096, 4096, ImageURLDetailLow, 85},
+		// Scaled to 2048x2048, then 768x768: 4 tiles.
+		{4096, 4096, ImageURLDetailHigh, 85 + 4*170},
+		// Scaled to 1536x768: 6 tiles.
+		{2048, 1024, ImageURLDetailAuto, 85 + 6*170},
+		// Small images are not scaled up: 1 tile.
+		{300, 200, "", 85 + 170},
+	}
+	for _, tt := range tests {
+		if got := EstimateImageTokens(tt.width, tt.height, tt.detail); got != tt.expected {
+			t.Errorf("EstimateImageTokens(%d, %d, %q) = %d, expected %d",
+				tt.width, tt.height, tt.detail, got, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package openai
+
+import "fmt"
+
+// ToolExample is an example invocation of a tool, injected into a
+// conversation as few-shot messages to show the model how to call the tool.
+type ToolExample struct {
+	// Input is the user message the example answers.
+	Input string
+	// Arguments are the JSON arguments the tool is called with.
+	Arguments Arguments
+	// Result is the tool's result for the arguments.
+	Result string
+	// Output is the assistant answer after the result. It is optional.
+	Output string
+}
+
+// ToolOption configures a tool registered with RegisterTool.
+type ToolOption func(*registeredTool)
+
+// WithToolExamples attaches example invocations to a registered tool. They
+// are only sent with requests made with WithRegisteredToolExamples.
+func WithToolExamples(examples ...ToolExample) ToolOption {
+	return func(t *registeredTool) {
+		t.examples = append(t.examples, examples...)
+	}
+}
+
+// WithRegisteredToolExamples injects the examples of the tools registered
+// with RegisterTool into the call's messages, see AddToolExamples. It has no
+// effect together with WithoutRegisteredTools.
+func WithRegisteredToolExamples() ChatCompletionOption {
+	return func(o *chatCompletionOptions) {
+		o.registeredToolExamples = true
+	}
+}
+
+// AddToolExamples inserts the examples of the tool named name into the
+// messages of req, after the leading system messages and before the rest of
+// the conversation. Each example becomes a user message with the Input, an
+// assistant message calling the tool with the Arguments, a tool message with
+// the Result and, if set, an assistant message with the Output.
+func AddToolExamples(req *ChatCompletionRequest, name string, examples []ToolExample) {
+	if len(examples) == 0 {
+		return
+	}
+
+	pos := 0
+	for pos < len(req.Messages) && req.Messages[pos].Role == ChatMessageRoleSystem {
+		pos++
+	}
+	inserted := toolExampleMessages(name, examples)
+
+	messages := make([]ChatCompletionMessage, 0, len(req.Messages)+len(inserted))
+	messages = append(messages, req.Messages[:pos]...)
+	messages = append(messages, inserted...)
+	req.Messages = append(messages, req.Messages[pos:]...)
+}
+
+func toolExampleMessages(name string, examples []ToolExample) []ChatCompletionMessage {
+	messages := make([]ChatCompletionMessage, 0, 4*len(examples))
+	for i, example := range examples {
+		id := fmt.Sprintf("example_%s_%d", name, i+1)
+		messages = append(messages,
+			ChatCompletionMessage{Role: ChatMessageRoleUser, Content: example.Input},
+			ChatCompletionMessage{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{{
+				ID:       id,
+				Type:     ToolTypeFunction,
+				Function: FunctionCall{Name: name, Arguments: example.Arguments},
+			}}},
+			ChatCompletionMessage{Role: ChatMessageRoleTool, Content: example.Result, ToolCallID: id},
+		)
+		if example.Output != "" {
+			messages = append(messages, ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: example.Output})
+		}
+	}
+	return messages
+}
+
+// addExamplesTo inserts the examples of all registered tools into the
+// messages of req in registration order.
+func (r *toolRegistry) addExamplesTo(req *ChatCompletionRequest) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Inserting in reverse keeps the examples in registration order, as each
+	// insertion goes right after the system messages.
+	for i := len(r.names) - 1; i >= 0; i-- {
+		name := r.names[i]
+		AddToolExamples(req, name, r.tools[name].examples)
+	}
+}
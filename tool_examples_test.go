@@ -0,0 +1,88 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestAddToolExamples(t *testing.T) {
+	system := ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: "Be helpful."}
+	user := ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Weather in Oslo?"}
+	req := ChatCompletionRequest{Messages: []ChatCompletionMessage{system, user}}
+
+	AddToolExamples(&req, "get_weather", []ToolExample{
+		{Input: "Weather in Paris?", Arguments: `{"city":"Paris"}`, Result: `{"temperature":21}`, Output: "21°C."},
+		{Input: "Weather in Rome?", Arguments: `{"city":"Rome"}`, Result: `{"temperature":25}`},
+	})
+
+	messages := req.Messages
+	if len(messages) != 2+4+3 || messages[0].Content != system.Content ||
+		messages[len(messages)-1].Content != user.Content {
+		t.Fatalf("expected the examples between the system and user messages, got %+v", messages)
+	}
+	call := messages[2]
+	if call.Role != ChatMessageRoleAssistant || len(call.ToolCalls) != 1 ||
+		call.ToolCalls[0].Function.Name != "get_weather" || call.ToolCalls[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Fatalf("unexpected example tool call %+v", call)
+	}
+	result := messages[3]
+	if result.Role != ChatMessageRoleTool || result.ToolCallID != call.ToolCalls[0].ID ||
+		result.Content != `{"temperature":21}` {
+		t.Errorf("unexpected example tool result %+v", result)
+	}
+	if messages[4].Role != ChatMessageRoleAssistant || messages[4].Content != "21°C." {
+		t.Errorf("unexpected example output %+v", messages[4])
+	}
+	if messages[5].Content != "Weather in Rome?" || messages[7].Role != ChatMessageRoleTool {
+		t.Errorf("expected the second example without output, got %+v", messages[5:8])
+	}
+	if messages[6].ToolCalls[0].ID == call.ToolCalls[0].ID {
+		t.Error("expected distinct tool call IDs per example")
+	}
+}
+
+func TestRegisteredToolExamples(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var received []ChatCompletionMessage
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		received = req.Messages
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	example := ToolExample{Input: "Weather in Paris?", Arguments: `{"city":"Paris"}`, Result: `{"temperature":21}`}
+	err := client.RegisterTool("get_weather", "Get the weather", getWeather, WithToolExamples(example))
+	checks.NoError(t, err, "RegisterTool error")
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	_, err = client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(received) != 1 {
+		t.Errorf("examples must only be sent on request, got %+v", received)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), request, WithRegisteredToolExamples())
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(received) != 4 || received[0].Content != example.Input || received[3].Content != "Hello!" {
+		t.Errorf("expected the example before the conversation, got %+v", received)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), request,
+		WithRegisteredToolExamples(), WithoutRegisteredTools())
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(received) != 1 {
+		t.Errorf("examples must not be sent without the registered tools, got %+v", received)
+	}
+}
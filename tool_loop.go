@@ -0,0 +1,133 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxToolIterations is the number of chat completion requests
+// RunToolLoop sends at most unless configured otherwise.
+const DefaultMaxToolIterations = 10
+
+var ErrToolLoopMaxIterations = errors.New("tool loop did not finish within the maximum number of iterations")
+
+// ToolLoopOption configures RunToolLoop.
+type ToolLoopOption func(*toolLoopOptions)
+
+type toolLoopOptions struct {
+	maxIterations   int
+	chatOptions     []ChatCompletionOption
+	argumentRetries int
+}
+
+// WithMaxToolIterations bounds the number of chat completion requests
+// RunToolLoop sends.
+func WithMaxToolIterations(n int) ToolLoopOption {
+	return func(o *toolLoopOptions) {
+		o.maxIterations = n
+	}
+}
+
+// WithToolLoopChatOptions passes opts to every chat completion request of the
+// loop.
+func WithToolLoopChatOptions(opts ...ChatCompletionOption) ToolLoopOption {
+	return func(o *toolLoopOptions) {
+		o.chatOptions = append(o.chatOptions, opts...)
+	}
+}
+
+// WithToolArgumentRetries asks the model up to n times in total to repeat
+// tool calls whose arguments can't be decoded, e.g. because they are invalid
+// JSON, passing it the decoding error to guide the correction. Once the
+// retries are used up, the loop fails with the *ToolArgumentsError. Without
+// this option, such errors are passed to the model like any other tool error
+// and only the maximum number of iterations bounds the loop.
+func WithToolArgumentRetries(n int) ToolLoopOption {
+	return func(o *toolLoopOptions) {
+		o.argumentRetries = n
+	}
+}
+
+// ToolLoopResult is the outcome of RunToolLoop.
+type ToolLoopResult struct {
+	// Response is the last response, whose first choice did not call a tool.
+	Response ChatCompletionResponse
+	// Messages is the conversation of the request followed by every tool
+	// call, tool result and the final assistant message.
+	Messages []ChatCompletionMessage
+	// Usage is the summed usage of all requests.
+	Usage Usage
+}
+
+// RunToolLoop sends request and, as long as the first choice of the response
+// calls tools, invokes the tools registered with RegisterTool, appends their
+// results to the conversation and sends it again. It returns once the model
+// answers without calling a tool.
+//
+// An error returned by a tool is passed to the model as the tool's result so
+// that it can recover. Calls of tools that are not registered fail the loop
+// with ErrToolNotRegistered, and exceeding the maximum number of iterations
+// fails it with ErrToolLoopMaxIterations; the result then holds the
+// conversation so far.
+func (c *Client) RunToolLoop(
+	ctx context.Context,
+	request ChatCompletionRequest,
+	opts ...ToolLoopOption,
+) (result ToolLoopResult, err error) {
+	options := &toolLoopOptions{maxIterations: DefaultMaxToolIterations}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	retriesLeft := options.argumentRetries
+	result.Messages = append([]ChatCompletionMessage(nil), request.Messages...)
+	for i := 0; i < options.maxIterations; i++ {
+		request.Messages = result.Messages
+		result.Response, err = c.CreateChatCompletion(ctx, request, options.chatOptions...)
+		if err != nil {
+			return
+		}
+		addUsage(&result.Usage, result.Response.Usage)
+		if len(result.Response.Choices) == 0 {
+			err = ErrNoChoices
+			return
+		}
+
+		msg := result.Response.Choices[0].Message
+		result.Messages = append(result.Messages, msg)
+		if len(msg.ToolCalls) == 0 {
+			return
+		}
+
+		for _, call := range msg.ToolCalls {
+			var content string
+			content, err = c.invokeTool(ctx, call)
+			if errors.Is(err, ErrToolNotRegistered) {
+				return
+			}
+			var argsErr *ToolArgumentsError
+			if options.argumentRetries > 0 && errors.As(err, &argsErr) {
+				if retriesLeft == 0 {
+					return
+				}
+				retriesLeft--
+				content = fmt.Sprintf("error: the arguments of this call could not be decoded: %v. "+
+					"Call %s again with valid JSON arguments.", argsErr.Err, argsErr.Name)
+				err = nil
+			}
+			if err != nil {
+				content = fmt.Sprintf("error: %v", err)
+				err = nil
+			}
+			result.Messages = append(result.Messages, ChatCompletionMessage{
+				Role:       ChatMessageRoleTool,
+				Content:    content,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+
+	err = fmt.Errorf("%w: %d", ErrToolLoopMaxIterations, options.maxIterations)
+	return
+}
@@ -0,0 +1,126 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRunToolLoop(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var requests [][]ChatCompletionMessage
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		requests = append(requests, req.Messages)
+
+		msg := ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "It is 21 degrees in Paris."}
+		if len(requests) == 1 {
+			msg = ChatCompletionMessage{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{
+				{ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+				{ID: "call_b", Type: ToolTypeFunction, Function: FunctionCall{Name: "fail", Arguments: `{}`}},
+			}}
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{
+			Choices: []ChatCompletionChoice{{Message: msg}},
+			Usage:   Usage{TotalTokens: 10},
+		})
+		_, _ = w.Write(resBytes)
+	})
+
+	checks.NoError(t, client.RegisterTool("get_weather", "", getWeather), "RegisterTool error")
+	checks.NoError(t, client.RegisterTool("fail", "", func(struct{}) (string, error) {
+		return "", errors.New("tool is broken")
+	}), "RegisterTool error")
+
+	result, err := client.RunToolLoop(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Weather in Paris?"}},
+	})
+	checks.NoError(t, err, "RunToolLoop error")
+
+	if len(requests) != 2 || len(requests[1]) != 4 {
+		t.Fatalf("unexpected requests: %+v", requests)
+	}
+	weather, failed := requests[1][2], requests[1][3]
+	if weather.ToolCallID != "call_a" || weather.Content != `{"city":"Paris","temperature":21}` {
+		t.Errorf("unexpected tool result: %+v", weather)
+	}
+	if failed.ToolCallID != "call_b" || failed.Content != "error: tool is broken" {
+		t.Errorf("tool error was not passed to the model: %+v", failed)
+	}
+	checks.NoError(t, ValidateToolCallOrdering(result.Messages), "invalid tool call ordering")
+	if len(result.Messages) != 5 || result.Messages[4].Content != "It is 21 degrees in Paris." {
+		t.Errorf("unexpected conversation: %+v", result.Messages)
+	}
+	if result.Usage.TotalTokens != 20 {
+		t.Errorf("usage was not summed: %+v", result.Usage)
+	}
+
+	requests = nil
+	_, err = client.RunToolLoop(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Weather in Paris?"}},
+	}, WithMaxToolIterations(1))
+	checks.ErrorIs(t, err, ErrToolLoopMaxIterations, "expected ErrToolLoopMaxIterations")
+}
+
+func TestRunToolLoopArgumentRetries(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var requests [][]ChatCompletionMessage
+	validAfter := 2
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		requests = append(requests, req.Messages)
+
+		arguments := `{"city":"Paris"`
+		if len(requests) > validAfter {
+			arguments = `{"city":"Paris"}`
+		}
+		msg := ChatCompletionMessage{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{
+			{ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "get_weather", Arguments: Arguments(arguments)}},
+		}}
+		if len(req.Messages) > 0 && req.Messages[len(req.Messages)-1].Content == `{"city":"Paris","temperature":21}` {
+			msg = ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "It is 21 degrees."}
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{Choices: []ChatCompletionChoice{{Message: msg}}})
+		_, _ = w.Write(resBytes)
+	})
+	checks.NoError(t, client.RegisterTool("get_weather", "", getWeather), "RegisterTool error")
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Weather in Paris?"}},
+	}
+	result, err := client.RunToolLoop(context.Background(), request, WithToolArgumentRetries(2))
+	checks.NoError(t, err, "RunToolLoop error")
+	if len(requests) != 4 || result.Response.Choices[0].Message.Content != "It is 21 degrees." {
+		t.Fatalf("unexpected requests: %+v", requests)
+	}
+	reask := requests[1][2]
+	if reask.ToolCallID != "call_a" || !strings.Contains(reask.Content, "Call get_weather again with valid JSON") ||
+		!strings.Contains(reask.Content, "unexpected end of JSON input") {
+		t.Errorf("unexpected re-ask: %q", reask.Content)
+	}
+
+	requests = nil
+	_, err = client.RunToolLoop(context.Background(), request, WithToolArgumentRetries(1))
+	var argsErr *ToolArgumentsError
+	if !errors.As(err, &argsErr) || argsErr.Name != "get_weather" {
+		t.Errorf("expected a ToolArgumentsError once the retries are used up, got %v", err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("expected 2 requests, got %d", len(requests))
+	}
+}
@@ -0,0 +1,379 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrInvalidToolFunction = errors.New("invalid tool function")
+	ErrToolNotRegistered   = errors.New("tool is not registered")
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+type registeredTool struct {
+	tool     Tool
+	invoke   func(ctx context.Context, arguments Arguments) (string, error)
+	examples []ToolExample
+}
+
+type toolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]registeredTool
+	// names keeps the registration order so requests are deterministic.
+	names []string
+}
+
+// RegisterTool registers a Go function as a tool. The JSON schema of the
+// tool's parameters is derived from the function's argument struct, and
+// registered tools are added to the Tools of every chat completion request of
+// the client unless WithoutRegisteredTools is given or the model is known not
+// to support tools. RunToolLoop calls fn with the decoded arguments when the
+// model calls the tool.
+//
+// fn must have one of the signatures
+//
+//	func(args T) (R, error)
+//	func(ctx context.Context, args T) (R, error)
+//
+// where T is a struct or a pointer to one. The schema uses the json tags of
+// its fields; fields tagged omitempty or of pointer type are optional, and
+// the fields of embedded structs are promoted as encoding/json does. The
+// description and enum tags, e.g. `enum:"celsius,fahrenheit"`, describe a
+// field further. A string result is passed to the model as is, other results
+// are encoded as JSON. Options such as WithToolExamples configure the tool
+// further.
+func (c *Client) RegisterTool(name, description string, fn any, opts ...ToolOption) error {
+	fnValue := reflect.ValueOf(fn)
+	if !fnValue.IsValid() {
+		return fmt.Errorf("%w: %s is nil", ErrInvalidToolFunction, name)
+	}
+	fnType := fnValue.Type()
+	if fnType.Kind() != reflect.Func {
+		return fmt.Errorf("%w: %s is a %s, not a function", ErrInvalidToolFunction, name, fnType)
+	}
+	if fnValue.IsNil() {
+		return fmt.Errorf("%w: %s is a nil %s", ErrInvalidToolFunction, name, fnType)
+	}
+
+	withContext := fnType.NumIn() == 2 && fnType.In(0) == contextType
+	if (fnType.NumIn() != 1 && !withContext) || fnType.NumOut() != 2 || fnType.Out(1) != errorType {
+		return fmt.Errorf("%w: %s must be func([context.Context,] T) (R, error), got %s",
+			ErrInvalidToolFunction, name, fnType)
+	}
+	argsType := fnType.In(fnType.NumIn() - 1)
+	if indirectType(argsType).Kind() != reflect.Struct {
+		return fmt.Errorf("%w: %s must take a struct argument, got %s", ErrInvalidToolFunction, name, argsType)
+	}
+
+	invoke := func(ctx context.Context, arguments Arguments) (string, error) {
+		args := reflect.New(indirectType(argsType))
+		if arguments != "" {
+			if err := arguments.Decode(args.Interface()); err != nil {
+				return "", &ToolArgumentsError{Name: name, Arguments: arguments, Err: err}
+			}
+		}
+		if argsType.Kind() != reflect.Ptr {
+			args = args.Elem()
+		}
+
+		in := []reflect.Value{args}
+		if withContext {
+			in = []reflect.Value{reflect.ValueOf(ctx), args}
+		}
+		out := fnValue.Call(in)
+		if err, _ := out[1].Interface().(error); err != nil {
+			return "", err
+		}
+		return toolResultString(out[0].Interface())
+	}
+
+	parameters := schemaForType(indirectType(argsType))
+	tool := registeredTool{
+		tool: Tool{Type: ToolTypeFunction, Function: &Functions{
+			Name:        name,
+			Description: description,
+			Parameters: FuncParameters{
+				Type:       JSONSchemaTypeObject,
+				Properties: derefProperties(parameters.Properties),
+				Required:   parameters.Required,
+			},
+		}},
+		invoke: invoke,
+	}
+	for _, opt := range opts {
+		opt(&tool)
+	}
+	c.tools.register(tool)
+	return nil
+}
+
+// ToolArgumentsError is returned when the arguments of a tool call can't be
+// decoded into the argument struct of the registered function.
+type ToolArgumentsError struct {
+	Name      string
+	Arguments Arguments
+	Err       error
+}
+
+func (e *ToolArgumentsError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool %s: %v", e.Name, e.Err)
+}
+
+func (e *ToolArgumentsError) Unwrap() error {
+	return e.Err
+}
+
+func toolResultString(result any) (string, error) {
+	if s, ok := result.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (r *toolRegistry) register(tool registeredTool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.tools == nil {
+		r.tools = make(map[string]registeredTool)
+	}
+	name := tool.tool.Function.Name
+	if _, ok := r.tools[name]; !ok {
+		r.names = append(r.names, name)
+	}
+	r.tools[name] = tool
+}
+
+func (r *toolRegistry) get(name string) (registeredTool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// addTo returns tools with every registered tool appended that is not
+// already in tools.
+func (r *toolRegistry) addTo(tools []Tool) []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.names) == 0 {
+		return tools
+	}
+	present := make(map[string]bool, len(tools))
+	for _, tool := range tools {
+		if tool.Function != nil {
+			present[tool.Function.Name] = true
+		}
+	}
+
+	merged := make([]Tool, len(tools), len(tools)+len(r.names))
+	copy(merged, tools)
+	for _, name := range r.names {
+		if !present[name] {
+			merged = append(merged, r.tools[name].tool)
+		}
+	}
+	return merged
+}
+
+// invokeTool calls the registered function for call.
+func (c *Client) invokeTool(ctx context.Context, call ToolCall) (string, error) {
+	tool, ok := c.tools.get(call.Function.Name)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrToolNotRegistered, call.Function.Name)
+	}
+	return tool.invoke(ctx, call.Function.Arguments)
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// schemaForType derives the JSON schema of values of type t.
+func schemaForType(t reflect.Type) *JSONSchema {
+	return schemaForTypeVisiting(t, make(map[reflect.Type]bool))
+}
+
+// schemaForTypeVisiting derives the schema of t. visiting holds the structs
+// whose schema is being derived; a struct that refers back to one of them is
+// described as a plain object so self-referential types terminate.
+func schemaForTypeVisiting(t reflect.Type, visiting map[reflect.Type]bool) *JSONSchema {
+	t = indirectType(t)
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: JSONSchemaTypeString}
+	case reflect.Bool:
+		return &JSONSchema{Type: JSONSchemaTypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: JSONSchemaTypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: JSONSchemaTypeNumber}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: JSONSchemaTypeArray, Items: schemaForTypeVisiting(t.Elem(), visiting)}
+	case reflect.Map:
+		return &JSONSchema{Type: JSONSchemaTypeObject}
+	case reflect.Struct:
+		if visiting[t] {
+			return &JSONSchema{Type: JSONSchemaTypeObject}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		return schemaForStruct(t, visiting)
+	default:
+		return &JSONSchema{}
+	}
+}
+
+func schemaForStruct(t reflect.Type, visiting map[reflect.Type]bool) *JSONSchema {
+	schema := &JSONSchema{Type: JSONSchemaTypeObject, Properties: make(map[string]*JSONSchema)}
+	for _, field := range dominantSchemaFields(t) {
+		property := schemaForTypeVisiting(field.field.Type, visiting)
+		property.Description = field.field.Tag.Get("description")
+		if enum := field.field.Tag.Get("enum"); enum != "" {
+			property.Enum = strings.Split(enum, ",")
+		}
+		schema.Properties[field.name] = property
+		if !field.optional {
+			schema.Required = append(schema.Required, field.name)
+		}
+	}
+	return schema
+}
+
+// schemaField is a field encoding/json encodes for a struct, possibly
+// promoted from an embedded struct.
+type schemaField struct {
+	field    reflect.StructField
+	name     string
+	tagged   bool
+	optional bool
+	depth    int
+}
+
+// dominantSchemaFields returns the fields encoding/json encodes for t, in
+// field order. Fields of embedded structs without a json name are promoted;
+// of fields with the same name, the shallowest one wins, then the one with a
+// json name, and all of them are dropped if that leaves a tie.
+func dominantSchemaFields(t reflect.Type) []schemaField {
+	var fields []schemaField
+	collectSchemaFields(t, 0, make(map[reflect.Type]bool), &fields)
+
+	byName := make(map[string][]int)
+	for i, field := range fields {
+		byName[field.name] = append(byName[field.name], i)
+	}
+	dominant := make([]schemaField, 0, len(fields))
+	for i, field := range fields {
+		if dominantSchemaField(fields, byName[field.name]) == i {
+			dominant = append(dominant, field)
+		}
+	}
+	return dominant
+}
+
+// dominantSchemaField returns the position of the field of fields at
+// positions that encoding/json encodes, or -1 if there is none.
+func dominantSchemaField(fields []schemaField, positions []int) int {
+	depth := fields[positions[0]].depth
+	for _, pos := range positions[1:] {
+		if fields[pos].depth < depth {
+			depth = fields[pos].depth
+		}
+	}
+	shallowest, tagged := -1, -1
+	candidates, taggedCandidates := 0, 0
+	for _, pos := range positions {
+		if fields[pos].depth != depth {
+			continue
+		}
+		candidates++
+		shallowest = pos
+		if fields[pos].tagged {
+			taggedCandidates++
+			tagged = pos
+		}
+	}
+	switch {
+	case candidates == 1:
+		return shallowest
+	case taggedCandidates == 1:
+		return tagged
+	default:
+		return -1
+	}
+}
+
+// collectSchemaFields appends the fields of t at depth to fields, descending
+// into embedded structs. embedded holds the embedded structs being collected,
+// so embedding cycles terminate.
+func collectSchemaFields(t reflect.Type, depth int, embedded map[reflect.Type]bool, fields *[]schemaField) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldType := indirectType(field.Type)
+		// Unmarshal can't allocate embedded pointers to unexported structs.
+		promoted := field.Anonymous && fieldType.Kind() == reflect.Struct &&
+			(field.IsExported() || field.Type.Kind() != reflect.Ptr)
+		if !field.IsExported() && !promoted {
+			continue
+		}
+
+		name, optional := "", field.Type.Kind() == reflect.Ptr
+		if tag, ok := field.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			name = parts[0]
+			for _, option := range parts[1:] {
+				if option == "omitempty" {
+					optional = true
+				}
+			}
+		}
+
+		if promoted && name == "" {
+			if !embedded[fieldType] {
+				embedded[fieldType] = true
+				collectSchemaFields(fieldType, depth+1, embedded, fields)
+				delete(embedded, fieldType)
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		tagged := name != ""
+		if !tagged {
+			name = field.Name
+		}
+		*fields = append(*fields, schemaField{field: field, name: name, tagged: tagged, optional: optional, depth: depth})
+	}
+}
+
+func derefProperties(properties map[string]*JSONSchema) map[string]JSONSchema {
+	deref := make(map[string]JSONSchema, len(properties))
+	for name, property := range properties {
+		deref[name] = *property
+	}
+	return deref
+}
@@ -0,0 +1,201 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type weatherArgs struct {
+	City  string   `json:"city" description:"The city name"`
+	Unit  string   `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	Days  *int     `json:"days"`
+	Tags  []string `json:"tags,omitempty"`
+	Debug bool     `json:"-"`
+}
+
+func getWeather(_ context.Context, args weatherArgs) (map[string]any, error) {
+	return map[string]any{"city": args.City, "temperature": 21}, nil
+}
+
+func TestRegisterToolSchema(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var tools []Tool
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		tools = req.Tools
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	checks.NoError(t, client.RegisterTool("get_weather", "Get the weather", getWeather), "RegisterTool error")
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+	_, err := client.CreateChatCompletion(context.Background(), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(tools) != 1 || tools[0].Function.Name != "get_weather" || tools[0].Function.Description != "Get the weather" {
+		t.Fatalf("registered tool was not sent: %+v", tools)
+	}
+
+	parameters, err := json.Marshal(tools[0].Function.Parameters)
+	checks.NoError(t, err, "Marshal error")
+	//nolint:lll
+	expected := `{"type":"object","properties":{"city":{"type":"string","description":"The city name"},"days":{"type":"integer"},"tags":{"type":"array","items":{"type":"string"}},"unit":{"type":"string","enum":["celsius","fahrenheit"]}},"required":["city"]}`
+	if string(parameters) != expected {
+		t.Errorf("unexpected parameters schema:\n%s\nexpected:\n%s", parameters, expected)
+	}
+
+	_, err = client.CreateChatCompletion(context.Background(), request, WithoutRegisteredTools())
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(tools) != 0 {
+		t.Errorf("registered tools were sent despite WithoutRegisteredTools: %+v", tools)
+	}
+}
+
+func TestRegisterToolInvalidFunction(t *testing.T) {
+	client := NewClient("token")
+	var nilFunc func(weatherArgs) (string, error)
+	for _, fn := range []any{
+		nil,
+		nilFunc,
+		"not a function",
+		func(string) (string, error) { return "", nil },
+		func(weatherArgs) string { return "" },
+		func(context.Context, weatherArgs, int) (string, error) { return "", nil },
+	} {
+		err := client.RegisterTool("tool", "", fn)
+		checks.ErrorIs(t, err, ErrInvalidToolFunction, "invalid tool function should be rejected")
+	}
+	checks.NoError(t, client.RegisterTool("tool", "", func(*weatherArgs) (string, error) { return "", nil }),
+		"pointer arguments should be accepted")
+}
+
+type treeArgs struct {
+	Name     string     `json:"name"`
+	Children []treeArgs `json:"children,omitempty"`
+	Parent   *treeArgs  `json:"parent,omitempty"`
+}
+
+func TestRegisterToolRecursiveSchema(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var tools []Tool
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		tools = req.Tools
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	err := client.RegisterTool("walk_tree", "", func(treeArgs) (string, error) { return "", nil })
+	checks.NoError(t, err, "RegisterTool error")
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(tools) != 1 {
+		t.Fatalf("registered tool was not sent: %+v", tools)
+	}
+
+	parameters, err := json.Marshal(tools[0].Function.Parameters)
+	checks.NoError(t, err, "Marshal error")
+	//nolint:lll
+	expected := `{"type":"object","properties":{"children":{"type":"array","items":{"type":"object"}},"name":{"type":"string"},"parent":{"type":"object"}},"required":["name"]}`
+	if string(parameters) != expected {
+		t.Errorf("unexpected parameters schema:\n%s\nexpected:\n%s", parameters, expected)
+	}
+}
+
+func TestRegisteredToolsSkippedForModelsWithoutTools(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var tools []Tool
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		tools = req.Tools
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	checks.NoError(t, client.RegisterTool("get_weather", "Get the weather", getWeather), "RegisterTool error")
+	_, err := client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT40314,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "registered tools should not make requests to models without tools fail")
+	if len(tools) != 0 {
+		t.Errorf("registered tools were sent to a model without tools: %+v", tools)
+	}
+}
+
+type baseArgs struct {
+	ID   string `json:"id"`
+	Note string `json:"note,omitempty"`
+}
+
+type AuditArgs struct {
+	Note string `json:"note"`
+	By   string `json:"by,omitempty"`
+}
+
+type embeddedArgs struct {
+	baseArgs
+	*AuditArgs
+	Name string `json:"name"`
+}
+
+func TestRegisterToolEmbeddedSchema(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var tools []Tool
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		req, err := getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		tools = req.Tools
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+
+	err := client.RegisterTool("audit", "", func(embeddedArgs) (string, error) { return "", nil })
+	checks.NoError(t, err, "RegisterTool error")
+	_, err = client.CreateChatCompletion(context.Background(), ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	})
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if len(tools) != 1 {
+		t.Fatalf("registered tool was not sent: %+v", tools)
+	}
+
+	// The embedded fields are promoted like encoding/json does, and the
+	// ambiguous note field is dropped.
+	parameters, err := json.Marshal(tools[0].Function.Parameters)
+	checks.NoError(t, err, "Marshal error")
+	//nolint:lll
+	expected := `{"type":"object","properties":{"by":{"type":"string"},"id":{"type":"string"},"name":{"type":"string"}},"required":["id","name"]}`
+	if string(parameters) != expected {
+		t.Errorf("unexpected parameters schema:\n%s\nexpected:\n%s", parameters, expected)
+	}
+
+	var args embeddedArgs
+	checks.NoError(t, json.Unmarshal([]byte(`{"id":"1","by":"me","name":"x"}`), &args), "Unmarshal error")
+	if args.ID != "1" || args.AuditArgs == nil || args.By != "me" || args.Name != "x" {
+		t.Errorf("arguments matching the schema were not decoded: %+v", args)
+	}
+}
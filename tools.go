@@ -0,0 +1,119 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrToolCallWithoutResult = errors.New("tool call is not followed by a tool message with its result")
+	ErrToolResultWithoutCall = errors.New("tool message does not answer a preceding tool call")
+	ErrToolResultMissing     = errors.New("no result for tool call")
+)
+
+// ValidateToolCallOrdering checks that every tool call of an assistant message
+// is answered by a tool message with the matching ToolCallID, and that these
+// tool messages directly follow the assistant message. The API rejects
+// conversations that violate this with a 400 error.
+func ValidateToolCallOrdering(messages []ChatCompletionMessage) error {
+	pending := make(map[string]bool)
+	pendingSince := 0
+
+	for i, msg := range messages {
+		if msg.Role == ChatMessageRoleTool {
+			if !pending[msg.ToolCallID] {
+				return fmt.Errorf("%w: message %d references tool call %q",
+					ErrToolResultWithoutCall, i, msg.ToolCallID)
+			}
+			delete(pending, msg.ToolCallID)
+			continue
+		}
+
+		if err := checkNoPendingToolCalls(messages, pending, pendingSince); err != nil {
+			return err
+		}
+
+		for _, call := range msg.ToolCalls {
+			pending[call.ID] = true
+		}
+		pendingSince = i
+	}
+
+	return checkNoPendingToolCalls(messages, pending, pendingSince)
+}
+
+// checkNoPendingToolCalls reports the first tool call of messages[since]
+// that is still pending, in the order of its ToolCalls.
+func checkNoPendingToolCalls(messages []ChatCompletionMessage, pending map[string]bool, since int) error {
+	if len(pending) == 0 {
+		return nil
+	}
+	for _, call := range messages[since].ToolCalls {
+		if pending[call.ID] {
+			return fmt.Errorf("%w: tool call %q of message %d", ErrToolCallWithoutResult, call.ID, since)
+		}
+	}
+	return nil
+}
+
+// FunctionsToTools wraps each function definition into a Tool of type function,
+// to move from the deprecated functions field to tools.
+func FunctionsToTools(fns []Functions) []Tool {
+	if fns == nil {
+		return nil
+	}
+	tools := make([]Tool, 0, len(fns))
+	for i := range fns {
+		fn := fns[i]
+		tools = append(tools, Tool{Type: ToolTypeFunction, Function: &fn})
+	}
+	return tools
+}
+
+// ToolsToFunctions returns the function definitions of the function tools,
+// for models that only support the deprecated functions field. Tools of other
+// types are skipped.
+func ToolsToFunctions(tools []Tool) []Functions {
+	if tools == nil {
+		return nil
+	}
+	fns := make([]Functions, 0, len(tools))
+	for _, tool := range tools {
+		if tool.Type != ToolTypeFunction || tool.Function == nil {
+			continue
+		}
+		fns = append(fns, *tool.Function)
+	}
+	return fns
+}
+
+// ToolResultMessages builds the tool messages answering the message's tool
+// calls from results, which maps ToolCall.ID to the result content. The
+// messages are returned in the order of ToolCalls, ready to be appended to
+// the conversation after the message itself. It fails if a result is missing
+// for any of the tool calls.
+func (m ChatCompletionMessage) ToolResultMessages(results map[string]string) ([]ChatCompletionMessage, error) {
+	return OrderedToolMessages(m.ToolCalls, results)
+}
+
+// OrderedToolMessages builds the tool messages answering calls from results,
+// which maps ToolCall.ID to the result content, in the order of calls rather
+// than the iteration order of results. The API rejects results of parallel
+// tool calls that are out of order, so use it to collect results of calls
+// executed concurrently. It fails with ErrToolResultMissing if a result is
+// missing for any of the calls; results for other IDs are ignored.
+func OrderedToolMessages(calls []ToolCall, results map[string]string) ([]ChatCompletionMessage, error) {
+	messages := make([]ChatCompletionMessage, 0, len(calls))
+	for _, call := range calls {
+		result, ok := results[call.ID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s (%s)", ErrToolResultMissing, call.ID, call.Function.Name)
+		}
+		messages = append(messages, ChatCompletionMessage{
+			Role:       ChatMessageRoleTool,
+			Content:    result,
+			ToolCallID: call.ID,
+		})
+	}
+	return messages, nil
+}
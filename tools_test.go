@@ -0,0 +1,152 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateToolCallOrdering(t *testing.T) {
+	assistant := ChatCompletionMessage{
+		Role: ChatMessageRoleAssistant,
+		ToolCalls: []ToolCall{
+			{ID: "call_a", Type: ToolTypeFunction},
+			{ID: "call_b", Type: ToolTypeFunction},
+		},
+	}
+	user := ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "Hi"}
+	resultA := ChatCompletionMessage{Role: ChatMessageRoleTool, ToolCallID: "call_a", Content: "a"}
+	resultB := ChatCompletionMessage{Role: ChatMessageRoleTool, ToolCallID: "call_b", Content: "b"}
+
+	testCases := []struct {
+		name     string
+		messages []ChatCompletionMessage
+		expected error
+	}{
+		{"valid", []ChatCompletionMessage{user, assistant, resultB, resultA, user}, nil},
+		{"missing result", []ChatCompletionMessage{user, assistant, resultA, user}, ErrToolCallWithoutResult},
+		{"missing result at end", []ChatCompletionMessage{user, assistant, resultA}, ErrToolCallWithoutResult},
+		{"result after user turn", []ChatCompletionMessage{user, assistant, resultA, user, resultB},
+			ErrToolCallWithoutResult},
+		{"result without call", []ChatCompletionMessage{user, resultA}, ErrToolResultWithoutCall},
+		{"duplicate result", []ChatCompletionMessage{user, assistant, resultA, resultA, resultB},
+			ErrToolResultWithoutCall},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateToolCallOrdering(tc.messages)
+			if tc.expected == nil {
+				checks.NoError(t, err, "unexpected error")
+				return
+			}
+			checks.ErrorIs(t, err, tc.expected, "unexpected error", err.Error())
+		})
+	}
+
+	// The first unanswered call in message order is reported, every time.
+	for i := 0; i < 20; i++ {
+		err := ValidateToolCallOrdering([]ChatCompletionMessage{user, assistant, user})
+		if expected := `tool call "call_a" of message 1`; err == nil || !strings.HasSuffix(err.Error(), expected) {
+			t.Fatalf("expected the error to end with %s, got %v", expected, err)
+		}
+	}
+}
+
+func TestFunctionsToToolsRoundTrip(t *testing.T) {
+	fns := []Functions{
+		{
+			Name:        "get_weather",
+			Description: "Get the weather",
+			Parameters: FuncParameters{
+				Type:       JSONSchemaTypeObject,
+				Properties: map[string]JSONSchema{"city": {Type: JSONSchemaTypeString}},
+				Required:   []string{"city"},
+			},
+		},
+		{Name: "get_time"},
+	}
+
+	tools := FunctionsToTools(fns)
+	if len(tools) != len(fns) {
+		t.Fatalf("expected %d tools, got %d", len(fns), len(tools))
+	}
+	for i, tool := range tools {
+		if tool.Type != ToolTypeFunction || tool.Function == nil || tool.Function.Name != fns[i].Name {
+			t.Errorf("unexpected tool %d: %+v", i, tool)
+		}
+	}
+
+	roundTripped := ToolsToFunctions(tools)
+	if !reflect.DeepEqual(roundTripped, fns) {
+		t.Errorf("round trip changed the functions: %+v", roundTripped)
+	}
+
+	tools[0].Function.Name = "changed"
+	if fns[0].Name != "get_weather" {
+		t.Error("tools share memory with the original functions")
+	}
+
+	if ToolsToFunctions([]Tool{{Type: "retrieval"}}) == nil || len(ToolsToFunctions([]Tool{{Type: "retrieval"}})) != 0 {
+		t.Error("non-function tools should be skipped")
+	}
+}
+
+func TestToolResultMessages(t *testing.T) {
+	assistant := ChatCompletionMessage{
+		Role: ChatMessageRoleAssistant,
+		ToolCalls: []ToolCall{
+			{ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "a"}},
+			{ID: "call_b", Type: ToolTypeFunction, Function: FunctionCall{Name: "b"}},
+		},
+	}
+
+	messages, err := assistant.ToolResultMessages(map[string]string{"call_b": "B", "call_a": "A"})
+	checks.NoError(t, err, "ToolResultMessages error")
+	if len(messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(messages))
+	}
+	for i, expected := range []struct{ id, content string }{{"call_a", "A"}, {"call_b", "B"}} {
+		msg := messages[i]
+		if msg.Role != ChatMessageRoleTool || msg.ToolCallID != expected.id || msg.Content != expected.content {
+			t.Errorf("unexpected message %d: %+v", i, msg)
+		}
+	}
+	checks.NoError(t, ValidateToolCallOrdering(append([]ChatCompletionMessage{assistant}, messages...)),
+		"tool result messages should form a valid conversation")
+
+	_, err = assistant.ToolResultMessages(map[string]string{"call_a": "A"})
+	checks.ErrorIs(t, err, ErrToolResultMissing, "expected ErrToolResultMissing")
+}
+
+func TestOrderedToolMessages(t *testing.T) {
+	calls := make([]ToolCall, 20)
+	results := make(map[string]string, len(calls))
+	for i := range calls {
+		id := fmt.Sprintf("call_%d", i)
+		calls[i] = ToolCall{ID: id, Type: ToolTypeFunction, Function: FunctionCall{Name: "lookup"}}
+		results[id] = fmt.Sprintf("result %d", i)
+	}
+
+	// Map iteration order is random, so repeat to catch ordering by it.
+	for attempt := 0; attempt < 10; attempt++ {
+		messages, err := OrderedToolMessages(calls, results)
+		checks.NoError(t, err, "OrderedToolMessages error")
+		if len(messages) != len(calls) {
+			t.Fatalf("expected %d messages, got %d", len(calls), len(messages))
+		}
+		for i, msg := range messages {
+			if msg.Role != ChatMessageRoleTool || msg.ToolCallID != calls[i].ID || msg.Content != results[calls[i].ID] {
+				t.Fatalf("unexpected message %d: %+v", i, msg)
+			}
+		}
+	}
+
+	delete(results, "call_7")
+	_, err := OrderedToolMessages(calls, results)
+	checks.ErrorIs(t, err, ErrToolResultMissing, "expected ErrToolResultMissing")
+}
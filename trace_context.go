@@ -0,0 +1,55 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+)
+
+// TraceContext is a W3C trace context, see
+// https://www.w3.org/TR/trace-context/.
+type TraceContext struct {
+	// TraceParent is the traceparent header value, e.g.
+	// "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+	TraceParent string
+	// TraceState is the optional vendor-specific tracestate header value.
+	TraceState string
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx carrying tc, which requests
+// made with the context propagate when ClientConfig.PropagateTraceContext is
+// set.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the trace context stored in ctx by
+// ContextWithTraceContext. It is the default ClientConfig.TraceContextExtractor.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+var traceParentPattern = regexp.MustCompile(`^[0-9a-f]{2}-[0-9a-f]{32}-[0-9a-f]{16}-[0-9a-f]{2}$`)
+
+// setTraceHeaders sets the traceparent and tracestate headers of req from
+// the trace context of its context. Malformed trace parents are not sent.
+func (c *Client) setTraceHeaders(req *http.Request) {
+	if !c.config.PropagateTraceContext {
+		return
+	}
+	extract := c.config.TraceContextExtractor
+	if extract == nil {
+		extract = TraceContextFromContext
+	}
+	tc, ok := extract(req.Context())
+	if !ok || !traceParentPattern.MatchString(tc.TraceParent) {
+		return
+	}
+	req.Header.Set("traceparent", tc.TraceParent)
+	if tc.TraceState != "" {
+		req.Header.Set("tracestate", tc.TraceState)
+	}
+}
@@ -0,0 +1,71 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestPropagateTraceContext(t *testing.T) {
+	server := test.NewTestServer()
+	var header http.Header
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		if r.Header.Get("Accept") == "text/event-stream" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("data: [DONE]\n\n"))
+			return
+		}
+		resBytes, _ := json.Marshal(ChatCompletionResponse{})
+		_, _ = w.Write(resBytes)
+	})
+	ts := server.OpenAITestServer()
+	ts.Start()
+	defer ts.Close()
+
+	config := DefaultConfig(test.GetTestToken())
+	config.BaseURL = ts.URL + "/v1"
+	config.PropagateTraceContext = true
+	client := NewClientWithConfig(config)
+
+	const traceParent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	ctx := ContextWithTraceContext(context.Background(), TraceContext{TraceParent: traceParent, TraceState: "vendor=1"})
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Hello!"}},
+	}
+
+	_, err := client.CreateChatCompletion(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if header.Get("traceparent") != traceParent || header.Get("tracestate") != "vendor=1" {
+		t.Errorf("trace context was not propagated: %v", header)
+	}
+
+	stream, err := client.CreateChatCompletionStream(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletionStream error")
+	stream.Close()
+	if header.Get("traceparent") != traceParent {
+		t.Errorf("trace context was not propagated to the stream: %v", header)
+	}
+
+	ctx = ContextWithTraceContext(context.Background(), TraceContext{TraceParent: "not-a-trace"})
+	_, err = client.CreateChatCompletion(ctx, request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if header.Get("traceparent") != "" {
+		t.Errorf("malformed trace parent was sent: %q", header.Get("traceparent"))
+	}
+
+	config.PropagateTraceContext = false
+	client = NewClientWithConfig(config)
+	_, err = client.CreateChatCompletion(ContextWithTraceContext(context.Background(),
+		TraceContext{TraceParent: traceParent}), request)
+	checks.NoError(t, err, "CreateChatCompletion error")
+	if header.Get("traceparent") != "" {
+		t.Error("trace context was propagated although disabled")
+	}
+}
@@ -0,0 +1,81 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+)
+
+// TranscriptOption configures FormatTranscript.
+type TranscriptOption func(*transcriptOptions)
+
+type transcriptOptions struct {
+	omitSystem bool
+}
+
+// WithoutSystemMessages leaves system messages out of the transcript.
+func WithoutSystemMessages() TranscriptOption {
+	return func(o *transcriptOptions) {
+		o.omitSystem = true
+	}
+}
+
+// FormatTranscript renders messages as a readable transcript with one
+// "ROLE: content" line per message, e.g. for logging or to feed a
+// conversation into a summarizer. Function and tool calls are rendered as
+// calls with their arguments, and image parts as [image] placeholders.
+func FormatTranscript(messages []ChatCompletionMessage, opts ...TranscriptOption) string {
+	options := &transcriptOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var sb strings.Builder
+	for _, msg := range messages {
+		if options.omitSystem && msg.Role == ChatMessageRoleSystem {
+			continue
+		}
+
+		sb.WriteString(strings.ToUpper(msg.Role))
+		switch {
+		case msg.Name != "":
+			fmt.Fprintf(&sb, " (%s)", msg.Name)
+		case msg.ToolCallID != "":
+			fmt.Fprintf(&sb, " (%s)", msg.ToolCallID)
+		}
+		sb.WriteString(":")
+
+		for _, part := range transcriptParts(msg) {
+			sb.WriteString(" ")
+			sb.WriteString(part)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func transcriptParts(msg ChatCompletionMessage) []string {
+	var parts []string
+	if text := msg.TextContent(); text != "" {
+		parts = append(parts, text)
+	}
+	for _, part := range msg.MultiContent {
+		switch part.Type {
+		case ChatMessagePartTypeText:
+			// Already part of TextContent.
+		case ChatMessagePartTypeImageURL:
+			parts = append(parts, "[image]")
+		default:
+			parts = append(parts, fmt.Sprintf("[%s]", part.Type))
+		}
+	}
+	if msg.Refusal != "" {
+		parts = append(parts, fmt.Sprintf("[refusal: %s]", msg.Refusal))
+	}
+	if msg.FunctionCall != zeroFunctionCall {
+		parts = append(parts, fmt.Sprintf("[function call %s(%s)]", msg.FunctionCall.Name, msg.FunctionCall.Arguments))
+	}
+	for _, call := range msg.ToolCalls {
+		parts = append(parts, fmt.Sprintf("[tool call %s %s(%s)]", call.ID, call.Function.Name, call.Function.Arguments))
+	}
+	return parts
+}
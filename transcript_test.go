@@ -0,0 +1,36 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+
+	"testing"
+)
+
+func TestFormatTranscript(t *testing.T) {
+	messages := []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "Be brief."},
+		{Role: ChatMessageRoleUser, Name: "bob", MultiContent: []ChatMessagePart{
+			{Type: ChatMessagePartTypeText, Text: "What is this?"},
+			{Type: ChatMessagePartTypeImageURL, ImageURL: &ChatMessageImageURL{URL: "https://example.com/cat.png"}},
+		}},
+		{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{
+			{ID: "call_a", Type: ToolTypeFunction, Function: FunctionCall{Name: "classify", Arguments: `{"image":1}`}},
+		}},
+		{Role: ChatMessageRoleTool, ToolCallID: "call_a", Content: "cat"},
+		{Role: ChatMessageRoleAssistant, Content: "A cat."},
+	}
+
+	expected := "SYSTEM: Be brief.\n" +
+		"USER (bob): What is this? [image]\n" +
+		"ASSISTANT: [tool call call_a classify({\"image\":1})]\n" +
+		"TOOL (call_a): cat\n" +
+		"ASSISTANT: A cat.\n"
+	if got := FormatTranscript(messages); got != expected {
+		t.Errorf("unexpected transcript:\n%s\nexpected:\n%s", got, expected)
+	}
+
+	expected = expected[len("SYSTEM: Be brief.\n"):]
+	if got := FormatTranscript(messages, WithoutSystemMessages()); got != expected {
+		t.Errorf("unexpected transcript without system messages:\n%s", got)
+	}
+}
@@ -0,0 +1,82 @@
+package openai
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrTrimMiddleExceedsLimit = errors.New("messages do not fit into the token limit without their ends")
+
+// TrimMiddle fits messages into maxTokens prompt tokens of model, counted
+// like CountMessageTokens, by dropping messages from the middle of the
+// conversation outward, which keeps both its setup and its most recent
+// messages. Leading system messages and the last message are always kept,
+// and tool results are dropped together with the assistant message that
+// called them. It returns the remaining messages and the ascending indexes of
+// the removed ones. If the kept messages alone exceed maxTokens, everything
+// else is removed and ErrTrimMiddleExceedsLimit is returned.
+func TrimMiddle(
+	messages []ChatCompletionMessage,
+	model string,
+	maxTokens int,
+) ([]ChatCompletionMessage, []int, error) {
+	tokenizer := tokenizerForModel(model)
+	total := tokensPerReply
+	counts := make([]int, len(messages))
+	for i, msg := range messages {
+		counts[i] = countMessageTokens(tokenizer, msg)
+		total += counts[i]
+	}
+	if total <= maxTokens {
+		return messages, nil, nil
+	}
+
+	// Groups are the start indexes of the removable runs of messages: a
+	// message and the tool results following it, between the leading system
+	// messages and the group of the last message.
+	start := leadingSystemMessages(messages)
+	var groups []int
+	for i := start; i < len(messages); i++ {
+		if i == start || messages[i].Role != ChatMessageRoleTool {
+			groups = append(groups, i)
+		}
+	}
+	if len(groups) > 0 {
+		groups = groups[:len(groups)-1]
+	}
+
+	// Remove the groups from the middle outward.
+	var order []int
+	for lo, hi := (len(groups)-1)/2, (len(groups)-1)/2+1; lo >= 0 || hi < len(groups); lo, hi = lo-1, hi+1 {
+		if lo >= 0 {
+			order = append(order, lo)
+		}
+		if hi < len(groups) {
+			order = append(order, hi)
+		}
+	}
+	removed := make(map[int]bool)
+	for _, g := range order {
+		if total <= maxTokens {
+			break
+		}
+		for i := groups[g]; i == groups[g] || messages[i].Role == ChatMessageRoleTool; i++ {
+			removed[i] = true
+			total -= counts[i]
+		}
+	}
+
+	kept := make([]ChatCompletionMessage, 0, len(messages)-len(removed))
+	indexes := make([]int, 0, len(removed))
+	for i, msg := range messages {
+		if removed[i] {
+			indexes = append(indexes, i)
+		} else {
+			kept = append(kept, msg)
+		}
+	}
+	if total > maxTokens {
+		return kept, indexes, fmt.Errorf("%w: %d tokens, limit %d", ErrTrimMiddleExceedsLimit, total, maxTokens)
+	}
+	return kept, indexes, nil
+}
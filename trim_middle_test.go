@@ -0,0 +1,52 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"reflect"
+	"testing"
+)
+
+func TestTrimMiddle(t *testing.T) {
+	RegisterTokenizer("trim-middle-model", wordTokenizer{})
+	messages := []ChatCompletionMessage{
+		{Role: ChatMessageRoleSystem, Content: "setup"},
+		{Role: ChatMessageRoleUser, Content: "first question"},
+		{Role: ChatMessageRoleAssistant, Content: "first answer"},
+		{Role: ChatMessageRoleUser, Content: "second question"},
+		{Role: ChatMessageRoleAssistant, ToolCalls: []ToolCall{{ID: "a"}}},
+		{Role: ChatMessageRoleTool, Content: "tool result", ToolCallID: "a"},
+		{Role: ChatMessageRoleAssistant, Content: "second answer"},
+		{Role: ChatMessageRoleUser, Content: "last question"},
+	}
+	total := CountMessageTokens("trim-middle-model", messages)
+
+	kept, removed, err := TrimMiddle(messages, "trim-middle-model", total)
+	checks.NoError(t, err, "TrimMiddle error")
+	if len(kept) != len(messages) || len(removed) != 0 {
+		t.Errorf("fitting messages should be kept, removed %v", removed)
+	}
+
+	// Each plain message costs 3 + 1 (role) + 2 (content) tokens.
+	kept, removed, err = TrimMiddle(messages, "trim-middle-model", total-1)
+	checks.NoError(t, err, "TrimMiddle error")
+	if !reflect.DeepEqual(removed, []int{3}) || len(kept) != len(messages)-1 {
+		t.Errorf("expected the middle message to be removed, got %v", removed)
+	}
+
+	kept, removed, err = TrimMiddle(messages, "trim-middle-model", total-7)
+	checks.NoError(t, err, "TrimMiddle error")
+	if !reflect.DeepEqual(removed, []int{3, 4, 5}) {
+		t.Errorf("expected the tool call to be removed with its result, got %v", removed)
+	}
+	if kept[0].Content != "setup" || kept[len(kept)-1].Content != "last question" {
+		t.Errorf("expected the ends to be kept, got %+v", kept)
+	}
+
+	kept, removed, err = TrimMiddle(messages, "trim-middle-model", 10)
+	checks.ErrorIs(t, err, ErrTrimMiddleExceedsLimit, "ends exceeding the limit should be reported")
+	if !reflect.DeepEqual(removed, []int{1, 2, 3, 4, 5, 6}) || len(kept) != 2 {
+		t.Errorf("expected everything but the ends to be removed, got %v", removed)
+	}
+}
@@ -0,0 +1,205 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+)
+
+// TypedStream decodes the JSON content of the first choice of a stream into
+// progressively more complete values of T as it arrives, see StreamTyped.
+type TypedStream[T any] struct {
+	stream   *ChatCompletionStream
+	schema   json.RawMessage
+	content  strings.Builder
+	last     string
+	finished bool
+}
+
+// StreamTyped streams request with a response format requesting JSON
+// matching the schema of T, like CreateChatCompletionTyped, and returns a
+// TypedStream emitting values of T decoded from the content received so far.
+// A ResponseFormat already set on request is kept.
+func StreamTyped[T any](
+	ctx context.Context,
+	client *Client,
+	request ChatCompletionRequest,
+	opts ...ChatCompletionOption,
+) (*TypedStream[T], error) {
+	var schema json.RawMessage
+	if request.ResponseFormat == nil {
+		var err error
+		request.ResponseFormat, schema, err = jsonSchemaResponseFormat[T]()
+		if err != nil {
+			return nil, err
+		}
+	}
+	stream, err := client.CreateChatCompletionStream(ctx, request, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &TypedStream[T]{stream: stream, schema: schema}, nil
+}
+
+// Recv returns the next value of T, decoded from the content received so far
+// with open strings, arrays and objects closed and incomplete numbers, keys
+// and literals left out, which is a best-effort partial parse. Fields that
+// have not been streamed yet are zero. A value is only returned when it
+// changed, and the last value before io.EOF is decoded from the complete
+// content. If the complete content is not JSON, Recv fails with a
+// *ResponseNotJSONError; if it does not match the derived schema, the value is
+// returned along with a *SchemaViolationError.
+func (s *TypedStream[T]) Recv() (value T, err error) {
+	for {
+		if s.finished {
+			err = io.EOF
+			return
+		}
+
+		var response ChatCompletionStreamResponse
+		response, err = s.stream.Recv()
+		if errors.Is(err, io.EOF) {
+			s.finished = true
+			return s.final()
+		}
+		if err != nil {
+			return
+		}
+
+		delta := ""
+		for _, choice := range response.Choices {
+			if choice.Index == 0 {
+				delta += choice.Delta.Content
+			}
+		}
+		if delta == "" {
+			continue
+		}
+		s.content.WriteString(delta)
+
+		partial, ok := completePartialJSON(s.content.String())
+		if !ok || partial == s.last {
+			continue
+		}
+		var v T
+		if json.Unmarshal([]byte(partial), &v) != nil {
+			continue
+		}
+		s.last = partial
+		return v, nil
+	}
+}
+
+// final decodes the complete content, or returns io.EOF if the last partial
+// value already was complete.
+func (s *TypedStream[T]) final() (value T, err error) {
+	content := strings.TrimSpace(s.content.String())
+	if !json.Valid([]byte(content)) {
+		err = &ResponseNotJSONError{Content: s.content.String()}
+		return
+	}
+	if s.schema != nil {
+		err = ValidateAgainstSchema(content, s.schema)
+	}
+	if content == s.last && err == nil {
+		err = io.EOF
+		return
+	}
+	if decodeErr := json.Unmarshal([]byte(content), &value); decodeErr != nil {
+		err = decodeErr
+	}
+	return
+}
+
+// Close closes the underlying stream.
+func (s *TypedStream[T]) Close() {
+	s.stream.Close()
+}
+
+// completePartialJSON turns the prefix of a JSON value into valid JSON by
+// closing an open string value and all open arrays and objects. Incomplete
+// numbers, literals and object keys at the end are cut off. It reports false
+// if no valid JSON could be formed.
+func completePartialJSON(prefix string) (string, bool) {
+	type cutPoint struct {
+		end   int
+		stack string
+	}
+
+	var (
+		stack       []byte
+		cuts        []cutPoint
+		inString    bool
+		escapeStart = -1
+	)
+	for i := 0; i < len(prefix); i++ {
+		c := prefix[i]
+		if inString {
+			switch {
+			case escapeStart >= 0:
+				// \uXXXX escapes span four hex digits after the u.
+				if prefix[escapeStart+1] != 'u' || i-escapeStart == 5 {
+					escapeStart = -1
+				}
+			case c == '\\':
+				escapeStart = i
+			case c == '"':
+				inString = false
+				cuts = append(cuts, cutPoint{i + 1, string(stack)})
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, c)
+			cuts = append(cuts, cutPoint{i + 1, string(stack)})
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			cuts = append(cuts, cutPoint{i + 1, string(stack)})
+		case ',':
+			cuts = append(cuts, cutPoint{i, string(stack)})
+		}
+	}
+
+	if inString {
+		// An open string is most likely a value that is still streaming.
+		end := len(prefix)
+		if escapeStart >= 0 {
+			end = escapeStart
+		}
+		if candidate := prefix[:end] + `"` + closeJSON(string(stack)); json.Valid([]byte(candidate)) {
+			return candidate, true
+		}
+	} else if trimmed := strings.TrimSpace(prefix); json.Valid([]byte(trimmed)) {
+		return trimmed, true
+	}
+
+	for i := len(cuts) - 1; i >= 0; i-- {
+		candidate := strings.TrimRight(prefix[:cuts[i].end], ", \t\r\n") + closeJSON(cuts[i].stack)
+		candidate = strings.TrimSpace(candidate)
+		if json.Valid([]byte(candidate)) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// closeJSON returns the brackets closing the open arrays and objects of stack.
+func closeJSON(stack string) string {
+	closing := make([]byte, 0, len(stack))
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			closing = append(closing, '}')
+		} else {
+			closing = append(closing, ']')
+		}
+	}
+	return string(closing)
+}
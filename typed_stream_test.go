@@ -0,0 +1,88 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestStreamTyped(t *testing.T) {
+	client, server, teardown := setupOpenAITestServer()
+	defer teardown()
+
+	var deltas []string
+	var received ChatCompletionRequest
+	server.RegisterHandler("/v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		received, err = getChatCompletionBody(r)
+		checks.NoError(t, err, "could not read request")
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range deltas {
+			frame, _ := json.Marshal(ChatCompletionStreamResponse{Choices: []ChatCompletionStreamChoice{{
+				Delta: ChatCompletionStreamChoiceDelta{Content: delta},
+			}}})
+			_, _ = w.Write([]byte("data: " + string(frame) + "\n\n"))
+		}
+		_, _ = w.Write([]byte("data: [DONE]\n\n"))
+	})
+
+	request := ChatCompletionRequest{
+		Model:    GPT4o,
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "Forecast for Paris"}},
+	}
+	recvAll := func() ([]typedForecast, error) {
+		stream, err := StreamTyped[typedForecast](context.Background(), client, request)
+		checks.NoError(t, err, "StreamTyped error")
+		defer stream.Close()
+
+		var values []typedForecast
+		for {
+			value, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return values, nil
+			}
+			if err != nil {
+				return values, err
+			}
+			values = append(values, value)
+		}
+	}
+
+	deltas = []string{`{"city":"Pa`, `ris","hi`, `gh":21`, `.5,"note":"a\`, `u00e9`, `b"}`}
+	values, err := recvAll()
+	checks.NoError(t, err, "Recv error")
+	expected := []typedForecast{
+		{City: "Pa"},
+		{City: "Paris"},
+		{City: "Paris", High: 21.5, Note: "a"},
+		{City: "Paris", High: 21.5, Note: "aé"},
+		{City: "Paris", High: 21.5, Note: "aéb"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values\n%+v\nexpected\n%+v", values, expected)
+	}
+	if received.ResponseFormat == nil || received.ResponseFormat.Type != ChatCompletionResponseFormatTypeJSONSchema {
+		t.Errorf("expected a json_schema response format, got %+v", received.ResponseFormat)
+	}
+
+	deltas = []string{`{"ci`, `ty":"Paris"}`}
+	values, err = recvAll()
+	checks.ErrorIs(t, err, ErrSchemaViolation, "missing required field should be reported")
+	if len(values) != 2 || values[0].City != "" || values[1].City != "Paris" {
+		t.Errorf("expected an empty and a complete value, got %+v", values)
+	}
+
+	deltas = []string{"It will be ", "sunny."}
+	values, err = recvAll()
+	checks.ErrorIs(t, err, ErrResponseNotJSON, "prose should be rejected")
+	if len(values) != 0 {
+		t.Errorf("expected no values from prose, got %+v", values)
+	}
+}
@@ -0,0 +1,50 @@
+package openai
+
+// WebSearchContextSize is the amount of search results search-enabled models
+// add to their context.
+type WebSearchContextSize string
+
+const (
+	WebSearchContextSizeLow    WebSearchContextSize = "low"
+	WebSearchContextSizeMedium WebSearchContextSize = "medium"
+	WebSearchContextSizeHigh   WebSearchContextSize = "high"
+)
+
+func (s WebSearchContextSize) valid() bool {
+	switch s {
+	case "", WebSearchContextSizeLow, WebSearchContextSizeMedium, WebSearchContextSizeHigh:
+		return true
+	}
+	return false
+}
+
+// WebSearchOptions configures the built-in web search of search-enabled
+// models such as gpt-4o-search-preview. The citations of the results are
+// returned as the Annotations of the message.
+type WebSearchOptions struct {
+	// SearchContextSize defaults to medium.
+	SearchContextSize WebSearchContextSize   `json:"search_context_size,omitempty"`
+	UserLocation      *WebSearchUserLocation `json:"user_location,omitempty"`
+}
+
+type WebSearchUserLocationType string
+
+const (
+	WebSearchUserLocationTypeApproximate WebSearchUserLocationType = "approximate"
+)
+
+// WebSearchUserLocation is the location of the user to refine search
+// results with.
+type WebSearchUserLocation struct {
+	Type        WebSearchUserLocationType `json:"type"`
+	Approximate WebSearchLocation         `json:"approximate"`
+}
+
+// WebSearchLocation is an approximate location. Country is a two-letter ISO
+// country code and Timezone an IANA time zone such as "Europe/Paris".
+type WebSearchLocation struct {
+	City     string `json:"city,omitempty"`
+	Country  string `json:"country,omitempty"`
+	Region   string `json:"region,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+}
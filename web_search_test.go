@@ -0,0 +1,38 @@
+package openai_test
+
+import (
+	. "github.com/sashabaranov/go-openai"
+	"github.com/sashabaranov/go-openai/internal/test/checks"
+
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWebSearchOptions(t *testing.T) {
+	request := ChatCompletionRequest{Model: "gpt-4o-search-preview"}
+	b, err := json.Marshal(request)
+	checks.NoError(t, err, "Marshal error")
+	if strings.Contains(string(b), "web_search_options") {
+		t.Errorf("nil web search options were sent: %s", b)
+	}
+
+	request.WebSearchOptions = &WebSearchOptions{
+		SearchContextSize: WebSearchContextSizeLow,
+		UserLocation: &WebSearchUserLocation{
+			Type:        WebSearchUserLocationTypeApproximate,
+			Approximate: WebSearchLocation{City: "Paris", Country: "FR"},
+		},
+	}
+	checks.NoError(t, request.Validate(), "valid web search options rejected")
+	b, err = json.Marshal(request)
+	checks.NoError(t, err, "Marshal error")
+	expected := `"web_search_options":{"search_context_size":"low","user_location":{"type":"approximate",` +
+		`"approximate":{"city":"Paris","country":"FR"}}}`
+	if !strings.Contains(string(b), expected) {
+		t.Errorf("unexpected web search options: %s", b)
+	}
+
+	request.WebSearchOptions = &WebSearchOptions{SearchContextSize: "huge"}
+	checks.ErrorIs(t, request.Validate(), ErrInvalidChatCompletionRequest, "unknown context size accepted")
+}